@@ -0,0 +1,177 @@
+package appSource
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Prepare copies a user supplied app, given as a directory or a zip file, into
+// a new temporary directory and returns it. The app must have a manifest.yml
+// at its root, or at the root of the zip's single top-level directory.
+func Prepare(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "uptimer-custom-*")
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if err != nil {
+			os.RemoveAll(dir) //nolint:errcheck
+		}
+	}()
+
+	if info.IsDir() {
+		err = copyDir(path, dir)
+	} else {
+		err = extractZip(path, dir)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = hoistManifestDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", path, err)
+	}
+
+	return dir, nil
+}
+
+// hoistManifestDir makes sure manifest.yml is at the root of dir. Zips that
+// wrap the app in a single top-level directory have it moved up a level.
+func hoistManifestDir(dir string) error {
+	manifestDir, err := findManifestDir(dir)
+	if err != nil || manifestDir == dir {
+		return err
+	}
+
+	wrapper := filepath.Join(dir, ".uptimer-wrapper")
+	if err := os.Rename(manifestDir, wrapper); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(wrapper)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := os.Rename(filepath.Join(wrapper, e.Name()), filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+
+	return os.Remove(wrapper)
+}
+
+func findManifestDir(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, "manifest.yml")); err == nil {
+		return dir, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		nested := filepath.Join(dir, entries[0].Name())
+		if _, err := os.Stat(filepath.Join(nested, "manifest.yml")); err == nil {
+			return nested, nil
+		}
+	}
+
+	return "", fmt.Errorf("no manifest.yml found")
+}
+
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close() //nolint:errcheck
+
+		return writeFile(target, in, info.Mode().Perm())
+	})
+}
+
+func extractZip(zipPath, dst string) error {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close() //nolint:errcheck
+
+	for _, f := range r.File {
+		target := filepath.Join(dst, f.Name)
+		if !strings.HasPrefix(target, filepath.Clean(dst)+string(os.PathSeparator)) {
+			return fmt.Errorf("zip entry %q is outside of the app directory", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		in, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(target, in, f.Mode().Perm()|0600)
+		in.Close() //nolint:errcheck
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFile(path string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close() //nolint:errcheck
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,13 @@
+package appSource_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAppSource(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AppSource Suite")
+}
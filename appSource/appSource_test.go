@@ -0,0 +1,134 @@
+package appSource_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/uptimer/appSource"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Prepare", func() {
+	var (
+		srcDir string
+		dir    string
+		err    error
+	)
+
+	writeZip := func(files map[string]string) string {
+		zipPath := filepath.Join(srcDir, "app.zip")
+		f, err := os.Create(zipPath)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close() //nolint:errcheck
+
+		w := zip.NewWriter(f)
+		for name, content := range files {
+			fw, err := w.Create(name)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = fw.Write([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(w.Close()).To(Succeed())
+
+		return zipPath
+	}
+
+	BeforeEach(func() {
+		srcDir, err = os.MkdirTemp("", "appSource-test")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(srcDir) //nolint:errcheck
+		if dir != "" {
+			os.RemoveAll(dir) //nolint:errcheck
+		}
+	})
+
+	Context("when given a directory", func() {
+		BeforeEach(func() {
+			appDir := filepath.Join(srcDir, "app")
+			Expect(os.MkdirAll(filepath.Join(appDir, "src"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appDir, "manifest.yml"), []byte("applications: []"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appDir, "src", "App.java"), []byte("class App {}"), 0644)).To(Succeed())
+
+			dir, err = appSource.Prepare(appDir)
+		})
+
+		It("copies the app into a new directory", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dir).NotTo(Equal(filepath.Join(srcDir, "app")))
+			Expect(filepath.Join(dir, "manifest.yml")).To(BeARegularFile())
+			Expect(os.ReadFile(filepath.Join(dir, "src", "App.java"))).To(Equal([]byte("class App {}")))
+		})
+	})
+
+	Context("when given a zip file", func() {
+		BeforeEach(func() {
+			dir, err = appSource.Prepare(writeZip(map[string]string{
+				"manifest.yml":  "applications: []",
+				"app/server.js": "console.log(1)",
+			}))
+		})
+
+		It("extracts the app into a new directory", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(dir, "manifest.yml")).To(BeARegularFile())
+			Expect(os.ReadFile(filepath.Join(dir, "app", "server.js"))).To(Equal([]byte("console.log(1)")))
+		})
+	})
+
+	Context("when the zip wraps the app in a single directory", func() {
+		BeforeEach(func() {
+			dir, err = appSource.Prepare(writeZip(map[string]string{
+				"my-app/manifest.yml": "applications: []",
+				"my-app/app.rb":       "puts 1",
+			}))
+		})
+
+		It("moves the app to the root of the directory", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(dir, "manifest.yml")).To(BeARegularFile())
+			Expect(filepath.Join(dir, "app.rb")).To(BeARegularFile())
+			Expect(filepath.Join(dir, "my-app")).NotTo(BeADirectory())
+		})
+	})
+
+	Context("when the app has no manifest", func() {
+		BeforeEach(func() {
+			dir, err = appSource.Prepare(writeZip(map[string]string{
+				"app.rb": "puts 1",
+			}))
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(ContainSubstring("no manifest.yml found")))
+		})
+	})
+
+	Context("when a zip entry escapes the app directory", func() {
+		BeforeEach(func() {
+			dir, err = appSource.Prepare(writeZip(map[string]string{
+				"manifest.yml": "applications: []",
+				"../evil.sh":   "rm -rf /",
+			}))
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(ContainSubstring("outside of the app directory")))
+		})
+	})
+
+	Context("when the path does not exist", func() {
+		BeforeEach(func() {
+			dir, err = appSource.Prepare(filepath.Join(srcDir, "missing"))
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,37 @@
+package appSource
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// CompileLinuxBinary cross-compiles a single file Go program that only uses
+// the standard library into a static linux/amd64 binary at outPath, so that it
+// can be pushed with the binary buildpack.
+func CompileLinuxBinary(source, outPath string) error {
+	buildDir, err := os.MkdirTemp("", "uptimer-build-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(buildDir) //nolint:errcheck
+
+	if err := os.WriteFile(filepath.Join(buildDir, "main.go"), []byte(source), 0644); err != nil {
+		return err
+	}
+
+	absOutPath, err := filepath.Abs(outPath)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "build", "-o", absOutPath, "main.go")
+	cmd.Dir = buildDir
+	cmd.Env = append(os.Environ(), "GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0", "GOFLAGS=", "GO111MODULE=on")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go build failed: %s: %s", err, out)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package appSource_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/uptimer/appSource"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CompileLinuxBinary", func() {
+	var outDir string
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = os.MkdirTemp("", "appSource-compile-test")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir) //nolint:errcheck
+	})
+
+	It("builds a linux executable", func() {
+		outPath := filepath.Join(outDir, "app")
+
+		err := appSource.CompileLinuxBinary("package main\n\nfunc main() {}\n", outPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		binary, err := os.ReadFile(outPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bytes.HasPrefix(binary, []byte("\x7fELF"))).To(BeTrue())
+	})
+
+	Context("when the source does not compile", func() {
+		It("returns the compiler output", func() {
+			err := appSource.CompileLinuxBinary("package main\n\nfunc main() { undefined() }\n", filepath.Join(outDir, "app"))
+			Expect(err).To(MatchError(ContainSubstring("undefined")))
+		})
+	})
+})
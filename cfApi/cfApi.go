@@ -0,0 +1,736 @@
+package cfApi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+// cfApi generates operations that talk to the Cloud Controller v3 and UAA
+// APIs directly instead of running the cf CLI. The operations of one
+// generator share a session, just like cf commands share a CF_HOME.
+type cfApi struct {
+	session               *session
+	useBuildpackDetection bool
+}
+
+func New(client *http.Client, useBuildpackDetection bool) cfCmdGenerator.CfCmdGenerator {
+	return &cfApi{
+		session:               &session{client: client},
+		useBuildpackDetection: useBuildpackDetection,
+	}
+}
+
+func (c *cfApi) operation(description string, run func(out, errOut io.Writer) error) cmdStartWaiter.CmdStartWaiter {
+	return &operation{
+		description: description,
+		session:     c.session,
+		run:         run,
+	}
+}
+
+func (c *cfApi) Api(apiUrl string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("api "+apiUrl, func(out, errOut io.Writer) error {
+		if !strings.Contains(apiUrl, "://") {
+			apiUrl = "https://" + apiUrl
+		}
+		apiUrl = strings.TrimSuffix(apiUrl, "/")
+		fmt.Fprintf(out, "Setting API endpoint to %s...\n", apiUrl) //nolint:errcheck
+
+		res, err := c.session.client.Get(apiUrl + "/")
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close() //nolint:errcheck
+
+		root := &struct {
+			Links map[string]struct {
+				Href string `json:"href"`
+			} `json:"links"`
+		}{}
+		if err := decodeRoot(res, root); err != nil {
+			return fmt.Errorf("Failed to read API root %s: %s", apiUrl, err)
+		}
+
+		c.session.api = apiUrl
+		c.session.uaaUrl = root.Links["uaa"].Href
+		c.session.logCacheUrl = root.Links["log_cache"].Href
+		c.session.accessToken, c.session.refreshToken = "", ""
+		c.session.clientId, c.session.clientSecret = "", ""
+		c.session.orgGuid, c.session.spaceGuid = "", ""
+		if c.session.uaaUrl == "" {
+			return fmt.Errorf("API %s does not link to UAA", apiUrl)
+		}
+
+		fmt.Fprintln(out, "OK") //nolint:errcheck
+		return nil
+	})
+}
+
+func (c *cfApi) Auth(username, password string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("auth "+username, func(out, errOut io.Writer) error {
+		if c.session.uaaUrl == "" {
+			return errors.New("No API endpoint set. Use 'cf api' to set an endpoint")
+		}
+		fmt.Fprintf(out, "Authenticating as %s...\n", username) //nolint:errcheck
+
+		c.session.clientId, c.session.clientSecret = "", ""
+		err := c.session.requestToken(url.Values{
+			"grant_type": {"password"},
+			"username":   {username},
+			"password":   {password},
+		})
+		if err != nil {
+			return fmt.Errorf("Authentication as %s failed: %s", username, err)
+		}
+
+		fmt.Fprintln(out, "OK") //nolint:errcheck
+		return nil
+	})
+}
+
+func (c *cfApi) AuthClientCredentials(clientId, clientSecret string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("auth "+clientId+" --client-credentials", func(out, errOut io.Writer) error {
+		if c.session.uaaUrl == "" {
+			return errors.New("No API endpoint set. Use 'cf api' to set an endpoint")
+		}
+		fmt.Fprintf(out, "Authenticating as client %s...\n", clientId) //nolint:errcheck
+
+		c.session.clientId, c.session.clientSecret = clientId, clientSecret
+		c.session.refreshToken = ""
+		err := c.session.requestToken(url.Values{"grant_type": {"client_credentials"}})
+		if err != nil {
+			c.session.clientId, c.session.clientSecret = "", ""
+			return fmt.Errorf("Authentication as client %s failed: %s", clientId, err)
+		}
+
+		fmt.Fprintln(out, "OK") //nolint:errcheck
+		return nil
+	})
+}
+
+func (c *cfApi) CreateQuota(quota string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("create-quota "+quota, func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Creating quota %s...\n", quota) //nolint:errcheck
+
+		quotaGuid, err := c.session.find("/v3/organization_quotas", url.Values{"names": {quota}})
+		if err != nil {
+			return err
+		}
+		if quotaGuid != "" {
+			fmt.Fprintf(out, "Organization quota '%s' already exists.\n", quota) //nolint:errcheck
+			return nil
+		}
+
+		_, err = c.session.cc(http.MethodPost, "/v3/organization_quotas", map[string]interface{}{
+			"name": quota,
+			"apps": map[string]interface{}{
+				"total_memory_in_mb":       10 * 1024,
+				"per_process_memory_in_mb": 1024,
+			},
+			"routes": map[string]interface{}{
+				"total_routes":         1000,
+				"total_reserved_ports": 1,
+			},
+			"services": map[string]interface{}{
+				"total_service_instances": 100,
+			},
+		}, nil)
+		return err
+	})
+}
+
+func (c *cfApi) SetQuota(org, quota string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("set-quota "+org+" "+quota, func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Setting quota %s to org %s...\n", quota, org) //nolint:errcheck
+
+		quotaGuid, err := c.session.mustFind("Quota", quota, "/v3/organization_quotas", url.Values{"names": {quota}})
+		if err != nil {
+			return err
+		}
+		orgGuid, err := c.session.mustFind("Organization", org, "/v3/organizations", url.Values{"names": {org}})
+		if err != nil {
+			return err
+		}
+
+		_, err = c.session.cc(http.MethodPost, "/v3/organization_quotas/"+quotaGuid+"/relationships/organizations", map[string]interface{}{
+			"data": []map[string]string{{"guid": orgGuid}},
+		}, nil)
+		return err
+	})
+}
+
+func (c *cfApi) CreateOrg(org string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("create-org "+org, func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Creating org %s...\n", org) //nolint:errcheck
+
+		orgGuid, err := c.session.find("/v3/organizations", url.Values{"names": {org}})
+		if err != nil {
+			return err
+		}
+		if orgGuid != "" {
+			fmt.Fprintf(out, "Organization '%s' already exists.\n", org) //nolint:errcheck
+			return nil
+		}
+
+		_, err = c.session.cc(http.MethodPost, "/v3/organizations", map[string]interface{}{"name": org}, nil)
+		return err
+	})
+}
+
+func (c *cfApi) CreateSpace(org, space string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("create-space "+space+" -o "+org, func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Creating space %s in org %s...\n", space, org) //nolint:errcheck
+
+		orgGuid, err := c.session.mustFind("Organization", org, "/v3/organizations", url.Values{"names": {org}})
+		if err != nil {
+			return err
+		}
+		spaceGuid, err := c.session.find("/v3/spaces", url.Values{"names": {space}, "organization_guids": {orgGuid}})
+		if err != nil {
+			return err
+		}
+		if spaceGuid != "" {
+			fmt.Fprintf(out, "Space '%s' already exists.\n", space) //nolint:errcheck
+			return nil
+		}
+
+		_, err = c.session.cc(http.MethodPost, "/v3/spaces", map[string]interface{}{
+			"name": space,
+			"relationships": map[string]interface{}{
+				"organization": relationship(orgGuid),
+			},
+		}, nil)
+		return err
+	})
+}
+
+func (c *cfApi) EnableOrgIsolation(org, isolationSegment string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("enable-org-isolation "+org+" "+isolationSegment, func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Enabling isolation segment %s for org %s...\n", isolationSegment, org) //nolint:errcheck
+
+		segmentGuid, err := c.session.mustFind("Isolation segment", isolationSegment, "/v3/isolation_segments", url.Values{"names": {isolationSegment}})
+		if err != nil {
+			return err
+		}
+		orgGuid, err := c.session.mustFind("Organization", org, "/v3/organizations", url.Values{"names": {org}})
+		if err != nil {
+			return err
+		}
+
+		_, err = c.session.cc(http.MethodPost, "/v3/isolation_segments/"+segmentGuid+"/relationships/organizations", map[string]interface{}{
+			"data": []map[string]string{{"guid": orgGuid}},
+		}, nil)
+		return err
+	})
+}
+
+func (c *cfApi) SetOrgDefaultIsolationSegment(org, isolationSegment string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("set-org-default-isolation-segment "+org+" "+isolationSegment, func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Setting isolation segment %s as default for org %s...\n", isolationSegment, org) //nolint:errcheck
+
+		segmentGuid, err := c.session.mustFind("Isolation segment", isolationSegment, "/v3/isolation_segments", url.Values{"names": {isolationSegment}})
+		if err != nil {
+			return err
+		}
+		orgGuid, err := c.session.mustFind("Organization", org, "/v3/organizations", url.Values{"names": {org}})
+		if err != nil {
+			return err
+		}
+
+		_, err = c.session.cc(http.MethodPatch, "/v3/organizations/"+orgGuid+"/relationships/default_isolation_segment", relationship(segmentGuid), nil)
+		return err
+	})
+}
+
+func (c *cfApi) Target(org, space string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("target -o "+org+" -s "+space, func(out, errOut io.Writer) error {
+		orgGuid, err := c.session.mustFind("Organization", org, "/v3/organizations", url.Values{"names": {org}})
+		if err != nil {
+			return err
+		}
+		spaceGuid, err := c.session.mustFind("Space", space, "/v3/spaces", url.Values{"names": {space}, "organization_guids": {orgGuid}})
+		if err != nil {
+			return err
+		}
+
+		c.session.orgGuid = orgGuid
+		c.session.spaceGuid = spaceGuid
+		fmt.Fprintf(out, "org:   %s\nspace: %s\n", org, space) //nolint:errcheck
+		return nil
+	})
+}
+
+func (c *cfApi) SetLabels(resourceType, name string, labels map[string]string) cmdStartWaiter.CmdStartWaiter {
+	return c.setMetadata("set-label", "labels", resourceType, name, labels)
+}
+
+func (c *cfApi) SetAnnotations(resourceType, name string, annotations map[string]string) cmdStartWaiter.CmdStartWaiter {
+	return c.setMetadata("set-annotation", "annotations", resourceType, name, annotations)
+}
+
+// setMetadata patches the labels or annotations, as kind says, of a
+// resource.
+func (c *cfApi) setMetadata(command, kind, resourceType, name string, values map[string]string) cmdStartWaiter.CmdStartWaiter {
+	description := command + " " + resourceType + " " + name
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		description += " " + key + "=" + values[key]
+	}
+
+	return c.operation(description, func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Setting %s of %s %s...\n", kind, resourceType, name) //nolint:errcheck
+
+		path, err := c.labelledResource(resourceType, name)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.session.cc(http.MethodPatch, path, map[string]interface{}{
+			"metadata": map[string]interface{}{kind: values},
+		}, nil)
+		return err
+	})
+}
+
+// labelledResource finds the path of a resource the way cf set-label does,
+// looking spaces up in the targeted org and apps and service instances up in
+// the targeted space.
+func (c *cfApi) labelledResource(resourceType, name string) (string, error) {
+	switch resourceType {
+	case "org":
+		orgGuid, err := c.session.mustFind("Organization", name, "/v3/organizations", url.Values{"names": {name}})
+		return "/v3/organizations/" + orgGuid, err
+	case "space":
+		if c.session.orgGuid == "" {
+			return "", errors.New("No org targeted, use 'cf target -o ORG' to target an org")
+		}
+		spaceGuid, err := c.session.mustFind("Space", name, "/v3/spaces", url.Values{"names": {name}, "organization_guids": {c.session.orgGuid}})
+		return "/v3/spaces/" + spaceGuid, err
+	case "app":
+		appGuid, err := c.session.appGuid(name)
+		return "/v3/apps/" + appGuid, err
+	case "service-instance":
+		spaceGuid, err := c.session.targetedSpace()
+		if err != nil {
+			return "", err
+		}
+		serviceGuid, err := c.session.mustFind("Service instance", name, "/v3/service_instances", url.Values{"names": {name}, "space_guids": {spaceGuid}})
+		return "/v3/service_instances/" + serviceGuid, err
+	default:
+		return "", fmt.Errorf("Unsupported resource type of '%s'", resourceType)
+	}
+}
+
+func (c *cfApi) Push(name, path string, instances int, noRoute bool) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("push "+name, func(out, errOut io.Writer) error {
+		return c.push(out, pushOptions{
+			name:      name,
+			path:      path,
+			instances: instances,
+			noRoute:   noRoute,
+			buildpack: c.defaultBuildpack(),
+		})
+	})
+}
+
+func (c *cfApi) RollingPush(name, path string, instances int) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("push "+name+" --strategy rolling", func(out, errOut io.Writer) error {
+		return c.push(out, pushOptions{
+			name:      name,
+			path:      path,
+			instances: instances,
+			rolling:   true,
+			buildpack: c.defaultBuildpack(),
+		})
+	})
+}
+
+func (c *cfApi) PushWithBuildpack(name, path, buildpack, stack string, instances int) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("push "+name, func(out, errOut io.Writer) error {
+		return c.push(out, pushOptions{
+			name:      name,
+			path:      path,
+			instances: instances,
+			buildpack: buildpack,
+			stack:     stack,
+		})
+	})
+}
+
+func (c *cfApi) PushDockerImage(name, image, username, password string, instances int) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("push "+name+" --docker-image "+image, func(out, errOut io.Writer) error {
+		return c.push(out, pushOptions{
+			name:           name,
+			instances:      instances,
+			dockerImage:    image,
+			dockerUsername: username,
+			dockerPassword: password,
+		})
+	})
+}
+
+func (c *cfApi) defaultBuildpack() string {
+	if c.useBuildpackDetection {
+		return ""
+	}
+
+	return "go_buildpack"
+}
+
+func (c *cfApi) Delete(name string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("delete "+name+" -f -r", func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Deleting app %s...\n", name) //nolint:errcheck
+
+		spaceGuid, err := c.session.targetedSpace()
+		if err != nil {
+			return err
+		}
+		appGuid, err := c.session.find("/v3/apps", url.Values{"names": {name}, "space_guids": {spaceGuid}})
+		if err != nil {
+			return err
+		}
+		if appGuid == "" {
+			fmt.Fprintf(out, "App '%s' does not exist.\n", name) //nolint:errcheck
+			return nil
+		}
+
+		routes := &resourceList{}
+		if _, err := c.session.cc(http.MethodGet, "/v3/apps/"+appGuid+"/routes", nil, routes); err != nil {
+			return err
+		}
+		for _, route := range routes.Resources {
+			if err := c.delete("/v3/routes/" + route.Guid); err != nil {
+				return err
+			}
+		}
+
+		return c.delete("/v3/apps/" + appGuid)
+	})
+}
+
+func (c *cfApi) DeleteOrg(org string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("delete-org "+org+" -f", func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Deleting org %s...\n", org) //nolint:errcheck
+
+		orgGuid, err := c.session.find("/v3/organizations", url.Values{"names": {org}})
+		if err != nil {
+			return err
+		}
+		if orgGuid == "" {
+			fmt.Fprintf(out, "Org '%s' does not exist.\n", org) //nolint:errcheck
+			return nil
+		}
+
+		if err := c.delete("/v3/organizations/" + orgGuid); err != nil {
+			return err
+		}
+		if c.session.orgGuid == orgGuid {
+			c.session.orgGuid, c.session.spaceGuid = "", ""
+		}
+
+		return nil
+	})
+}
+
+func (c *cfApi) DeleteQuota(quota string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("delete-quota "+quota+" -f", func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Deleting quota %s...\n", quota) //nolint:errcheck
+
+		quotaGuid, err := c.session.find("/v3/organization_quotas", url.Values{"names": {quota}})
+		if err != nil {
+			return err
+		}
+		if quotaGuid == "" {
+			fmt.Fprintf(out, "Quota '%s' does not exist.\n", quota) //nolint:errcheck
+			return nil
+		}
+
+		return c.delete("/v3/organization_quotas/" + quotaGuid)
+	})
+}
+
+func (c *cfApi) delete(path string) error {
+	jobUrl, err := c.session.cc(http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.session.awaitJob(jobUrl)
+}
+
+func (c *cfApi) LogOut() cmdStartWaiter.CmdStartWaiter {
+	return c.operation("logout", func(out, errOut io.Writer) error {
+		c.session.accessToken, c.session.refreshToken = "", ""
+		c.session.clientId, c.session.clientSecret = "", ""
+		c.session.orgGuid, c.session.spaceGuid = "", ""
+		fmt.Fprintln(out, "Logging out...\nOK") //nolint:errcheck
+		return nil
+	})
+}
+
+func (c *cfApi) AppStats(appName string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("app "+appName, func(out, errOut io.Writer) error {
+		return c.appStats(out, appName)
+	})
+}
+
+func (c *cfApi) RecentLogs(appName string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("logs "+appName+" --recent", func(out, errOut io.Writer) error {
+		return c.recentLogs(out, appName)
+	})
+}
+
+func (c *cfApi) StreamLogs(ctx context.Context, appName string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("logs "+appName, func(out, errOut io.Writer) error {
+		return c.streamLogs(ctx, out, appName)
+	})
+}
+
+func (c *cfApi) MapRoute(appName, domain string, port int) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("map-route "+appName+" "+domain+" --port "+strconv.Itoa(port), func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Mapping route %s:%d to app %s...\n", domain, port, appName) //nolint:errcheck
+
+		appGuid, err := c.session.appGuid(appName)
+		if err != nil {
+			return err
+		}
+		domainGuid, err := c.session.mustFind("Domain", domain, "/v3/domains", url.Values{"names": {domain}})
+		if err != nil {
+			return err
+		}
+
+		routeGuid, err := c.session.find("/v3/routes", url.Values{"domain_guids": {domainGuid}, "ports": {strconv.Itoa(port)}})
+		if err != nil {
+			return err
+		}
+		if routeGuid == "" {
+			route := &resource{}
+			_, err = c.session.cc(http.MethodPost, "/v3/routes", map[string]interface{}{
+				"port": port,
+				"relationships": map[string]interface{}{
+					"space":  relationship(c.session.spaceGuid),
+					"domain": relationship(domainGuid),
+				},
+			}, route)
+			if err != nil {
+				return err
+			}
+			routeGuid = route.Guid
+		}
+
+		_, err = c.session.cc(http.MethodPost, "/v3/routes/"+routeGuid+"/destinations", map[string]interface{}{
+			"destinations": []map[string]interface{}{
+				{"app": map[string]string{"guid": appGuid}},
+			},
+		}, nil)
+		return err
+	})
+}
+
+func (c *cfApi) CreateUserProvidedService(serviceName, syslogUrl string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("create-user-provided-service "+serviceName, func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Creating user provided service %s...\n", serviceName) //nolint:errcheck
+
+		spaceGuid, err := c.session.targetedSpace()
+		if err != nil {
+			return err
+		}
+		serviceGuid, err := c.session.find("/v3/service_instances", url.Values{"names": {serviceName}, "space_guids": {spaceGuid}})
+		if err != nil {
+			return err
+		}
+		if serviceGuid != "" {
+			fmt.Fprintf(out, "Service instance %s already exists\n", serviceName) //nolint:errcheck
+			return nil
+		}
+
+		_, err = c.session.cc(http.MethodPost, "/v3/service_instances", map[string]interface{}{
+			"type":             "user-provided",
+			"name":             serviceName,
+			"syslog_drain_url": syslogUrl,
+			"relationships": map[string]interface{}{
+				"space": relationship(spaceGuid),
+			},
+		}, nil)
+		return err
+	})
+}
+
+func (c *cfApi) BindService(appName, serviceName string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("bind-service "+appName+" "+serviceName, func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Binding service %s to app %s...\n", serviceName, appName) //nolint:errcheck
+
+		appGuid, err := c.session.appGuid(appName)
+		if err != nil {
+			return err
+		}
+		serviceGuid, err := c.session.mustFind("Service instance", serviceName, "/v3/service_instances", url.Values{"names": {serviceName}, "space_guids": {c.session.spaceGuid}})
+		if err != nil {
+			return err
+		}
+		bindingGuid, err := c.session.find("/v3/service_credential_bindings", url.Values{"app_guids": {appGuid}, "service_instance_guids": {serviceGuid}})
+		if err != nil {
+			return err
+		}
+		if bindingGuid != "" {
+			fmt.Fprintf(out, "App %s is already bound to service instance %s.\n", appName, serviceName) //nolint:errcheck
+			return nil
+		}
+
+		jobUrl, err := c.session.cc(http.MethodPost, "/v3/service_credential_bindings", map[string]interface{}{
+			"type": "app",
+			"relationships": map[string]interface{}{
+				"service_instance": relationship(serviceGuid),
+				"app":              relationship(appGuid),
+			},
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		return c.session.awaitJob(jobUrl)
+	})
+}
+
+func (c *cfApi) UnbindService(appName, serviceName string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("unbind-service "+appName+" "+serviceName, func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Unbinding app %s from service %s...\n", appName, serviceName) //nolint:errcheck
+
+		spaceGuid, err := c.session.targetedSpace()
+		if err != nil {
+			return err
+		}
+		appGuid, err := c.session.find("/v3/apps", url.Values{"names": {appName}, "space_guids": {spaceGuid}})
+		if err != nil {
+			return err
+		}
+		serviceGuid, err := c.session.find("/v3/service_instances", url.Values{"names": {serviceName}, "space_guids": {spaceGuid}})
+		if err != nil {
+			return err
+		}
+		bindingGuid := ""
+		if appGuid != "" && serviceGuid != "" {
+			bindingGuid, err = c.session.find("/v3/service_credential_bindings", url.Values{"app_guids": {appGuid}, "service_instance_guids": {serviceGuid}})
+			if err != nil {
+				return err
+			}
+		}
+		if bindingGuid == "" {
+			fmt.Fprintf(out, "Binding between %s and %s did not exist\n", serviceName, appName) //nolint:errcheck
+			return nil
+		}
+
+		return c.delete("/v3/service_credential_bindings/" + bindingGuid)
+	})
+}
+
+func (c *cfApi) DeleteService(serviceName string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("delete-service "+serviceName+" -f", func(out, errOut io.Writer) error {
+		fmt.Fprintf(out, "Deleting service %s...\n", serviceName) //nolint:errcheck
+
+		spaceGuid, err := c.session.targetedSpace()
+		if err != nil {
+			return err
+		}
+		serviceGuid, err := c.session.find("/v3/service_instances", url.Values{"names": {serviceName}, "space_guids": {spaceGuid}})
+		if err != nil {
+			return err
+		}
+		if serviceGuid == "" {
+			fmt.Fprintf(out, "Service instance %s did not exist.\n", serviceName) //nolint:errcheck
+			return nil
+		}
+
+		return c.delete("/v3/service_instances/" + serviceGuid)
+	})
+}
+
+func (c *cfApi) PurgeServiceInstance(serviceGuid string) cmdStartWaiter.CmdStartWaiter {
+	path := fmt.Sprintf("/v3/service_instances/%s?purge=true", serviceGuid)
+	return c.operation("curl --fail -X DELETE "+path, func(out, errOut io.Writer) error {
+		return c.delete(path)
+	})
+}
+
+func (c *cfApi) Curl(path string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("curl --fail "+path, func(out, errOut io.Writer) error {
+		var body json.RawMessage
+		if _, err := c.session.cc(http.MethodGet, path, nil, &body); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(out, string(body)) //nolint:errcheck
+		return nil
+	})
+}
+
+func (c *cfApi) Restage(appName string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("restage "+appName, func(out, errOut io.Writer) error {
+		return c.restage(out, appName)
+	})
+}
+
+func (c *cfApi) AppGuid(appName string) cmdStartWaiter.CmdStartWaiter {
+	return c.operation("app "+appName+" --guid", func(out, errOut io.Writer) error {
+		appGuid, err := c.session.appGuid(appName)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(out, appGuid) //nolint:errcheck
+		return nil
+	})
+}
+
+func (c *cfApi) OauthToken() cmdStartWaiter.CmdStartWaiter {
+	return c.operation("oauth-token", func(out, errOut io.Writer) error {
+		if c.session.accessToken == "" {
+			return errors.New("Not logged in. Use 'cf login' or 'cf auth' to log in.")
+		}
+
+		fmt.Fprintf(out, "bearer %s\n", c.session.accessToken) //nolint:errcheck
+		return nil
+	})
+}
+
+// AccessToken returns the session's current access token.
+func (c *cfApi) AccessToken() (string, error) {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+
+	if c.session.accessToken == "" {
+		return "", errors.New("Not logged in. Use 'cf login' or 'cf auth' to log in.")
+	}
+
+	return c.session.accessToken, nil
+}
+
+func (c *cfApi) RefreshToken() cmdStartWaiter.CmdStartWaiter {
+	return c.operation("oauth-token", func(out, errOut io.Writer) error {
+		if !c.session.canRefresh() {
+			return errors.New("Not logged in. Use 'cf login' or 'cf auth' to log in.")
+		}
+
+		if err := c.session.refresh(); err != nil {
+			return fmt.Errorf("Failed to refresh token: %s", err)
+		}
+
+		fmt.Fprintf(out, "bearer %s\n", c.session.accessToken) //nolint:errcheck
+		return nil
+	})
+}
@@ -0,0 +1,13 @@
+package cfApi_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCfApi(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CfApi Suite")
+}
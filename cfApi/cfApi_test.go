@@ -0,0 +1,871 @@
+package cfApi_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+	"gopkg.in/yaml.v3"
+
+	. "github.com/cloudfoundry/uptimer/cfApi"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CfApi", func() {
+	var (
+		cc                    *fakeCC
+		useBuildpackDetection bool
+		generator             cfCmdGenerator.CfCmdGenerator
+		outBuf                *bytes.Buffer
+		errBuf                *bytes.Buffer
+		runner                cmdRunner.CmdRunner
+	)
+
+	run := func(csws ...cmdStartWaiter.CmdStartWaiter) error {
+		return runner.RunInSequence(csws...)
+	}
+
+	login := func() {
+		Expect(run(generator.Api(cc.URL()), generator.Auth("admin", "pass"))).To(Succeed())
+	}
+
+	target := func() {
+		login()
+		Expect(run(generator.Target("some-org", "some-space"))).To(Succeed())
+	}
+
+	decodeBody := func(req recordedRequest) map[string]interface{} {
+		body := map[string]interface{}{}
+		Expect(json.Unmarshal(req.Body, &body)).To(Succeed())
+		return body
+	}
+
+	BeforeEach(func() {
+		useBuildpackDetection = false
+		outBuf = &bytes.Buffer{}
+		errBuf = &bytes.Buffer{}
+		runner = cmdRunner.New(outBuf, errBuf, io.Copy)
+
+		cc = newFakeCC()
+		cc.respondWithResources("GET /v3/organizations", map[string]string{"some-org": "org-guid"})
+		cc.respondWithResources("GET /v3/spaces", map[string]string{"some-space": "space-guid"})
+		cc.respondWithResources("GET /v3/apps", map[string]string{"some-app": "app-guid"})
+		cc.respond("GET /v3/jobs/job-guid", http.StatusOK, `{"state": "COMPLETE"}`)
+	})
+
+	JustBeforeEach(func() {
+		generator = New(http.DefaultClient, useBuildpackDetection)
+	})
+
+	AfterEach(func() {
+		cc.Close()
+	})
+
+	Describe("Api and Auth", func() {
+		It("authenticates against the UAA linked from the API root with the cf client", func() {
+			login()
+
+			tokenRequests := cc.requestsTo("POST /oauth/token")
+			Expect(tokenRequests).To(HaveLen(1))
+			Expect(tokenRequests[0].Authorization).To(Equal("Basic Y2Y6"))
+			form, err := url.ParseQuery(string(tokenRequests[0].Body))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form.Get("grant_type")).To(Equal("password"))
+			Expect(form.Get("username")).To(Equal("admin"))
+			Expect(form.Get("password")).To(Equal("pass"))
+		})
+
+		It("sends the token with Cloud Controller requests", func() {
+			target()
+
+			Expect(cc.requestsTo("GET /v3/organizations")[0].Authorization).To(Equal("bearer some-token"))
+		})
+
+		It("fails when the credentials are rejected", func() {
+			cc.respond("POST /oauth/token", http.StatusUnauthorized, `{"error": "unauthorized", "error_description": "Bad credentials"}`)
+
+			err := run(generator.Api(cc.URL()), generator.Auth("admin", "wrong"))
+
+			Expect(err).To(MatchError("Authentication as admin failed: Bad credentials"))
+			Expect(errBuf.String()).To(ContainSubstring("FAILED"))
+		})
+
+		It("fails to authenticate without an API", func() {
+			Expect(run(generator.Auth("admin", "pass"))).To(MatchError(ContainSubstring("No API endpoint set")))
+		})
+
+		It("authenticates as a client with client credentials", func() {
+			Expect(run(generator.Api(cc.URL()), generator.AuthClientCredentials("uptimer", "secret"))).To(Succeed())
+
+			tokenRequests := cc.requestsTo("POST /oauth/token")
+			Expect(tokenRequests).To(HaveLen(1))
+			Expect(tokenRequests[0].Authorization).To(Equal("Basic dXB0aW1lcjpzZWNyZXQ="))
+			form, err := url.ParseQuery(string(tokenRequests[0].Body))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form.Get("grant_type")).To(Equal("client_credentials"))
+		})
+
+		It("requests a new client token once the token is rejected", func() {
+			cc.respond("POST /oauth/token", http.StatusOK, `{"access_token": "some-token", "token_type": "bearer"}`)
+			Expect(run(generator.Api(cc.URL()), generator.AuthClientCredentials("uptimer", "secret"))).To(Succeed())
+			rejected := false
+			cc.handle("POST /v3/organizations", func(w http.ResponseWriter, r *http.Request) {
+				if !rejected {
+					rejected = true
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			})
+
+			Expect(run(generator.CreateOrg("new-org"))).To(Succeed())
+
+			tokenRequests := cc.requestsTo("POST /oauth/token")
+			Expect(tokenRequests).To(HaveLen(2))
+			Expect(tokenRequests[1].Authorization).To(Equal("Basic dXB0aW1lcjpzZWNyZXQ="))
+			form, err := url.ParseQuery(string(tokenRequests[1].Body))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form.Get("grant_type")).To(Equal("client_credentials"))
+		})
+
+		It("fails when the client credentials are rejected", func() {
+			cc.respond("POST /oauth/token", http.StatusUnauthorized, `{"error": "unauthorized", "error_description": "Bad credentials"}`)
+
+			err := run(generator.Api(cc.URL()), generator.AuthClientCredentials("uptimer", "wrong"))
+
+			Expect(err).To(MatchError("Authentication as client uptimer failed: Bad credentials"))
+		})
+	})
+
+	Describe("errors", func() {
+		It("reports Cloud Controller errors on stderr", func() {
+			login()
+			cc.respond("POST /v3/organizations", http.StatusUnprocessableEntity, `{"errors": [{"code": 10008, "title": "CF-UnprocessableEntity", "detail": "Organization 'new-org' already exists."}]}`)
+
+			err := run(generator.CreateOrg("new-org"))
+
+			Expect(err).To(MatchError("Organization 'new-org' already exists. (CF-UnprocessableEntity)"))
+			Expect(errBuf.String()).To(ContainSubstring("Organization 'new-org' already exists."))
+		})
+
+		It("refreshes a rejected token and repeats the request", func() {
+			login()
+			rejected := false
+			cc.handle("POST /v3/organizations", func(w http.ResponseWriter, r *http.Request) {
+				if !rejected {
+					rejected = true
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			})
+
+			Expect(run(generator.CreateOrg("new-org"))).To(Succeed())
+
+			tokenRequests := cc.requestsTo("POST /oauth/token")
+			Expect(tokenRequests).To(HaveLen(2))
+			form, err := url.ParseQuery(string(tokenRequests[1].Body))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form.Get("grant_type")).To(Equal("refresh_token"))
+			Expect(form.Get("refresh_token")).To(Equal("some-refresh-token"))
+			Expect(cc.requestsTo("POST /v3/organizations")).To(HaveLen(2))
+		})
+
+		It("reports tokens that cannot be refreshed the way the cf CLI does", func() {
+			login()
+			cc.respond("POST /oauth/token", http.StatusUnauthorized, `{"error": "invalid_token"}`)
+			cc.respond("POST /v3/organizations", http.StatusUnauthorized, `{"errors": [{"code": 1000, "title": "CF-InvalidAuthToken", "detail": "Invalid Auth Token"}]}`)
+
+			Expect(run(generator.CreateOrg("new-org"))).To(HaveOccurred())
+			Expect(errBuf.String()).To(ContainSubstring(cfCmdGenerator.AuthExpiredMessage))
+		})
+
+		It("requires a login", func() {
+			Expect(run(generator.Api(cc.URL()), generator.CreateOrg("some-org"))).To(MatchError(ContainSubstring("Not logged in")))
+		})
+	})
+
+	Describe("Setup operations", func() {
+		BeforeEach(func() {
+			cc.respond("POST /v3/organizations", http.StatusCreated, `{"guid": "org-guid"}`)
+			cc.respond("POST /v3/spaces", http.StatusCreated, `{"guid": "space-guid"}`)
+			cc.respondWithResources("GET /v3/organization_quotas", map[string]string{"some-quota": "quota-guid"})
+			cc.respond("POST /v3/organization_quotas", http.StatusCreated, `{"guid": "quota-guid"}`)
+			cc.respond("POST /v3/organization_quotas/quota-guid/relationships/organizations", http.StatusOK, `{}`)
+			cc.respond("GET /v3/isolation_segments", http.StatusOK, `{"resources": [{"guid": "segment-guid"}]}`)
+			cc.respond("POST /v3/isolation_segments/segment-guid/relationships/organizations", http.StatusOK, `{}`)
+			cc.respond("PATCH /v3/organizations/org-guid/relationships/default_isolation_segment", http.StatusOK, `{}`)
+		})
+
+		It("creates the org and space", func() {
+			login()
+
+			Expect(run(generator.CreateOrg("new-org"), generator.CreateSpace("some-org", "new-space"))).To(Succeed())
+
+			Expect(decodeBody(cc.requestsTo("POST /v3/organizations")[0])).To(Equal(map[string]interface{}{"name": "new-org"}))
+			Expect(decodeBody(cc.requestsTo("POST /v3/spaces")[0])).To(Equal(map[string]interface{}{
+				"name": "new-space",
+				"relationships": map[string]interface{}{
+					"organization": map[string]interface{}{"data": map[string]interface{}{"guid": "org-guid"}},
+				},
+			}))
+		})
+
+		It("creates and sets the quota", func() {
+			login()
+
+			Expect(run(generator.CreateQuota("new-quota"), generator.SetQuota("some-org", "some-quota"))).To(Succeed())
+
+			quota := decodeBody(cc.requestsTo("POST /v3/organization_quotas")[0])
+			Expect(quota["name"]).To(Equal("new-quota"))
+			Expect(quota["apps"]).To(HaveKeyWithValue("total_memory_in_mb", BeNumerically("==", 10240)))
+			Expect(quota["routes"]).To(HaveKeyWithValue("total_reserved_ports", BeNumerically("==", 1)))
+			Expect(decodeBody(cc.requestsTo("POST /v3/organization_quotas/quota-guid/relationships/organizations")[0])).To(Equal(map[string]interface{}{
+				"data": []interface{}{map[string]interface{}{"guid": "org-guid"}},
+			}))
+		})
+
+		It("leaves the org, space and quota alone when they already exist, like the cf CLI", func() {
+			login()
+
+			Expect(run(
+				generator.CreateOrg("some-org"),
+				generator.CreateSpace("some-org", "some-space"),
+				generator.CreateQuota("some-quota"),
+			)).To(Succeed())
+
+			Expect(cc.requestsTo("POST /v3/organizations")).To(BeEmpty())
+			Expect(cc.requestsTo("POST /v3/spaces")).To(BeEmpty())
+			Expect(cc.requestsTo("POST /v3/organization_quotas")).To(BeEmpty())
+			Expect(outBuf.String()).To(ContainSubstring("Organization 'some-org' already exists."))
+			Expect(outBuf.String()).To(ContainSubstring("Space 'some-space' already exists."))
+			Expect(outBuf.String()).To(ContainSubstring("Organization quota 'some-quota' already exists."))
+		})
+
+		It("isolates the org", func() {
+			login()
+
+			Expect(run(
+				generator.EnableOrgIsolation("some-org", "some-segment"),
+				generator.SetOrgDefaultIsolationSegment("some-org", "some-segment"),
+			)).To(Succeed())
+
+			Expect(cc.requestsTo("POST /v3/isolation_segments/segment-guid/relationships/organizations")).To(HaveLen(1))
+			Expect(decodeBody(cc.requestsTo("PATCH /v3/organizations/org-guid/relationships/default_isolation_segment")[0])).To(Equal(map[string]interface{}{
+				"data": map[string]interface{}{"guid": "segment-guid"},
+			}))
+		})
+	})
+
+	Describe("Target", func() {
+		It("looks up the space within the org", func() {
+			target()
+
+			Expect(cc.requestsTo("GET /v3/spaces")[0].Query).To(Equal("names=some-space&organization_guids=org-guid"))
+		})
+
+		It("fails when the space does not exist", func() {
+			cc.respond("GET /v3/spaces", http.StatusOK, `{"resources": []}`)
+			login()
+
+			Expect(run(generator.Target("some-org", "some-space"))).To(MatchError("Space 'some-space' not found."))
+		})
+
+		It("is required by space scoped operations", func() {
+			login()
+
+			Expect(run(generator.AppGuid("some-app"))).To(MatchError(ContainSubstring("No org and space targeted")))
+		})
+	})
+
+	Describe("SetLabels", func() {
+		var labels map[string]string
+
+		BeforeEach(func() {
+			labels = map[string]string{"uptimer.cloudfoundry.org/run-id": "some-run-id"}
+			cc.respond("PATCH /v3/organizations/org-guid", http.StatusOK, `{}`)
+			cc.respond("PATCH /v3/spaces/space-guid", http.StatusOK, `{}`)
+			cc.respond("PATCH /v3/apps/app-guid", http.StatusOK, `{}`)
+			cc.respond("PATCH /v3/service_instances/service-guid", http.StatusOK, `{}`)
+			cc.respond("GET /v3/service_instances", http.StatusOK, `{"resources": [{"guid": "service-guid"}]}`)
+		})
+
+		It("patches the labels of each kind of resource", func() {
+			target()
+
+			Expect(run(
+				generator.SetLabels("org", "some-org", labels),
+				generator.SetLabels("space", "some-space", labels),
+				generator.SetLabels("app", "some-app", labels),
+				generator.SetLabels("service-instance", "some-service", labels),
+			)).To(Succeed())
+
+			expectedBody := map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"uptimer.cloudfoundry.org/run-id": "some-run-id"},
+				},
+			}
+			for _, path := range []string{"organizations/org-guid", "spaces/space-guid", "apps/app-guid", "service_instances/service-guid"} {
+				Expect(decodeBody(cc.requestsTo("PATCH /v3/" + path)[0])).To(Equal(expectedBody))
+			}
+			Expect(cc.requestsTo("GET /v3/service_instances")[0].Query).To(Equal("names=some-service&space_guids=space-guid"))
+		})
+
+		It("patches the annotations of a resource", func() {
+			target()
+
+			Expect(run(generator.SetAnnotations("org", "some-org", map[string]string{"uptimer.cloudfoundry.org/host": "some host"}))).To(Succeed())
+
+			Expect(decodeBody(cc.requestsTo("PATCH /v3/organizations/org-guid")[0])).To(Equal(map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{"uptimer.cloudfoundry.org/host": "some host"},
+				},
+			}))
+			Expect(fmt.Sprint(generator.SetAnnotations("org", "some-org", map[string]string{"b": "2", "a": "1"}))).To(Equal("set-annotation org some-org a=1 b=2"))
+		})
+
+		It("describes itself like cf set-label", func() {
+			Expect(fmt.Sprint(generator.SetLabels("org", "some-org", map[string]string{"b": "2", "a": "1"}))).To(Equal("set-label org some-org a=1 b=2"))
+		})
+
+		It("fails for resources it cannot label", func() {
+			target()
+
+			Expect(run(generator.SetLabels("stack", "some-stack", labels))).To(MatchError("Unsupported resource type of 'stack'"))
+		})
+	})
+
+	Describe("pushing", func() {
+		var appDir string
+
+		BeforeEach(func() {
+			var err error
+			appDir, err = os.MkdirTemp("", "cfApi")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(appDir, "manifest.yml"), []byte("applications:\n- name: app\n  memory: 64M\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appDir, "app"), []byte("binary"), 0755)).To(Succeed())
+
+			cc.respondWithJob("POST /v3/spaces/space-guid/actions/apply_manifest", "/v3/jobs/job-guid")
+			cc.respond("POST /v3/packages", http.StatusCreated, `{"guid": "package-guid"}`)
+			cc.respond("POST /v3/packages/package-guid/upload", http.StatusOK, `{"guid": "package-guid"}`)
+			cc.respond("GET /v3/packages/package-guid", http.StatusOK, `{"state": "READY"}`)
+			cc.respond("POST /v3/builds", http.StatusCreated, `{"guid": "build-guid"}`)
+			cc.respond("GET /v3/builds/build-guid", http.StatusOK, `{"state": "STAGED", "droplet": {"guid": "droplet-guid"}}`)
+			cc.respond("PATCH /v3/apps/app-guid/relationships/current_droplet", http.StatusOK, `{}`)
+			cc.respond("POST /v3/apps/app-guid/actions/restart", http.StatusOK, `{}`)
+			cc.respond("POST /v3/deployments", http.StatusCreated, `{"guid": "deployment-guid"}`)
+			cc.respond("GET /v3/deployments/deployment-guid", http.StatusOK, `{"status": {"value": "FINALIZED", "reason": "DEPLOYED"}}`)
+			cc.respond("GET /v3/apps/app-guid/processes/web/stats", http.StatusOK, `{"resources": [{"index": 0, "state": "STARTING"}, {"index": 1, "state": "RUNNING"}]}`)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(appDir) //nolint:errcheck
+		})
+
+		appliedManifest := func() map[string]interface{} {
+			manifest := &struct {
+				Applications []map[string]interface{} `yaml:"applications"`
+			}{}
+			req := cc.requestsTo("POST /v3/spaces/space-guid/actions/apply_manifest")[0]
+			Expect(req.ContentType).To(Equal("application/x-yaml"))
+			Expect(yaml.Unmarshal(req.Body, manifest)).To(Succeed())
+			Expect(manifest.Applications).To(HaveLen(1))
+			return manifest.Applications[0]
+		}
+
+		uploadedFiles := func() map[string]os.FileMode {
+			req := cc.requestsTo("POST /v3/packages/package-guid/upload")[0]
+			_, params, err := mime.ParseMediaType(req.ContentType)
+			Expect(err).NotTo(HaveOccurred())
+			form, err := multipart.NewReader(bytes.NewReader(req.Body), params["boundary"]).ReadForm(1 << 20)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form.Value["resources"]).To(Equal([]string{"[]"}))
+
+			bits, err := form.File["bits"][0].Open()
+			Expect(err).NotTo(HaveOccurred())
+			contents, err := io.ReadAll(bits)
+			Expect(err).NotTo(HaveOccurred())
+			archive, err := zip.NewReader(bytes.NewReader(contents), int64(len(contents)))
+			Expect(err).NotTo(HaveOccurred())
+
+			files := map[string]os.FileMode{}
+			for _, f := range archive.File {
+				files[f.Name] = f.Mode()
+			}
+			return files
+		}
+
+		Describe("Push", func() {
+			It("applies the manifest, uploads, stages and starts the app", func() {
+				target()
+
+				Expect(run(generator.Push("some-app", appDir, 2, false))).To(Succeed())
+
+				Expect(cc.routes()).To(ContainElements(
+					"POST /v3/spaces/space-guid/actions/apply_manifest",
+					"GET /v3/jobs/job-guid",
+					"POST /v3/packages",
+					"POST /v3/packages/package-guid/upload",
+					"POST /v3/builds",
+					"PATCH /v3/apps/app-guid/relationships/current_droplet",
+					"POST /v3/apps/app-guid/actions/restart",
+					"GET /v3/apps/app-guid/processes/web/stats",
+				))
+				Expect(decodeBody(cc.requestsTo("POST /v3/builds")[0])).To(Equal(map[string]interface{}{
+					"package": map[string]interface{}{"guid": "package-guid"},
+				}))
+				Expect(decodeBody(cc.requestsTo("PATCH /v3/apps/app-guid/relationships/current_droplet")[0])).To(Equal(map[string]interface{}{
+					"data": map[string]interface{}{"guid": "droplet-guid"},
+				}))
+			})
+
+			It("overrides the manifest like the cf CLI's flags", func() {
+				target()
+
+				Expect(run(generator.Push("some-app", appDir, 2, false))).To(Succeed())
+
+				Expect(appliedManifest()).To(Equal(map[string]interface{}{
+					"name":          "some-app",
+					"memory":        "64M",
+					"instances":     2,
+					"buildpacks":    []interface{}{"go_buildpack"},
+					"default-route": true,
+				}))
+			})
+
+			It("uploads the app's files, keeping their modes", func() {
+				target()
+
+				Expect(run(generator.Push("some-app", appDir, 2, false))).To(Succeed())
+
+				files := uploadedFiles()
+				Expect(files).To(HaveKey("manifest.yml"))
+				Expect(files).To(HaveKeyWithValue("app", os.FileMode(0755)))
+			})
+
+			It("pushes without a route", func() {
+				target()
+
+				Expect(run(generator.Push("some-app", appDir, 1, true))).To(Succeed())
+
+				Expect(appliedManifest()).To(HaveKeyWithValue("no-route", true))
+				Expect(appliedManifest()).NotTo(HaveKey("default-route"))
+			})
+
+			Context("when using buildpack detection", func() {
+				BeforeEach(func() {
+					useBuildpackDetection = true
+				})
+
+				It("leaves the buildpack to the manifest", func() {
+					target()
+
+					Expect(run(generator.Push("some-app", appDir, 2, false))).To(Succeed())
+
+					Expect(appliedManifest()).NotTo(HaveKey("buildpacks"))
+				})
+			})
+
+			It("fails when staging fails", func() {
+				cc.respond("GET /v3/builds/build-guid", http.StatusOK, `{"state": "FAILED", "error": "NoAppDetectedError"}`)
+				target()
+
+				Expect(run(generator.Push("some-app", appDir, 2, false))).To(MatchError("Staging failed: NoAppDetectedError"))
+			})
+
+			It("fails when all instances crash", func() {
+				cc.respond("GET /v3/apps/app-guid/processes/web/stats", http.StatusOK, `{"resources": [{"index": 0, "state": "CRASHED"}]}`)
+				target()
+
+				Expect(run(generator.Push("some-app", appDir, 1, false))).To(MatchError("Start unsuccessful: all instances crashed"))
+			})
+
+			It("fails when applying the manifest fails", func() {
+				cc.respond("GET /v3/jobs/job-guid", http.StatusOK, `{"state": "FAILED", "errors": [{"detail": "Memory quota exceeded"}]}`)
+				target()
+
+				Expect(run(generator.Push("some-app", appDir, 1, false))).To(MatchError("Memory quota exceeded"))
+				Expect(cc.requestsTo("POST /v3/packages")).To(BeEmpty())
+			})
+		})
+
+		Describe("RollingPush", func() {
+			It("deploys the new droplet with a rolling deployment", func() {
+				target()
+
+				Expect(run(generator.RollingPush("some-app", appDir, 2))).To(Succeed())
+
+				deployment := decodeBody(cc.requestsTo("POST /v3/deployments")[0])
+				Expect(deployment["strategy"]).To(Equal("rolling"))
+				Expect(deployment["droplet"]).To(Equal(map[string]interface{}{"guid": "droplet-guid"}))
+				Expect(cc.requestsTo("POST /v3/apps/app-guid/actions/restart")).To(BeEmpty())
+			})
+
+			It("fails when the deployment is cancelled", func() {
+				cc.respond("GET /v3/deployments/deployment-guid", http.StatusOK, `{"status": {"value": "FINALIZED", "reason": "CANCELED"}}`)
+				target()
+
+				Expect(run(generator.RollingPush("some-app", appDir, 2))).To(MatchError("Rolling deployment CANCELED"))
+			})
+		})
+
+		Describe("PushWithBuildpack", func() {
+			It("pushes with the given buildpack and stack", func() {
+				target()
+
+				Expect(run(generator.PushWithBuildpack("some-app", appDir, "java_buildpack", "cflinuxfs4", 1))).To(Succeed())
+
+				Expect(appliedManifest()).To(HaveKeyWithValue("buildpacks", []interface{}{"java_buildpack"}))
+				Expect(appliedManifest()).To(HaveKeyWithValue("stack", "cflinuxfs4"))
+			})
+		})
+
+		Describe("PushDockerImage", func() {
+			It("creates a docker package with the registry credentials", func() {
+				target()
+
+				Expect(run(generator.PushDockerImage("some-app", "registry/image:tag", "user", "secret", 2))).To(Succeed())
+
+				Expect(appliedManifest()).To(HaveKeyWithValue("docker", map[string]interface{}{"image": "registry/image:tag", "username": "user"}))
+				pkg := decodeBody(cc.requestsTo("POST /v3/packages")[0])
+				Expect(pkg["type"]).To(Equal("docker"))
+				Expect(pkg["data"]).To(Equal(map[string]interface{}{"image": "registry/image:tag", "username": "user", "password": "secret"}))
+				Expect(cc.requestsTo("POST /v3/packages/package-guid/upload")).To(BeEmpty())
+			})
+		})
+
+		Describe("Restage", func() {
+			It("stages the newest package again and restarts the app", func() {
+				cc.respond("GET /v3/apps/app-guid/packages", http.StatusOK, `{"resources": [{"guid": "package-guid"}]}`)
+				target()
+
+				Expect(run(generator.Restage("some-app"))).To(Succeed())
+
+				Expect(cc.requestsTo("GET /v3/apps/app-guid/packages")[0].Query).To(Equal("order_by=-created_at&per_page=1&states=READY"))
+				Expect(cc.requestsTo("POST /v3/builds")).To(HaveLen(1))
+				Expect(cc.requestsTo("POST /v3/apps/app-guid/actions/restart")).To(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("deleting", func() {
+		BeforeEach(func() {
+			cc.respond("GET /v3/apps/app-guid/routes", http.StatusOK, `{"resources": [{"guid": "route-guid"}]}`)
+			cc.respondWithJob("DELETE /v3/routes/route-guid", "/v3/jobs/job-guid")
+			cc.respondWithJob("DELETE /v3/apps/app-guid", "/v3/jobs/job-guid")
+			cc.respondWithJob("DELETE /v3/organizations/org-guid", "/v3/jobs/job-guid")
+			cc.respond("GET /v3/organization_quotas", http.StatusOK, `{"resources": [{"guid": "quota-guid"}]}`)
+			cc.respondWithJob("DELETE /v3/organization_quotas/quota-guid", "/v3/jobs/job-guid")
+		})
+
+		It("deletes the app and its routes", func() {
+			target()
+
+			Expect(run(generator.Delete("some-app"))).To(Succeed())
+
+			Expect(cc.routes()).To(ContainElements("DELETE /v3/routes/route-guid", "DELETE /v3/apps/app-guid"))
+			Expect(cc.requestsTo("GET /v3/jobs/job-guid")).To(HaveLen(2))
+		})
+
+		It("succeeds when the app does not exist", func() {
+			cc.respond("GET /v3/apps", http.StatusOK, `{"resources": []}`)
+			target()
+
+			Expect(run(generator.Delete("some-app"))).To(Succeed())
+			Expect(outBuf.String()).To(ContainSubstring("App 'some-app' does not exist."))
+		})
+
+		It("deletes the org and quota", func() {
+			login()
+
+			Expect(run(generator.DeleteOrg("some-org"), generator.DeleteQuota("some-quota"), generator.LogOut())).To(Succeed())
+
+			Expect(cc.routes()).To(ContainElements("DELETE /v3/organizations/org-guid", "DELETE /v3/organization_quotas/quota-guid"))
+		})
+
+		It("succeeds when the org does not exist", func() {
+			cc.respond("GET /v3/organizations", http.StatusOK, `{"resources": []}`)
+			login()
+
+			Expect(run(generator.DeleteOrg("some-org"))).To(Succeed())
+		})
+
+		It("fails when the deletion job fails", func() {
+			cc.respond("GET /v3/jobs/job-guid", http.StatusOK, `{"state": "FAILED", "errors": [{"detail": "Org has service instances"}]}`)
+			login()
+
+			Expect(run(generator.DeleteOrg("some-org"))).To(MatchError("Org has service instances"))
+		})
+	})
+
+	Describe("LogOut", func() {
+		It("forgets the token", func() {
+			target()
+
+			Expect(run(generator.LogOut())).To(Succeed())
+			Expect(run(generator.OauthToken())).To(MatchError(ContainSubstring("Not logged in")))
+		})
+	})
+
+	Describe("AppStats", func() {
+		It("prints the instances of each process like cf app", func() {
+			cc.respond("GET /v3/apps/app-guid/processes", http.StatusOK, `{"resources": [{"guid": "web-guid", "type": "web"}]}`)
+			cc.respond("GET /v3/processes/web-guid/stats", http.StatusOK, `{"resources": [
+				{"index": 0, "state": "RUNNING", "usage": {"cpu": 0.003, "mem": 21495808, "disk": 8388608}, "mem_quota": 268435456, "disk_quota": 1073741824, "uptime": 60},
+				{"index": 1, "state": "CRASHED", "usage": {}, "mem_quota": 268435456, "disk_quota": 1073741824}
+			]}`)
+			target()
+
+			Expect(run(generator.AppStats("some-app"))).To(Succeed())
+
+			Expect(outBuf.String()).To(ContainSubstring("type:           web\n"))
+			Expect(outBuf.String()).To(MatchRegexp(`#0   running   \S+   0.3%   20.5M of 256M   8M of 1G\n`))
+			Expect(outBuf.String()).To(MatchRegexp(`#1   crashed   \S+   0.0%   0B of 256M   0B of 1G\n`))
+		})
+
+		It("reports an unavailable stats server", func() {
+			cc.respond("GET /v3/apps/app-guid/processes", http.StatusOK, `{"resources": [{"guid": "web-guid", "type": "web"}]}`)
+			cc.respond("GET /v3/processes/web-guid/stats", http.StatusServiceUnavailable, `{"errors": [{"code": 220002, "title": "CF-StatsUnavailable", "detail": "Stats server temporarily unavailable."}]}`)
+			target()
+
+			Expect(run(generator.AppStats("some-app"))).To(HaveOccurred())
+			Expect(errBuf.String()).To(ContainSubstring("Stats server temporarily unavailable."))
+		})
+	})
+
+	Describe("logs", func() {
+		BeforeEach(func() {
+			cc.respond("GET /api/v1/read/app-guid", http.StatusOK, `{"envelopes": {"batch": [
+				{"timestamp": "2000000000", "instance_id": "1", "tags": {"source_type": "APP/PROC/WEB"}, "log": {"payload": "MTAwMQ==", "type": "OUT"}},
+				{"timestamp": "1000000000", "instance_id": "0", "tags": {"source_type": "APP/PROC/WEB"}, "log": {"payload": "MTAwMA=="}}
+			]}}`)
+		})
+
+		It("prints recent logs oldest first, like cf logs --recent", func() {
+			target()
+
+			Expect(run(generator.RecentLogs("some-app"))).To(Succeed())
+
+			req := cc.requestsTo("GET /api/v1/read/app-guid")[0]
+			Expect(req.Query).To(Equal("descending=true&envelope_types=LOG&limit=1000"))
+			Expect(req.Authorization).To(Equal("bearer some-token"))
+			Expect(outBuf.String()).To(ContainSubstring(
+				"   1970-01-01T00:00:01.00+0000 [APP/PROC/WEB/0] OUT 1000\n" +
+					"   1970-01-01T00:00:02.00+0000 [APP/PROC/WEB/1] OUT 1001\n",
+			))
+		})
+
+		It("streams logs until the context is done", func() {
+			target()
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			Expect(runner.RunWithContext(ctx, generator.StreamLogs(ctx, "some-app"))).To(Succeed())
+
+			Expect(cc.requestsTo("GET /api/v1/read/app-guid")[0].Query).To(ContainSubstring("start_time="))
+			Expect(outBuf.String()).To(ContainSubstring("[APP/PROC/WEB/1] OUT 1001"))
+		})
+	})
+
+	Describe("MapRoute", func() {
+		BeforeEach(func() {
+			cc.respond("GET /v3/domains", http.StatusOK, `{"resources": [{"guid": "domain-guid"}]}`)
+			cc.respond("GET /v3/routes", http.StatusOK, `{"resources": []}`)
+			cc.respond("POST /v3/routes", http.StatusCreated, `{"guid": "route-guid"}`)
+			cc.respond("POST /v3/routes/route-guid/destinations", http.StatusOK, `{}`)
+		})
+
+		It("creates the tcp route and maps the app to it", func() {
+			target()
+
+			Expect(run(generator.MapRoute("some-app", "tcp.example.com", 1025))).To(Succeed())
+
+			route := decodeBody(cc.requestsTo("POST /v3/routes")[0])
+			Expect(route["port"]).To(BeNumerically("==", 1025))
+			Expect(decodeBody(cc.requestsTo("POST /v3/routes/route-guid/destinations")[0])).To(Equal(map[string]interface{}{
+				"destinations": []interface{}{map[string]interface{}{"app": map[string]interface{}{"guid": "app-guid"}}},
+			}))
+		})
+
+		It("reuses an existing route", func() {
+			cc.respond("GET /v3/routes", http.StatusOK, `{"resources": [{"guid": "route-guid"}]}`)
+			target()
+
+			Expect(run(generator.MapRoute("some-app", "tcp.example.com", 1025))).To(Succeed())
+
+			Expect(cc.requestsTo("POST /v3/routes")).To(BeEmpty())
+		})
+	})
+
+	Describe("syslog drain services", func() {
+		It("creates a user provided service and binds it to the app", func() {
+			cc.respond("POST /v3/service_instances", http.StatusCreated, `{"guid": "service-guid"}`)
+			created := false
+			cc.handle("GET /v3/service_instances", func(w http.ResponseWriter, r *http.Request) {
+				if !created {
+					created = true
+					w.Write([]byte(`{"resources": []}`)) //nolint:errcheck
+					return
+				}
+				w.Write([]byte(`{"resources": [{"guid": "service-guid"}]}`)) //nolint:errcheck
+			})
+			cc.respond("GET /v3/service_credential_bindings", http.StatusOK, `{"resources": []}`)
+			cc.respond("POST /v3/service_credential_bindings", http.StatusCreated, `{"guid": "binding-guid"}`)
+			target()
+
+			Expect(run(
+				generator.CreateUserProvidedService("some-service", "syslog://tcp.example.com:1025"),
+				generator.BindService("some-app", "some-service"),
+			)).To(Succeed())
+
+			service := decodeBody(cc.requestsTo("POST /v3/service_instances")[0])
+			Expect(service["type"]).To(Equal("user-provided"))
+			Expect(service["syslog_drain_url"]).To(Equal("syslog://tcp.example.com:1025"))
+			binding := decodeBody(cc.requestsTo("POST /v3/service_credential_bindings")[0])
+			Expect(binding["relationships"]).To(Equal(map[string]interface{}{
+				"service_instance": map[string]interface{}{"data": map[string]interface{}{"guid": "service-guid"}},
+				"app":              map[string]interface{}{"data": map[string]interface{}{"guid": "app-guid"}},
+			}))
+		})
+
+		It("leaves an existing service and binding alone, like the cf CLI", func() {
+			cc.respond("GET /v3/service_instances", http.StatusOK, `{"resources": [{"guid": "service-guid"}]}`)
+			cc.respond("GET /v3/service_credential_bindings", http.StatusOK, `{"resources": [{"guid": "binding-guid"}]}`)
+			target()
+
+			Expect(run(
+				generator.CreateUserProvidedService("some-service", "syslog://tcp.example.com:1025"),
+				generator.BindService("some-app", "some-service"),
+			)).To(Succeed())
+
+			Expect(cc.requestsTo("POST /v3/service_instances")).To(BeEmpty())
+			Expect(cc.requestsTo("POST /v3/service_credential_bindings")).To(BeEmpty())
+			Expect(outBuf.String()).To(ContainSubstring("Service instance some-service already exists"))
+			Expect(outBuf.String()).To(ContainSubstring("App some-app is already bound to service instance some-service."))
+		})
+
+		It("unbinds and deletes the service", func() {
+			cc.respond("GET /v3/service_instances", http.StatusOK, `{"resources": [{"guid": "service-guid"}]}`)
+			cc.respond("GET /v3/service_credential_bindings", http.StatusOK, `{"resources": [{"guid": "binding-guid"}]}`)
+			cc.respond("DELETE /v3/service_credential_bindings/binding-guid", http.StatusNoContent, ``)
+			cc.respond("DELETE /v3/service_instances/service-guid", http.StatusNoContent, ``)
+			target()
+
+			Expect(run(
+				generator.UnbindService("some-app", "some-service"),
+				generator.DeleteService("some-service"),
+			)).To(Succeed())
+
+			Expect(cc.requestsTo("GET /v3/service_credential_bindings")[0].Query).To(Equal("app_guids=app-guid&service_instance_guids=service-guid"))
+			Expect(cc.requestsTo("DELETE /v3/service_credential_bindings/binding-guid")).To(HaveLen(1))
+			Expect(cc.requestsTo("DELETE /v3/service_instances/service-guid")).To(HaveLen(1))
+		})
+
+		It("succeeds when the service does not exist", func() {
+			cc.respond("GET /v3/service_instances", http.StatusOK, `{"resources": []}`)
+			target()
+
+			Expect(run(
+				generator.UnbindService("some-app", "some-service"),
+				generator.DeleteService("some-service"),
+			)).To(Succeed())
+
+			Expect(outBuf.String()).To(ContainSubstring("Service instance some-service did not exist."))
+		})
+	})
+
+	Describe("PurgeServiceInstance", func() {
+		It("purges the service instance by guid", func() {
+			cc.respond("DELETE /v3/service_instances/service-guid", http.StatusNoContent, ``)
+			login()
+
+			Expect(run(generator.PurgeServiceInstance("service-guid"))).To(Succeed())
+
+			Expect(cc.requestsTo("DELETE /v3/service_instances/service-guid")[0].Query).To(Equal("purge=true"))
+		})
+	})
+
+	Describe("Curl", func() {
+		It("prints the response body", func() {
+			login()
+			outBuf.Reset()
+
+			Expect(run(generator.Curl("/v3/organizations?per_page=5000"))).To(Succeed())
+
+			Expect(cc.requestsTo("GET /v3/organizations")[0].Query).To(Equal("per_page=5000"))
+			Expect(outBuf.String()).To(MatchJSON(`{"resources": [{"guid": "org-guid", "name": "some-org"}]}`))
+		})
+
+		It("fails when the request fails", func() {
+			cc.respond("GET /v3/service_instances", http.StatusForbidden, `{"errors": [{"detail": "You are not authorized to perform the requested action", "title": "CF-NotAuthorized"}]}`)
+			login()
+
+			Expect(run(generator.Curl("/v3/service_instances"))).To(MatchError("You are not authorized to perform the requested action (CF-NotAuthorized)"))
+		})
+	})
+
+	Describe("RefreshToken", func() {
+		It("obtains a new access token with the refresh token", func() {
+			login()
+			cc.respond("POST /oauth/token", http.StatusOK, `{"access_token": "new-token"}`)
+			outBuf.Reset()
+
+			Expect(run(generator.RefreshToken())).To(Succeed())
+
+			Expect(outBuf.String()).To(Equal("bearer new-token\n"))
+			Expect(run(generator.CreateOrg("new-org"))).To(HaveOccurred())
+			Expect(cc.requestsTo("POST /v3/organizations")[0].Authorization).To(Equal("bearer new-token"))
+		})
+
+		It("fails when the refresh token is rejected", func() {
+			login()
+			cc.respond("POST /oauth/token", http.StatusUnauthorized, `{"error": "invalid_token", "error_description": "Invalid refresh token"}`)
+
+			Expect(run(generator.RefreshToken())).To(MatchError("Failed to refresh token: Invalid refresh token"))
+		})
+
+		It("requires a login", func() {
+			Expect(run(generator.RefreshToken())).To(MatchError(ContainSubstring("Not logged in")))
+		})
+	})
+
+	Describe("AccessToken", func() {
+		It("returns the session's access token", func() {
+			login()
+
+			Expect(generator.AccessToken()).To(Equal("some-token"))
+		})
+
+		It("requires a login", func() {
+			_, err := generator.AccessToken()
+
+			Expect(err).To(MatchError(ContainSubstring("Not logged in")))
+		})
+	})
+
+	Describe("AppGuid and OauthToken", func() {
+		It("prints the app guid and the token like the cf CLI", func() {
+			target()
+			outBuf.Reset()
+
+			Expect(run(generator.AppGuid("some-app"), generator.OauthToken())).To(Succeed())
+
+			Expect(outBuf.String()).To(Equal("app-guid\nbearer some-token\n"))
+		})
+	})
+})
@@ -0,0 +1,153 @@
+package cfApi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+type recordedRequest struct {
+	Method        string
+	Path          string
+	Query         string
+	Authorization string
+	ContentType   string
+	Body          []byte
+}
+
+// fakeCC serves canned responses for the Cloud Controller, UAA and Log Cache
+// APIs from one server, and records the requests it receives.
+type fakeCC struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	responses map[string]func(w http.ResponseWriter, r *http.Request)
+	requests  []recordedRequest
+}
+
+func newFakeCC() *fakeCC {
+	f := &fakeCC{responses: map[string]func(w http.ResponseWriter, r *http.Request){}}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+
+	f.respond("GET /", http.StatusOK, fmt.Sprintf(`{"links": {
+		"uaa": {"href": "%[1]s"},
+		"log_cache": {"href": "%[1]s"}
+	}}`, f.server.URL))
+	f.respond("POST /oauth/token", http.StatusOK, `{"access_token": "some-token", "refresh_token": "some-refresh-token", "token_type": "bearer"}`)
+
+	return f
+}
+
+func (f *fakeCC) URL() string {
+	return f.server.URL
+}
+
+func (f *fakeCC) Close() {
+	f.server.Close()
+}
+
+// respond serves body with status for requests to route, a method and path
+// such as "GET /v3/apps".
+func (f *fakeCC) respond(route string, status int, body string) {
+	f.handle(route, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body)) //nolint:errcheck
+	})
+}
+
+// respondWithResources lists resources, given by name and guid, for
+// requests to route. Requests with a names query get only those named.
+func (f *fakeCC) respondWithResources(route string, guidsByName map[string]string) {
+	f.handle(route, func(w http.ResponseWriter, r *http.Request) {
+		names := map[string]bool{}
+		for _, name := range strings.Split(r.URL.Query().Get("names"), ",") {
+			names[name] = true
+		}
+
+		resources := []map[string]string{}
+		for name, guid := range guidsByName {
+			if r.URL.Query().Has("names") && !names[name] {
+				continue
+			}
+			resources = append(resources, map[string]string{"guid": guid, "name": name})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"resources": resources}) //nolint:errcheck
+	})
+}
+
+// respondWithJob answers requests to route as accepted, naming the job at
+// jobPath in the Location header.
+func (f *fakeCC) respondWithJob(route, jobPath string) {
+	f.handle(route, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", f.server.URL+jobPath)
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+func (f *fakeCC) handle(route string, handler func(w http.ResponseWriter, r *http.Request)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[route] = handler
+}
+
+func (f *fakeCC) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	route := r.Method + " " + r.URL.Path
+	f.mu.Lock()
+	f.requests = append(f.requests, recordedRequest{
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		Query:         r.URL.RawQuery,
+		Authorization: r.Header.Get("Authorization"),
+		ContentType:   r.Header.Get("Content-Type"),
+		Body:          body,
+	})
+	handler, ok := f.responses[route]
+	f.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"errors": [{"code": 10000, "title": "CF-NotFound", "detail": "Unknown request %s"}]}`, route) //nolint:errcheck
+		return
+	}
+
+	handler(w, r)
+}
+
+// routes returns the method and path of every request received so far.
+func (f *fakeCC) routes() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var routes []string
+	for _, r := range f.requests {
+		routes = append(routes, r.Method+" "+r.Path)
+	}
+
+	return routes
+}
+
+// requestsTo returns the requests received for route.
+func (f *fakeCC) requestsTo(route string) []recordedRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var requests []recordedRequest
+	for _, r := range f.requests {
+		if r.Method+" "+r.Path == route {
+			requests = append(requests, r)
+		}
+	}
+
+	return requests
+}
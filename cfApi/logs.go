@@ -0,0 +1,214 @@
+package cfApi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type processStats struct {
+	Resources []struct {
+		Index int    `json:"index"`
+		State string `json:"state"`
+		Usage struct {
+			Cpu  float64 `json:"cpu"`
+			Mem  uint64  `json:"mem"`
+			Disk uint64  `json:"disk"`
+		} `json:"usage"`
+		MemQuota  uint64 `json:"mem_quota"`
+		DiskQuota uint64 `json:"disk_quota"`
+		Uptime    int64  `json:"uptime"`
+	} `json:"resources"`
+}
+
+type logCacheEnvelopes struct {
+	Envelopes struct {
+		Batch []struct {
+			Timestamp  string            `json:"timestamp"`
+			InstanceId string            `json:"instance_id"`
+			Tags       map[string]string `json:"tags"`
+			Log        *struct {
+				Payload []byte `json:"payload"`
+				Type    string `json:"type"`
+			} `json:"log"`
+		} `json:"batch"`
+	} `json:"envelopes"`
+}
+
+// appStats prints the instances of each of the app's processes in the
+// layout of `cf app`.
+func (c *cfApi) appStats(out io.Writer, appName string) error {
+	appGuid, err := c.session.appGuid(appName)
+	if err != nil {
+		return err
+	}
+
+	processes := &struct {
+		Resources []struct {
+			Guid string `json:"guid"`
+			Type string `json:"type"`
+		} `json:"resources"`
+	}{}
+	if _, err := c.session.cc(http.MethodGet, "/v3/apps/"+appGuid+"/processes", nil, processes); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Showing health and status for app %s...\n", appName) //nolint:errcheck
+	for _, process := range processes.Resources {
+		stats := &processStats{}
+		if _, err := c.session.cc(http.MethodGet, "/v3/processes/"+process.Guid+"/stats", nil, stats); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "\ntype:           %s\n", process.Type)                              //nolint:errcheck
+		fmt.Fprintln(out, "     state     since                  cpu    memory         disk") //nolint:errcheck
+		for _, instance := range stats.Resources {
+			since := time.Now().Add(-time.Duration(instance.Uptime) * time.Second).UTC()
+			fmt.Fprintf( //nolint:errcheck
+				out,
+				"#%d   %s   %s   %.1f%%   %s of %s   %s of %s\n",
+				instance.Index,
+				strings.ToLower(instance.State),
+				since.Format(time.RFC3339),
+				instance.Usage.Cpu*100,
+				formatBytes(instance.Usage.Mem),
+				formatBytes(instance.MemQuota),
+				formatBytes(instance.Usage.Disk),
+				formatBytes(instance.DiskQuota),
+			)
+		}
+	}
+
+	return nil
+}
+
+// formatBytes formats a byte count the way the cf CLI does, e.g. "20.5M".
+func formatBytes(bytes uint64) string {
+	units := []struct {
+		suffix string
+		size   uint64
+	}{
+		{"T", 1 << 40},
+		{"G", 1 << 30},
+		{"M", 1 << 20},
+		{"K", 1 << 10},
+	}
+	for _, unit := range units {
+		if bytes >= unit.size {
+			value := strconv.FormatFloat(float64(bytes)/float64(unit.size), 'f', 1, 64)
+			return strings.TrimSuffix(value, ".0") + unit.suffix
+		}
+	}
+
+	return fmt.Sprintf("%dB", bytes)
+}
+
+func (c *cfApi) recentLogs(out io.Writer, appName string) error {
+	appGuid, err := c.session.appGuid(appName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Retrieving logs for app %s...\n\n", appName) //nolint:errcheck
+	lines, _, err := c.readLogs(appGuid, url.Values{
+		"descending": {"true"},
+		"limit":      {"1000"},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		fmt.Fprintln(out, line) //nolint:errcheck
+	}
+
+	return nil
+}
+
+// streamLogs prints the app's new logs as they arrive, until ctx is done.
+func (c *cfApi) streamLogs(ctx context.Context, out io.Writer, appName string) error {
+	appGuid, err := c.session.appGuid(appName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Retrieving logs for app %s...\n\n", appName) //nolint:errcheck
+	startTime := time.Now().UnixNano()
+	for {
+		lines, lastTimestamp, err := c.readLogs(appGuid, url.Values{
+			"start_time": {strconv.FormatInt(startTime, 10)},
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, line := range lines {
+			fmt.Fprintln(out, line) //nolint:errcheck
+		}
+		if lastTimestamp >= startTime {
+			startTime = lastTimestamp + 1
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// readLogs reads the app's logs from Log Cache and formats them, oldest
+// first, as `cf logs` prints them. It also returns the newest timestamp.
+func (c *cfApi) readLogs(appGuid string, query url.Values) ([]string, int64, error) {
+	if c.session.logCacheUrl == "" {
+		return nil, 0, errors.New("API does not link to Log Cache")
+	}
+	query.Set("envelope_types", "LOG")
+
+	envelopes := &logCacheEnvelopes{}
+	_, err := c.session.do(http.MethodGet, c.session.logCacheUrl+"/api/v1/read/"+appGuid+"?"+query.Encode(), "", nil, envelopes)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	batch := envelopes.Envelopes.Batch
+	timestamps := make([]int64, len(batch))
+	order := make([]int, len(batch))
+	for i, e := range batch {
+		timestamps[i], _ = strconv.ParseInt(e.Timestamp, 10, 64)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return timestamps[order[i]] < timestamps[order[j]] })
+
+	var lines []string
+	var lastTimestamp int64
+	for _, i := range order {
+		e := batch[i]
+		lastTimestamp = timestamps[i]
+		if e.Log == nil {
+			continue
+		}
+
+		logType := e.Log.Type
+		if logType == "" {
+			logType = "OUT"
+		}
+		lines = append(lines, fmt.Sprintf(
+			"   %s [%s/%s] %s %s",
+			time.Unix(0, timestamps[i]).UTC().Format("2006-01-02T15:04:05.00-0700"),
+			e.Tags["source_type"],
+			e.InstanceId,
+			logType,
+			strings.TrimSpace(string(e.Log.Payload)),
+		))
+	}
+
+	return lines, lastTimestamp, nil
+}
@@ -0,0 +1,74 @@
+package cfApi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+// operation is an in-process stand-in for a cf CLI command. It satisfies
+// cmdStartWaiter.CmdStartWaiter, so that runners treat it like an exec.Cmd:
+// Start performs the operation, the pipes return what it printed, and Wait
+// returns its error. Unlike an exec.Cmd, it cannot be killed: runners leave
+// it to finish however it is timed out or cancelled, which its HTTP and
+// polling timeouts bound.
+type operation struct {
+	description string
+	session     *session
+	run         func(out, errOut io.Writer) error
+
+	stdout  bytes.Buffer
+	stderr  bytes.Buffer
+	started bool
+	err     error
+}
+
+func (o *operation) String() string {
+	return o.description
+}
+
+// Renew returns a copy of the operation that has not been started, so that
+// it can be retried.
+func (o *operation) Renew() cmdStartWaiter.CmdStartWaiter {
+	return &operation{
+		description: o.description,
+		session:     o.session,
+		run:         o.run,
+	}
+}
+
+func (o *operation) Start() error {
+	if o.started {
+		return errors.New("cfApi: already started")
+	}
+	o.started = true
+
+	o.session.mu.Lock()
+	defer o.session.mu.Unlock()
+
+	o.err = o.run(&o.stdout, &o.stderr)
+	if o.err != nil {
+		o.stderr.WriteString("FAILED\n")
+		o.stderr.WriteString(o.err.Error() + "\n")
+	}
+
+	return nil
+}
+
+func (o *operation) Wait() error {
+	if !o.started {
+		return errors.New("cfApi: not started")
+	}
+
+	return o.err
+}
+
+func (o *operation) StdoutPipe() (io.ReadCloser, error) {
+	return io.NopCloser(&o.stdout), nil
+}
+
+func (o *operation) StderrPipe() (io.ReadCloser, error) {
+	return io.NopCloser(&o.stderr), nil
+}
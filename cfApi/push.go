@@ -0,0 +1,389 @@
+package cfApi
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+type pushOptions struct {
+	name      string
+	path      string
+	instances int
+	noRoute   bool
+	rolling   bool
+
+	buildpack string
+	stack     string
+
+	dockerImage    string
+	dockerUsername string
+	dockerPassword string
+}
+
+// push does what `cf push` does: it applies the app's manifest, uploads its
+// bits (or registers its docker image), stages them and starts the app,
+// waiting for an instance to run.
+func (c *cfApi) push(out io.Writer, opts pushOptions) error {
+	spaceGuid, err := c.session.targetedSpace()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Pushing app %s...\n", opts.name) //nolint:errcheck
+
+	manifest, err := pushManifest(opts)
+	if err != nil {
+		return err
+	}
+	jobUrl, err := c.session.do(
+		http.MethodPost,
+		c.session.api+"/v3/spaces/"+spaceGuid+"/actions/apply_manifest",
+		"application/x-yaml",
+		manifest,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+	if err := c.session.awaitJob(jobUrl); err != nil {
+		return err
+	}
+
+	appGuid, err := c.session.appGuid(opts.name)
+	if err != nil {
+		return err
+	}
+
+	var packageGuid string
+	if opts.dockerImage != "" {
+		packageGuid, err = c.createDockerPackage(appGuid, opts)
+	} else {
+		packageGuid, err = c.uploadBitsPackage(out, appGuid, opts.path)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.stageAndStart(out, appGuid, packageGuid, opts.rolling)
+}
+
+// pushManifest returns the app's manifest with the push options applied, the
+// way command line flags override a manifest.
+func pushManifest(opts pushOptions) ([]byte, error) {
+	app := map[string]interface{}{}
+	if opts.path != "" {
+		manifest := &struct {
+			Applications []map[string]interface{} `yaml:"applications"`
+		}{}
+		contents, err := os.ReadFile(filepath.Join(opts.path, "manifest.yml"))
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(contents, manifest); err != nil {
+			return nil, fmt.Errorf("Failed to read manifest: %s", err)
+		}
+		if len(manifest.Applications) > 0 {
+			app = manifest.Applications[0]
+		}
+	}
+
+	app["name"] = opts.name
+	app["instances"] = opts.instances
+	if opts.buildpack != "" {
+		app["buildpacks"] = []string{opts.buildpack}
+	}
+	if opts.stack != "" {
+		app["stack"] = opts.stack
+	}
+	if opts.noRoute {
+		app["no-route"] = true
+	} else {
+		app["default-route"] = true
+	}
+	if opts.dockerImage != "" {
+		docker := map[string]string{"image": opts.dockerImage}
+		if opts.dockerUsername != "" {
+			docker["username"] = opts.dockerUsername
+		}
+		app["docker"] = docker
+	}
+
+	return yaml.Marshal(map[string]interface{}{
+		"applications": []map[string]interface{}{app},
+	})
+}
+
+func (c *cfApi) createDockerPackage(appGuid string, opts pushOptions) (string, error) {
+	data := map[string]string{"image": opts.dockerImage}
+	if opts.dockerUsername != "" {
+		data["username"] = opts.dockerUsername
+		data["password"] = opts.dockerPassword
+	}
+
+	pkg := &resource{}
+	_, err := c.session.cc(http.MethodPost, "/v3/packages", map[string]interface{}{
+		"type": "docker",
+		"data": data,
+		"relationships": map[string]interface{}{
+			"app": relationship(appGuid),
+		},
+	}, pkg)
+
+	return pkg.Guid, err
+}
+
+func (c *cfApi) uploadBitsPackage(out io.Writer, appGuid, path string) (string, error) {
+	pkg := &resource{}
+	_, err := c.session.cc(http.MethodPost, "/v3/packages", map[string]interface{}{
+		"type": "bits",
+		"relationships": map[string]interface{}{
+			"app": relationship(appGuid),
+		},
+	}, pkg)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintln(out, "Uploading files...") //nolint:errcheck
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	if err := form.WriteField("resources", "[]"); err != nil {
+		return "", err
+	}
+	bits, err := form.CreateFormFile("bits", "application.zip")
+	if err != nil {
+		return "", err
+	}
+	if err := zipDir(bits, path); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	_, err = c.session.do(http.MethodPost, c.session.api+"/v3/packages/"+pkg.Guid+"/upload", form.FormDataContentType(), body.Bytes(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	return pkg.Guid, poll("package upload", func() (bool, error) {
+		state := &struct {
+			State string `json:"state"`
+		}{}
+		if _, err := c.session.cc(http.MethodGet, "/v3/packages/"+pkg.Guid, nil, state); err != nil {
+			return false, err
+		}
+
+		switch state.State {
+		case "READY":
+			return true, nil
+		case "FAILED", "EXPIRED":
+			return false, fmt.Errorf("Package upload %s", state.State)
+		}
+
+		return false, nil
+	})
+}
+
+// zipDir writes the files below dir as a zip archive, keeping their modes so
+// that executables stay executable.
+func zipDir(w io.Writer, dir string) error {
+	archive := zip.NewWriter(w)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+			_, err = archive.CreateHeader(header)
+			return err
+		}
+		header.Method = zip.Deflate
+
+		entry, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck
+
+		_, err = io.Copy(entry, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// stageAndStart stages the package and runs the resulting droplet, either by
+// restarting the app or by a rolling deployment.
+func (c *cfApi) stageAndStart(out io.Writer, appGuid, packageGuid string, rolling bool) error {
+	fmt.Fprintln(out, "Staging app...") //nolint:errcheck
+	build := &resource{}
+	_, err := c.session.cc(http.MethodPost, "/v3/builds", map[string]interface{}{
+		"package": map[string]string{"guid": packageGuid},
+	}, build)
+	if err != nil {
+		return err
+	}
+
+	var dropletGuid string
+	err = poll("staging", func() (bool, error) {
+		state := &struct {
+			State   string `json:"state"`
+			Error   string `json:"error"`
+			Droplet *struct {
+				Guid string `json:"guid"`
+			} `json:"droplet"`
+		}{}
+		if _, err := c.session.cc(http.MethodGet, "/v3/builds/"+build.Guid, nil, state); err != nil {
+			return false, err
+		}
+
+		switch state.State {
+		case "STAGED":
+			if state.Droplet == nil {
+				return false, errors.New("Staging did not produce a droplet")
+			}
+			dropletGuid = state.Droplet.Guid
+			return true, nil
+		case "FAILED":
+			return false, fmt.Errorf("Staging failed: %s", state.Error)
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if rolling {
+		fmt.Fprintln(out, "Starting rolling deployment...") //nolint:errcheck
+		if err := c.deploy(appGuid, dropletGuid); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintln(out, "Starting app...") //nolint:errcheck
+		_, err = c.session.cc(http.MethodPatch, "/v3/apps/"+appGuid+"/relationships/current_droplet", relationship(dropletGuid), nil)
+		if err != nil {
+			return err
+		}
+		if _, err := c.session.cc(http.MethodPost, "/v3/apps/"+appGuid+"/actions/restart", nil, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := c.awaitRunningInstance(appGuid); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "OK") //nolint:errcheck
+
+	return nil
+}
+
+func (c *cfApi) deploy(appGuid, dropletGuid string) error {
+	deployment := &resource{}
+	_, err := c.session.cc(http.MethodPost, "/v3/deployments", map[string]interface{}{
+		"strategy": "rolling",
+		"droplet":  map[string]string{"guid": dropletGuid},
+		"relationships": map[string]interface{}{
+			"app": relationship(appGuid),
+		},
+	}, deployment)
+	if err != nil {
+		return err
+	}
+
+	return poll("rolling deployment", func() (bool, error) {
+		state := &struct {
+			Status struct {
+				Value  string `json:"value"`
+				Reason string `json:"reason"`
+			} `json:"status"`
+		}{}
+		if _, err := c.session.cc(http.MethodGet, "/v3/deployments/"+deployment.Guid, nil, state); err != nil {
+			return false, err
+		}
+
+		if state.Status.Value != "FINALIZED" {
+			return false, nil
+		}
+		if state.Status.Reason != "DEPLOYED" {
+			return false, fmt.Errorf("Rolling deployment %s", state.Status.Reason)
+		}
+
+		return true, nil
+	})
+}
+
+func (c *cfApi) awaitRunningInstance(appGuid string) error {
+	return poll("app to start", func() (bool, error) {
+		stats := &processStats{}
+		if _, err := c.session.cc(http.MethodGet, "/v3/apps/"+appGuid+"/processes/web/stats", nil, stats); err != nil {
+			return false, err
+		}
+
+		crashed := 0
+		for _, instance := range stats.Resources {
+			switch instance.State {
+			case "RUNNING":
+				return true, nil
+			case "CRASHED":
+				crashed++
+			}
+		}
+		if len(stats.Resources) > 0 && crashed == len(stats.Resources) {
+			return false, errors.New("Start unsuccessful: all instances crashed")
+		}
+
+		return false, nil
+	})
+}
+
+// restage stages the app's newest package again and restarts it with the new
+// droplet, as `cf restage` does.
+func (c *cfApi) restage(out io.Writer, appName string) error {
+	fmt.Fprintf(out, "Restaging app %s...\n", appName) //nolint:errcheck
+
+	appGuid, err := c.session.appGuid(appName)
+	if err != nil {
+		return err
+	}
+
+	packageGuid, err := c.session.mustFind("Package of app", appName, "/v3/apps/"+appGuid+"/packages", url.Values{
+		"states":   {"READY"},
+		"order_by": {"-created_at"},
+		"per_page": {"1"},
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.stageAndStart(out, appGuid, packageGuid, false)
+}
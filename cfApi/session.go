@@ -0,0 +1,328 @@
+package cfApi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+)
+
+// errAuthExpired reports a rejected token the way the cf CLI does, so that
+// both backends' expired sessions are recognised alike.
+var errAuthExpired = errors.New(cfCmdGenerator.AuthExpiredMessage)
+
+var (
+	pollInterval = time.Second
+	pollTimeout  = 5 * time.Minute
+)
+
+// session holds what the cf CLI keeps in its CF_HOME.
+type session struct {
+	mu     sync.Mutex
+	client *http.Client
+
+	api         string
+	uaaUrl      string
+	logCacheUrl string
+
+	accessToken  string
+	refreshToken string
+
+	// clientId and clientSecret are set when the session authenticated with
+	// client credentials, for which UAA issues no refresh token.
+	clientId     string
+	clientSecret string
+
+	orgGuid   string
+	spaceGuid string
+}
+
+type resource struct {
+	Guid string `json:"guid"`
+	Name string `json:"name"`
+}
+
+type resourceList struct {
+	Resources []resource `json:"resources"`
+}
+
+type apiErrors struct {
+	Errors []struct {
+		Code   int    `json:"code"`
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	} `json:"errors"`
+	Description string `json:"error_description"`
+}
+
+// relationship builds the `{"data": {"guid": ...}}` document the v3 API uses
+// for to-one relationships.
+func relationship(guid string) map[string]interface{} {
+	return map[string]interface{}{"data": map[string]string{"guid": guid}}
+}
+
+// cc sends a request to the Cloud Controller and decodes its response into
+// result, if given. It returns the Location header, which names the job of
+// asynchronous requests.
+func (s *session) cc(method, path string, body, result interface{}) (string, error) {
+	if s.api == "" {
+		return "", errors.New("No API endpoint set. Use 'cf api' to set an endpoint")
+	}
+
+	var encoded []byte
+	if body != nil {
+		var err error
+		encoded, err = json.Marshal(body)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return s.do(method, s.api+path, "application/json", encoded, result)
+}
+
+// do sends an authenticated request. Like the cf CLI, it refreshes a
+// rejected access token once and repeats the request.
+func (s *session) do(method, url, contentType string, body []byte, result interface{}) (string, error) {
+	if s.accessToken == "" {
+		return "", errors.New("Not logged in. Use 'cf login' or 'cf auth' to log in.")
+	}
+
+	location, err := s.sendWithToken(method, url, contentType, body, result)
+	if err == errAuthExpired && s.canRefresh() {
+		if s.refresh() != nil {
+			return "", errAuthExpired
+		}
+		location, err = s.sendWithToken(method, url, contentType, body, result)
+	}
+
+	return location, err
+}
+
+func (s *session) sendWithToken(method, url, contentType string, body []byte, result interface{}) (string, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "bearer "+s.accessToken)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return s.send(req, result)
+}
+
+func (s *session) canRefresh() bool {
+	return s.refreshToken != "" || s.clientId != ""
+}
+
+// refresh obtains a new access token with the refresh token, or with the
+// client credentials the session authenticated with.
+func (s *session) refresh() error {
+	if s.clientId != "" {
+		return s.requestToken(url.Values{"grant_type": {"client_credentials"}})
+	}
+
+	return s.requestToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {s.refreshToken},
+	})
+}
+
+// requestToken obtains tokens from UAA for the session's client, or for the
+// cf client when it has none.
+func (s *session) requestToken(form url.Values) error {
+	if s.uaaUrl == "" {
+		return errors.New("No API endpoint set. Use 'cf api' to set an endpoint")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.uaaUrl+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	if s.clientId != "" {
+		req.SetBasicAuth(url.QueryEscape(s.clientId), url.QueryEscape(s.clientSecret))
+	} else {
+		req.SetBasicAuth("cf", "")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	token := &struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}{}
+	if _, err := s.send(req, token); err != nil {
+		return err
+	}
+
+	s.accessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		s.refreshToken = token.RefreshToken
+	}
+
+	return nil
+}
+
+func (s *session) send(req *http.Request, result interface{}) (string, error) {
+	res, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if res.StatusCode == http.StatusUnauthorized && strings.HasPrefix(req.Header.Get("Authorization"), "bearer ") {
+		return "", errAuthExpired
+	}
+
+	if res.StatusCode >= 300 {
+		return "", responseError(req, res, body)
+	}
+
+	if result != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, result); err != nil {
+			return "", fmt.Errorf("Failed to decode response from %s %s: %s", req.Method, req.URL.Path, err)
+		}
+	}
+
+	return res.Header.Get("Location"), nil
+}
+
+func responseError(req *http.Request, res *http.Response, body []byte) error {
+	apiErrs := &apiErrors{}
+	if json.Unmarshal(body, apiErrs) == nil {
+		var details []string
+		for _, e := range apiErrs.Errors {
+			details = append(details, fmt.Sprintf("%s (%s)", e.Detail, e.Title))
+		}
+		if apiErrs.Description != "" {
+			details = append(details, apiErrs.Description)
+		}
+		if len(details) > 0 {
+			return errors.New(strings.Join(details, "; "))
+		}
+	}
+
+	return fmt.Errorf("%s %s responded with status %d: %s", req.Method, req.URL.Path, res.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// find returns the guid of the first resource listed at path matching the
+// query, or an empty string if there is none.
+func (s *session) find(path string, query url.Values) (string, error) {
+	list := &resourceList{}
+	if _, err := s.cc(http.MethodGet, path+"?"+query.Encode(), nil, list); err != nil {
+		return "", err
+	}
+
+	if len(list.Resources) == 0 {
+		return "", nil
+	}
+
+	return list.Resources[0].Guid, nil
+}
+
+// mustFind is find for resources that have to exist, named the way the cf
+// CLI reports them missing.
+func (s *session) mustFind(kind, name, path string, query url.Values) (string, error) {
+	guid, err := s.find(path, query)
+	if err != nil {
+		return "", err
+	}
+
+	if guid == "" {
+		return "", fmt.Errorf("%s '%s' not found.", kind, name)
+	}
+
+	return guid, nil
+}
+
+func (s *session) targetedSpace() (string, error) {
+	if s.spaceGuid == "" {
+		return "", errors.New("No org and space targeted, use 'cf target -o ORG -s SPACE' to target an org and space")
+	}
+
+	return s.spaceGuid, nil
+}
+
+func (s *session) appGuid(name string) (string, error) {
+	spaceGuid, err := s.targetedSpace()
+	if err != nil {
+		return "", err
+	}
+
+	return s.mustFind("App", name, "/v3/apps", url.Values{"names": {name}, "space_guids": {spaceGuid}})
+}
+
+// awaitJob polls the job at jobUrl, if any, until it has finished.
+func (s *session) awaitJob(jobUrl string) error {
+	if jobUrl == "" {
+		return nil
+	}
+
+	return poll("job", func() (bool, error) {
+		job := &struct {
+			State  string `json:"state"`
+			Errors []struct {
+				Detail string `json:"detail"`
+			} `json:"errors"`
+		}{}
+		if _, err := s.do(http.MethodGet, jobUrl, "", nil, job); err != nil {
+			return false, err
+		}
+
+		switch job.State {
+		case "COMPLETE":
+			return true, nil
+		case "FAILED":
+			if len(job.Errors) > 0 {
+				return false, errors.New(job.Errors[0].Detail)
+			}
+			return false, errors.New("Job failed")
+		}
+
+		return false, nil
+	})
+}
+
+// poll calls check until it reports done or fails, for at most pollTimeout.
+func poll(description string, check func() (bool, error)) error {
+	deadline := time.Now().Add(pollTimeout)
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Timed out after %s waiting for %s", pollTimeout, description)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// decodeRoot decodes the unauthenticated API root document.
+func decodeRoot(res *http.Response, root interface{}) error {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, root)
+}
@@ -2,18 +2,28 @@ package cfCmdGenerator
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
 )
 
+// AuthExpiredMessage is what the cf CLI prints when a session can no longer
+// be authenticated with its tokens.
+const AuthExpiredMessage = "Authentication has expired.  Please log back in to re-authenticate."
+
 //go:generate counterfeiter . CfCmdGenerator
 type CfCmdGenerator interface {
 	Api(url string) cmdStartWaiter.CmdStartWaiter
 	Auth(username, password string) cmdStartWaiter.CmdStartWaiter
+	AuthClientCredentials(clientID, clientSecret string) cmdStartWaiter.CmdStartWaiter
 	CreateQuota(quota string) cmdStartWaiter.CmdStartWaiter
 	SetQuota(org, quota string) cmdStartWaiter.CmdStartWaiter
 	CreateOrg(org string) cmdStartWaiter.CmdStartWaiter
@@ -21,7 +31,12 @@ type CfCmdGenerator interface {
 	EnableOrgIsolation(org, isolationSegment string) cmdStartWaiter.CmdStartWaiter
 	SetOrgDefaultIsolationSegment(org, isolationSegment string) cmdStartWaiter.CmdStartWaiter
 	Target(org, space string) cmdStartWaiter.CmdStartWaiter
+	SetLabels(resourceType, name string, labels map[string]string) cmdStartWaiter.CmdStartWaiter
+	SetAnnotations(resourceType, name string, annotations map[string]string) cmdStartWaiter.CmdStartWaiter
 	Push(name, path string, instances int, noRoute bool) cmdStartWaiter.CmdStartWaiter
+	RollingPush(name, path string, instances int) cmdStartWaiter.CmdStartWaiter
+	PushWithBuildpack(name, path, buildpack, stack string, instances int) cmdStartWaiter.CmdStartWaiter
+	PushDockerImage(name, image, username, password string, instances int) cmdStartWaiter.CmdStartWaiter
 	Delete(name string) cmdStartWaiter.CmdStartWaiter
 	DeleteOrg(org string) cmdStartWaiter.CmdStartWaiter
 	DeleteQuota(quota string) cmdStartWaiter.CmdStartWaiter
@@ -32,7 +47,15 @@ type CfCmdGenerator interface {
 	MapRoute(appName, domain string, port int) cmdStartWaiter.CmdStartWaiter
 	CreateUserProvidedService(serviceName, syslogURL string) cmdStartWaiter.CmdStartWaiter
 	BindService(appName, serviceName string) cmdStartWaiter.CmdStartWaiter
+	UnbindService(appName, serviceName string) cmdStartWaiter.CmdStartWaiter
+	DeleteService(serviceName string) cmdStartWaiter.CmdStartWaiter
+	PurgeServiceInstance(serviceGuid string) cmdStartWaiter.CmdStartWaiter
 	Restage(appName string) cmdStartWaiter.CmdStartWaiter
+	AppGuid(appName string) cmdStartWaiter.CmdStartWaiter
+	OauthToken() cmdStartWaiter.CmdStartWaiter
+	RefreshToken() cmdStartWaiter.CmdStartWaiter
+	AccessToken() (string, error)
+	Curl(path string) cmdStartWaiter.CmdStartWaiter
 }
 
 type cfCmdGenerator struct {
@@ -64,14 +87,36 @@ func (c *cfCmdGenerator) Api(url string) cmdStartWaiter.CmdStartWaiter {
 	)
 }
 
+// Auth passes the credentials in the environment rather than as arguments,
+// which would show in process listings.
 func (c *cfCmdGenerator) Auth(username string, password string) cmdStartWaiter.CmdStartWaiter {
-	return c.setCfHome(
-		exec.Command(
-			"cf", "auth", username, password,
+	return c.setCredentials(
+		c.setCfHome(
+			exec.Command(
+				"cf", "auth",
+			),
+		),
+		username, password,
+	)
+}
+
+func (c *cfCmdGenerator) AuthClientCredentials(clientID, clientSecret string) cmdStartWaiter.CmdStartWaiter {
+	return c.setCredentials(
+		c.setCfHome(
+			exec.Command(
+				"cf", "auth",
+				"--client-credentials",
+			),
 		),
+		clientID, clientSecret,
 	)
 }
 
+func (c *cfCmdGenerator) setCredentials(cmd *exec.Cmd, username, password string) *exec.Cmd {
+	cmd.Env = append(cmd.Env, fmt.Sprintf("CF_USERNAME=%s", username), fmt.Sprintf("CF_PASSWORD=%s", password))
+	return cmd
+}
+
 func (c *cfCmdGenerator) CreateQuota(quota string) cmdStartWaiter.CmdStartWaiter {
 	return c.setCfHome(
 		exec.Command(
@@ -134,6 +179,57 @@ func (c *cfCmdGenerator) Target(org string, space string) cmdStartWaiter.CmdStar
 		),
 	)
 }
+
+// SetLabels labels the named org, space, app or service-instance; spaces,
+// apps and service instances are looked up in the targeted org and space.
+func (c *cfCmdGenerator) SetLabels(resourceType, name string, labels map[string]string) cmdStartWaiter.CmdStartWaiter {
+	args := []string{"set-label", resourceType, name}
+	for _, key := range sortedKeys(labels) {
+		args = append(args, fmt.Sprintf("%s=%s", key, labels[key]))
+	}
+
+	return c.setCfHome(exec.Command("cf", args...))
+}
+
+// annotatedResources are, for each type of resource cf set-label takes, the
+// cf command that prints the guid of a resource of that type, and the path of
+// the Cloud Controller under which it is found.
+var annotatedResources = map[string]struct{ command, path string }{
+	"org":              {"org", "organizations"},
+	"space":            {"space", "spaces"},
+	"app":              {"app", "apps"},
+	"service-instance": {"service", "service_instances"},
+}
+
+// SetAnnotations finds resources the way SetLabels does. The cf CLI has no
+// command for annotations, so the resource's guid is looked up and its
+// metadata patched with cf curl, in a shell.
+func (c *cfCmdGenerator) SetAnnotations(resourceType, name string, annotations map[string]string) cmdStartWaiter.CmdStartWaiter {
+	resource, ok := annotatedResources[resourceType]
+	if !ok {
+		resource.command = resourceType
+	}
+	body, _ := json.Marshal(map[string]interface{}{ //nolint:errcheck
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+
+	return c.setCfHome(
+		exec.Command(
+			"sh", "-c", `guid=$(cf "$1" "$2" --guid) && cf curl --fail -X PATCH "/v3/$3/$guid" -d "$4"`,
+			"set-annotation", resource.command, name, resource.path, string(body),
+		),
+	)
+}
+
+func sortedKeys(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func (c *cfCmdGenerator) Push(name, path string, instances int, noRoute bool) cmdStartWaiter.CmdStartWaiter {
 	args := []string{
 		"push", name,
@@ -157,6 +253,72 @@ func (c *cfCmdGenerator) Push(name, path string, instances int, noRoute bool) cm
 	)
 }
 
+func (c *cfCmdGenerator) RollingPush(name, path string, instances int) cmdStartWaiter.CmdStartWaiter {
+	args := []string{
+		"push", name,
+		"-f", "manifest.yml",
+		"-i", strconv.Itoa(instances),
+		"--strategy", "rolling",
+	}
+
+	if !c.useBuildpackDetection {
+		args = append(args, "-b", "go_buildpack")
+	}
+
+	cmd := exec.Command("cf", args...)
+	cmd.Dir = path
+
+	return c.addCfStagingTimeout(
+		c.setCfHome(cmd),
+	)
+}
+
+// PushWithBuildpack pushes with the given buildpack and stack, leaving either
+// to the platform when empty.
+func (c *cfCmdGenerator) PushWithBuildpack(name, path, buildpack, stack string, instances int) cmdStartWaiter.CmdStartWaiter {
+	args := []string{
+		"push", name,
+		"-f", "manifest.yml",
+		"-i", strconv.Itoa(instances),
+	}
+
+	if buildpack != "" {
+		args = append(args, "-b", buildpack)
+	}
+
+	if stack != "" {
+		args = append(args, "-s", stack)
+	}
+
+	cmd := exec.Command("cf", args...)
+	cmd.Dir = path
+
+	return c.addCfStagingTimeout(
+		c.setCfHome(cmd),
+	)
+}
+
+func (c *cfCmdGenerator) PushDockerImage(name, image, username, password string, instances int) cmdStartWaiter.CmdStartWaiter {
+	args := []string{
+		"push", name,
+		"--docker-image", image,
+		"-i", strconv.Itoa(instances),
+		// A manifest.yml in the working directory would otherwise be applied.
+		"--no-manifest",
+	}
+
+	if username != "" {
+		args = append(args, "--docker-username", username)
+	}
+
+	cmd := c.setCfHome(exec.Command("cf", args...))
+	if username != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("CF_DOCKER_PASSWORD=%s", password))
+	}
+
+	return c.addCfStagingTimeout(cmd)
+}
+
 func (c *cfCmdGenerator) Delete(name string) cmdStartWaiter.CmdStartWaiter {
 	return c.setCfHome(
 		exec.Command(
@@ -244,6 +406,22 @@ func (c *cfCmdGenerator) BindService(appName, serviceName string) cmdStartWaiter
 	)
 }
 
+func (c *cfCmdGenerator) UnbindService(appName, serviceName string) cmdStartWaiter.CmdStartWaiter {
+	return c.setCfHome(
+		exec.Command(
+			"cf", "unbind-service", appName, serviceName,
+		),
+	)
+}
+
+func (c *cfCmdGenerator) DeleteService(serviceName string) cmdStartWaiter.CmdStartWaiter {
+	return c.setCfHome(
+		exec.Command(
+			"cf", "delete-service", serviceName, "-f",
+		),
+	)
+}
+
 func (c *cfCmdGenerator) Restage(appName string) cmdStartWaiter.CmdStartWaiter {
 	return c.setCfHome(
 		exec.Command(
@@ -251,3 +429,70 @@ func (c *cfCmdGenerator) Restage(appName string) cmdStartWaiter.CmdStartWaiter {
 		),
 	)
 }
+
+func (c *cfCmdGenerator) AppGuid(appName string) cmdStartWaiter.CmdStartWaiter {
+	return c.setCfHome(
+		exec.Command(
+			"cf", "app", appName,
+			"--guid",
+		),
+	)
+}
+
+// PurgeServiceInstance deletes a service instance by guid together with its
+// bindings, without targeting its space.
+func (c *cfCmdGenerator) PurgeServiceInstance(serviceGuid string) cmdStartWaiter.CmdStartWaiter {
+	return c.setCfHome(
+		exec.Command(
+			"cf", "curl", "--fail", "-X", "DELETE", fmt.Sprintf("/v3/service_instances/%s?purge=true", serviceGuid),
+		),
+	)
+}
+
+// Curl gets path from the Cloud Controller and prints the response body.
+func (c *cfCmdGenerator) Curl(path string) cmdStartWaiter.CmdStartWaiter {
+	return c.setCfHome(
+		exec.Command(
+			"cf", "curl", "--fail", path,
+		),
+	)
+}
+
+func (c *cfCmdGenerator) OauthToken() cmdStartWaiter.CmdStartWaiter {
+	return c.setCfHome(
+		exec.Command(
+			"cf", "oauth-token",
+		),
+	)
+}
+
+// AccessToken returns the access token the cf CLI keeps in the config of
+// CF_HOME, without the "bearer" prefix.
+func (c *cfCmdGenerator) AccessToken() (string, error) {
+	data, err := os.ReadFile(filepath.Join(c.cfHome, ".cf", "config.json"))
+	if err != nil {
+		return "", err
+	}
+
+	var config struct {
+		AccessToken string `json:"AccessToken"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", err
+	}
+	if config.AccessToken == "" {
+		return "", errors.New("Not logged in. Use 'cf login' or 'cf auth' to log in.")
+	}
+
+	return strings.TrimPrefix(config.AccessToken, "bearer "), nil
+}
+
+// RefreshToken obtains a new access token with the session's refresh token;
+// `cf oauth-token` always refreshes before printing the token.
+func (c *cfCmdGenerator) RefreshToken() cmdStartWaiter.CmdStartWaiter {
+	return c.setCfHome(
+		exec.Command(
+			"cf", "oauth-token",
+		),
+	)
+}
@@ -2,7 +2,9 @@ package cfCmdGenerator_test
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"time"
 
 	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
@@ -43,9 +45,17 @@ var _ = Describe("CfCmdGenerator", func() {
 
 	Describe("Auth", func() {
 		It("Generates the correct command", func() {
-			expectedCmd := exec.Command("cf", "auth", "user44", "pass55")
+			expectedCmd := exec.Command("cf", "auth")
 			cmd := generator.Auth("user44", "pass55")
-			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar)
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar, "CF_USERNAME=user44", "CF_PASSWORD=pass55")
+		})
+	})
+
+	Describe("AuthClientCredentials", func() {
+		It("Generates the correct command", func() {
+			expectedCmd := exec.Command("cf", "auth", "--client-credentials")
+			cmd := generator.AuthClientCredentials("client44", "secret55")
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar, "CF_USERNAME=client44", "CF_PASSWORD=secret55")
 		})
 	})
 
@@ -97,6 +107,27 @@ var _ = Describe("CfCmdGenerator", func() {
 		})
 	})
 
+	Describe("SetLabels", func() {
+		It("Generates the correct command with the labels in order", func() {
+			expectedCmd := exec.Command("cf", "set-label", "org", "someOrg", "a=1", "b=2")
+
+			cmd := generator.SetLabels("org", "someOrg", map[string]string{"b": "2", "a": "1"})
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar)
+		})
+	})
+
+	Describe("SetAnnotations", func() {
+		It("Generates a command patching the metadata of the resource by its guid", func() {
+			expectedCmd := exec.Command(
+				"sh", "-c", `guid=$(cf "$1" "$2" --guid) && cf curl --fail -X PATCH "/v3/$3/$guid" -d "$4"`,
+				"set-annotation", "service", "someService", "service_instances", `{"metadata":{"annotations":{"a":"1 2","b":"3"}}}`,
+			)
+
+			cmd := generator.SetAnnotations("service-instance", "someService", map[string]string{"b": "3", "a": "1 2"})
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar)
+		})
+	})
+
 	Describe("Push", func() {
 
 		It("Generates the correct command", func() {
@@ -130,6 +161,69 @@ var _ = Describe("CfCmdGenerator", func() {
 		})
 	})
 
+	Describe("RollingPush", func() {
+		It("Generates the correct command", func() {
+			expectedCmd := exec.Command("cf", "push", "appName", "-f", "manifest.yml", "-i", "2", "--strategy", "rolling", "-b", "go_buildpack")
+			expectedCmd.Dir = "path/to/app"
+
+			cmd := generator.RollingPush("appName", "path/to/app", 2)
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar, "CF_STAGING_TIMEOUT=5")
+		})
+
+		Context("given buildpack detection is turned on", func() {
+			BeforeEach(func() {
+				useBuildpackDetection = true
+			})
+
+			It("should not specify the go_buildpack", func() {
+				expectedCmd := exec.Command("cf", "push", "appName", "-f", "manifest.yml", "-i", "2", "--strategy", "rolling")
+				expectedCmd.Dir = "path/to/app"
+
+				cmd := generator.RollingPush("appName", "path/to/app", 2)
+				expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar, "CF_STAGING_TIMEOUT=5")
+			})
+		})
+	})
+
+	Describe("PushWithBuildpack", func() {
+		It("Generates the correct command", func() {
+			expectedCmd := exec.Command("cf", "push", "appName", "-f", "manifest.yml", "-i", "2", "-b", "staticfile_buildpack", "-s", "cflinuxfs4")
+			expectedCmd.Dir = "path/to/app"
+
+			cmd := generator.PushWithBuildpack("appName", "path/to/app", "staticfile_buildpack", "cflinuxfs4", 2)
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar, "CF_STAGING_TIMEOUT=5")
+		})
+
+		Context("when neither buildpack nor stack are given", func() {
+			It("leaves both to the platform", func() {
+				expectedCmd := exec.Command("cf", "push", "appName", "-f", "manifest.yml", "-i", "2")
+				expectedCmd.Dir = "path/to/app"
+
+				cmd := generator.PushWithBuildpack("appName", "path/to/app", "", "", 2)
+				expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar, "CF_STAGING_TIMEOUT=5")
+			})
+		})
+	})
+
+	Describe("PushDockerImage", func() {
+		It("Generates the correct command", func() {
+			expectedCmd := exec.Command("cf", "push", "appName", "--docker-image", "registry.example.com/some/image:latest", "-i", "2", "--no-manifest")
+
+			cmd := generator.PushDockerImage("appName", "registry.example.com/some/image:latest", "", "", 2)
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar, "CF_STAGING_TIMEOUT=5")
+			Expect(cmd.(*exec.Cmd).Env).NotTo(ContainElement(HavePrefix("CF_DOCKER_PASSWORD=")))
+		})
+
+		Context("when registry credentials are given", func() {
+			It("passes the username as a flag and the password in the environment", func() {
+				expectedCmd := exec.Command("cf", "push", "appName", "--docker-image", "registry.example.com/some/image:latest", "-i", "2", "--no-manifest", "--docker-username", "someUser")
+
+				cmd := generator.PushDockerImage("appName", "registry.example.com/some/image:latest", "someUser", "somePassword", 2)
+				expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar, "CF_STAGING_TIMEOUT=5", "CF_DOCKER_PASSWORD=somePassword")
+			})
+		})
+	})
+
 	Describe("Delete", func() {
 		It("Generates the correct command", func() {
 			expectedCmd := exec.Command("cf", "delete", "appName", "-f", "-r")
@@ -212,6 +306,38 @@ var _ = Describe("CfCmdGenerator", func() {
 		})
 	})
 
+	Describe("UnbindService", func() {
+		It("Generates the correct command", func() {
+			expectedCmd := exec.Command("cf", "unbind-service", "appName", "serviceName")
+			cmd := generator.UnbindService("appName", "serviceName")
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar)
+		})
+	})
+
+	Describe("DeleteService", func() {
+		It("Generates the correct command", func() {
+			expectedCmd := exec.Command("cf", "delete-service", "serviceName", "-f")
+			cmd := generator.DeleteService("serviceName")
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar)
+		})
+	})
+
+	Describe("PurgeServiceInstance", func() {
+		It("Generates the correct command", func() {
+			expectedCmd := exec.Command("cf", "curl", "--fail", "-X", "DELETE", "/v3/service_instances/service-guid?purge=true")
+			cmd := generator.PurgeServiceInstance("service-guid")
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar)
+		})
+	})
+
+	Describe("Curl", func() {
+		It("Generates the correct command", func() {
+			expectedCmd := exec.Command("cf", "curl", "--fail", "/v3/organizations?per_page=5000")
+			cmd := generator.Curl("/v3/organizations?per_page=5000")
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar)
+		})
+	})
+
 	Describe("Restage", func() {
 		It("Generates the correct command", func() {
 			expectedCmd := exec.Command("cf", "restage", "appName")
@@ -220,6 +346,62 @@ var _ = Describe("CfCmdGenerator", func() {
 		})
 	})
 
+	Describe("AppGuid", func() {
+		It("Generates the correct command", func() {
+			expectedCmd := exec.Command("cf", "app", "appName", "--guid")
+			cmd := generator.AppGuid("appName")
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar)
+		})
+	})
+
+	Describe("OauthToken", func() {
+		It("Generates the correct command", func() {
+			expectedCmd := exec.Command("cf", "oauth-token")
+			cmd := generator.OauthToken()
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar)
+		})
+	})
+
+	Describe("RefreshToken", func() {
+		It("Generates the correct command", func() {
+			expectedCmd := exec.Command("cf", "oauth-token")
+			cmd := generator.RefreshToken()
+			expectCommandToBeEquivalent(cmd, expectedCmd, cfHomeEnvVar)
+		})
+	})
+
+	Describe("AccessToken", func() {
+		var tmpCfHome string
+
+		BeforeEach(func() {
+			tmpCfHome = GinkgoT().TempDir()
+		})
+
+		JustBeforeEach(func() {
+			generator = New(tmpCfHome, false)
+		})
+
+		It("reads the access token from the CF_HOME config without its prefix", func() {
+			Expect(os.MkdirAll(filepath.Join(tmpCfHome, ".cf"), 0700)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tmpCfHome, ".cf", "config.json"), []byte(`{"AccessToken": "bearer some-token"}`), 0600)).To(Succeed())
+
+			Expect(generator.AccessToken()).To(Equal("some-token"))
+		})
+
+		It("fails when CF_HOME is not logged in", func() {
+			Expect(os.MkdirAll(filepath.Join(tmpCfHome, ".cf"), 0700)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tmpCfHome, ".cf", "config.json"), []byte(`{"AccessToken": ""}`), 0600)).To(Succeed())
+
+			_, err := generator.AccessToken()
+			Expect(err).To(MatchError(ContainSubstring("Not logged in")))
+		})
+
+		It("fails when CF_HOME has no config", func() {
+			_, err := generator.AccessToken()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Describe("EnableOrgIsolation", func() {
 		It("Generates the correct command", func() {
 			expectedCmd := exec.Command("cf", "enable-org-isolation", "someOrg", "someIsoSeg")
@@ -10,6 +10,18 @@ import (
 )
 
 type FakeCfCmdGenerator struct {
+	AccessTokenStub        func() (string, error)
+	accessTokenMutex       sync.RWMutex
+	accessTokenArgsForCall []struct {
+	}
+	accessTokenReturns struct {
+		result1 string
+		result2 error
+	}
+	accessTokenReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	ApiStub        func(string) cmdStartWaiter.CmdStartWaiter
 	apiMutex       sync.RWMutex
 	apiArgsForCall []struct {
@@ -21,6 +33,17 @@ type FakeCfCmdGenerator struct {
 	apiReturnsOnCall map[int]struct {
 		result1 cmdStartWaiter.CmdStartWaiter
 	}
+	AppGuidStub        func(string) cmdStartWaiter.CmdStartWaiter
+	appGuidMutex       sync.RWMutex
+	appGuidArgsForCall []struct {
+		arg1 string
+	}
+	appGuidReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	appGuidReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
 	AppStatsStub        func(string) cmdStartWaiter.CmdStartWaiter
 	appStatsMutex       sync.RWMutex
 	appStatsArgsForCall []struct {
@@ -44,6 +67,18 @@ type FakeCfCmdGenerator struct {
 	authReturnsOnCall map[int]struct {
 		result1 cmdStartWaiter.CmdStartWaiter
 	}
+	AuthClientCredentialsStub        func(string, string) cmdStartWaiter.CmdStartWaiter
+	authClientCredentialsMutex       sync.RWMutex
+	authClientCredentialsArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	authClientCredentialsReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	authClientCredentialsReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
 	BindServiceStub        func(string, string) cmdStartWaiter.CmdStartWaiter
 	bindServiceMutex       sync.RWMutex
 	bindServiceArgsForCall []struct {
@@ -102,6 +137,17 @@ type FakeCfCmdGenerator struct {
 	createUserProvidedServiceReturnsOnCall map[int]struct {
 		result1 cmdStartWaiter.CmdStartWaiter
 	}
+	CurlStub        func(string) cmdStartWaiter.CmdStartWaiter
+	curlMutex       sync.RWMutex
+	curlArgsForCall []struct {
+		arg1 string
+	}
+	curlReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	curlReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
 	DeleteStub        func(string) cmdStartWaiter.CmdStartWaiter
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
@@ -135,6 +181,17 @@ type FakeCfCmdGenerator struct {
 	deleteQuotaReturnsOnCall map[int]struct {
 		result1 cmdStartWaiter.CmdStartWaiter
 	}
+	DeleteServiceStub        func(string) cmdStartWaiter.CmdStartWaiter
+	deleteServiceMutex       sync.RWMutex
+	deleteServiceArgsForCall []struct {
+		arg1 string
+	}
+	deleteServiceReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	deleteServiceReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
 	EnableOrgIsolationStub        func(string, string) cmdStartWaiter.CmdStartWaiter
 	enableOrgIsolationMutex       sync.RWMutex
 	enableOrgIsolationArgsForCall []struct {
@@ -170,6 +227,27 @@ type FakeCfCmdGenerator struct {
 	mapRouteReturnsOnCall map[int]struct {
 		result1 cmdStartWaiter.CmdStartWaiter
 	}
+	OauthTokenStub        func() cmdStartWaiter.CmdStartWaiter
+	oauthTokenMutex       sync.RWMutex
+	oauthTokenArgsForCall []struct {
+	}
+	oauthTokenReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	oauthTokenReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	PurgeServiceInstanceStub        func(string) cmdStartWaiter.CmdStartWaiter
+	purgeServiceInstanceMutex       sync.RWMutex
+	purgeServiceInstanceArgsForCall []struct {
+		arg1 string
+	}
+	purgeServiceInstanceReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	purgeServiceInstanceReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
 	PushStub        func(string, string, int, bool) cmdStartWaiter.CmdStartWaiter
 	pushMutex       sync.RWMutex
 	pushArgsForCall []struct {
@@ -184,6 +262,36 @@ type FakeCfCmdGenerator struct {
 	pushReturnsOnCall map[int]struct {
 		result1 cmdStartWaiter.CmdStartWaiter
 	}
+	PushDockerImageStub        func(string, string, string, string, int) cmdStartWaiter.CmdStartWaiter
+	pushDockerImageMutex       sync.RWMutex
+	pushDockerImageArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 int
+	}
+	pushDockerImageReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	pushDockerImageReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	PushWithBuildpackStub        func(string, string, string, string, int) cmdStartWaiter.CmdStartWaiter
+	pushWithBuildpackMutex       sync.RWMutex
+	pushWithBuildpackArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 int
+	}
+	pushWithBuildpackReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	pushWithBuildpackReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
 	RecentLogsStub        func(string) cmdStartWaiter.CmdStartWaiter
 	recentLogsMutex       sync.RWMutex
 	recentLogsArgsForCall []struct {
@@ -195,6 +303,16 @@ type FakeCfCmdGenerator struct {
 	recentLogsReturnsOnCall map[int]struct {
 		result1 cmdStartWaiter.CmdStartWaiter
 	}
+	RefreshTokenStub        func() cmdStartWaiter.CmdStartWaiter
+	refreshTokenMutex       sync.RWMutex
+	refreshTokenArgsForCall []struct {
+	}
+	refreshTokenReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	refreshTokenReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
 	RestageStub        func(string) cmdStartWaiter.CmdStartWaiter
 	restageMutex       sync.RWMutex
 	restageArgsForCall []struct {
@@ -206,6 +324,45 @@ type FakeCfCmdGenerator struct {
 	restageReturnsOnCall map[int]struct {
 		result1 cmdStartWaiter.CmdStartWaiter
 	}
+	RollingPushStub        func(string, string, int) cmdStartWaiter.CmdStartWaiter
+	rollingPushMutex       sync.RWMutex
+	rollingPushArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 int
+	}
+	rollingPushReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	rollingPushReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	SetAnnotationsStub        func(string, string, map[string]string) cmdStartWaiter.CmdStartWaiter
+	setAnnotationsMutex       sync.RWMutex
+	setAnnotationsArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 map[string]string
+	}
+	setAnnotationsReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	setAnnotationsReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	SetLabelsStub        func(string, string, map[string]string) cmdStartWaiter.CmdStartWaiter
+	setLabelsMutex       sync.RWMutex
+	setLabelsArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 map[string]string
+	}
+	setLabelsReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	setLabelsReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
 	SetOrgDefaultIsolationSegmentStub        func(string, string) cmdStartWaiter.CmdStartWaiter
 	setOrgDefaultIsolationSegmentMutex       sync.RWMutex
 	setOrgDefaultIsolationSegmentArgsForCall []struct {
@@ -254,10 +411,78 @@ type FakeCfCmdGenerator struct {
 	targetReturnsOnCall map[int]struct {
 		result1 cmdStartWaiter.CmdStartWaiter
 	}
+	UnbindServiceStub        func(string, string) cmdStartWaiter.CmdStartWaiter
+	unbindServiceMutex       sync.RWMutex
+	unbindServiceArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	unbindServiceReturns struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
+	unbindServiceReturnsOnCall map[int]struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeCfCmdGenerator) AccessToken() (string, error) {
+	fake.accessTokenMutex.Lock()
+	ret, specificReturn := fake.accessTokenReturnsOnCall[len(fake.accessTokenArgsForCall)]
+	fake.accessTokenArgsForCall = append(fake.accessTokenArgsForCall, struct {
+	}{})
+	stub := fake.AccessTokenStub
+	fakeReturns := fake.accessTokenReturns
+	fake.recordInvocation("AccessToken", []interface{}{})
+	fake.accessTokenMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCfCmdGenerator) AccessTokenCallCount() int {
+	fake.accessTokenMutex.RLock()
+	defer fake.accessTokenMutex.RUnlock()
+	return len(fake.accessTokenArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) AccessTokenCalls(stub func() (string, error)) {
+	fake.accessTokenMutex.Lock()
+	defer fake.accessTokenMutex.Unlock()
+	fake.AccessTokenStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) AccessTokenReturns(result1 string, result2 error) {
+	fake.accessTokenMutex.Lock()
+	defer fake.accessTokenMutex.Unlock()
+	fake.AccessTokenStub = nil
+	fake.accessTokenReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCfCmdGenerator) AccessTokenReturnsOnCall(i int, result1 string, result2 error) {
+	fake.accessTokenMutex.Lock()
+	defer fake.accessTokenMutex.Unlock()
+	fake.AccessTokenStub = nil
+	if fake.accessTokenReturnsOnCall == nil {
+		fake.accessTokenReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.accessTokenReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCfCmdGenerator) Api(arg1 string) cmdStartWaiter.CmdStartWaiter {
 	fake.apiMutex.Lock()
 	ret, specificReturn := fake.apiReturnsOnCall[len(fake.apiArgsForCall)]
@@ -319,6 +544,67 @@ func (fake *FakeCfCmdGenerator) ApiReturnsOnCall(i int, result1 cmdStartWaiter.C
 	}{result1}
 }
 
+func (fake *FakeCfCmdGenerator) AppGuid(arg1 string) cmdStartWaiter.CmdStartWaiter {
+	fake.appGuidMutex.Lock()
+	ret, specificReturn := fake.appGuidReturnsOnCall[len(fake.appGuidArgsForCall)]
+	fake.appGuidArgsForCall = append(fake.appGuidArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.AppGuidStub
+	fakeReturns := fake.appGuidReturns
+	fake.recordInvocation("AppGuid", []interface{}{arg1})
+	fake.appGuidMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) AppGuidCallCount() int {
+	fake.appGuidMutex.RLock()
+	defer fake.appGuidMutex.RUnlock()
+	return len(fake.appGuidArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) AppGuidCalls(stub func(string) cmdStartWaiter.CmdStartWaiter) {
+	fake.appGuidMutex.Lock()
+	defer fake.appGuidMutex.Unlock()
+	fake.AppGuidStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) AppGuidArgsForCall(i int) string {
+	fake.appGuidMutex.RLock()
+	defer fake.appGuidMutex.RUnlock()
+	argsForCall := fake.appGuidArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCfCmdGenerator) AppGuidReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.appGuidMutex.Lock()
+	defer fake.appGuidMutex.Unlock()
+	fake.AppGuidStub = nil
+	fake.appGuidReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) AppGuidReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.appGuidMutex.Lock()
+	defer fake.appGuidMutex.Unlock()
+	fake.AppGuidStub = nil
+	if fake.appGuidReturnsOnCall == nil {
+		fake.appGuidReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.appGuidReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfCmdGenerator) AppStats(arg1 string) cmdStartWaiter.CmdStartWaiter {
 	fake.appStatsMutex.Lock()
 	ret, specificReturn := fake.appStatsReturnsOnCall[len(fake.appStatsArgsForCall)]
@@ -442,6 +728,68 @@ func (fake *FakeCfCmdGenerator) AuthReturnsOnCall(i int, result1 cmdStartWaiter.
 	}{result1}
 }
 
+func (fake *FakeCfCmdGenerator) AuthClientCredentials(arg1 string, arg2 string) cmdStartWaiter.CmdStartWaiter {
+	fake.authClientCredentialsMutex.Lock()
+	ret, specificReturn := fake.authClientCredentialsReturnsOnCall[len(fake.authClientCredentialsArgsForCall)]
+	fake.authClientCredentialsArgsForCall = append(fake.authClientCredentialsArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.AuthClientCredentialsStub
+	fakeReturns := fake.authClientCredentialsReturns
+	fake.recordInvocation("AuthClientCredentials", []interface{}{arg1, arg2})
+	fake.authClientCredentialsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) AuthClientCredentialsCallCount() int {
+	fake.authClientCredentialsMutex.RLock()
+	defer fake.authClientCredentialsMutex.RUnlock()
+	return len(fake.authClientCredentialsArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) AuthClientCredentialsCalls(stub func(string, string) cmdStartWaiter.CmdStartWaiter) {
+	fake.authClientCredentialsMutex.Lock()
+	defer fake.authClientCredentialsMutex.Unlock()
+	fake.AuthClientCredentialsStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) AuthClientCredentialsArgsForCall(i int) (string, string) {
+	fake.authClientCredentialsMutex.RLock()
+	defer fake.authClientCredentialsMutex.RUnlock()
+	argsForCall := fake.authClientCredentialsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCfCmdGenerator) AuthClientCredentialsReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.authClientCredentialsMutex.Lock()
+	defer fake.authClientCredentialsMutex.Unlock()
+	fake.AuthClientCredentialsStub = nil
+	fake.authClientCredentialsReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) AuthClientCredentialsReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.authClientCredentialsMutex.Lock()
+	defer fake.authClientCredentialsMutex.Unlock()
+	fake.AuthClientCredentialsStub = nil
+	if fake.authClientCredentialsReturnsOnCall == nil {
+		fake.authClientCredentialsReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.authClientCredentialsReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfCmdGenerator) BindService(arg1 string, arg2 string) cmdStartWaiter.CmdStartWaiter {
 	fake.bindServiceMutex.Lock()
 	ret, specificReturn := fake.bindServiceReturnsOnCall[len(fake.bindServiceArgsForCall)]
@@ -750,6 +1098,67 @@ func (fake *FakeCfCmdGenerator) CreateUserProvidedServiceReturnsOnCall(i int, re
 	}{result1}
 }
 
+func (fake *FakeCfCmdGenerator) Curl(arg1 string) cmdStartWaiter.CmdStartWaiter {
+	fake.curlMutex.Lock()
+	ret, specificReturn := fake.curlReturnsOnCall[len(fake.curlArgsForCall)]
+	fake.curlArgsForCall = append(fake.curlArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CurlStub
+	fakeReturns := fake.curlReturns
+	fake.recordInvocation("Curl", []interface{}{arg1})
+	fake.curlMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) CurlCallCount() int {
+	fake.curlMutex.RLock()
+	defer fake.curlMutex.RUnlock()
+	return len(fake.curlArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) CurlCalls(stub func(string) cmdStartWaiter.CmdStartWaiter) {
+	fake.curlMutex.Lock()
+	defer fake.curlMutex.Unlock()
+	fake.CurlStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) CurlArgsForCall(i int) string {
+	fake.curlMutex.RLock()
+	defer fake.curlMutex.RUnlock()
+	argsForCall := fake.curlArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCfCmdGenerator) CurlReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.curlMutex.Lock()
+	defer fake.curlMutex.Unlock()
+	fake.CurlStub = nil
+	fake.curlReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) CurlReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.curlMutex.Lock()
+	defer fake.curlMutex.Unlock()
+	fake.CurlStub = nil
+	if fake.curlReturnsOnCall == nil {
+		fake.curlReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.curlReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfCmdGenerator) Delete(arg1 string) cmdStartWaiter.CmdStartWaiter {
 	fake.deleteMutex.Lock()
 	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
@@ -933,6 +1342,67 @@ func (fake *FakeCfCmdGenerator) DeleteQuotaReturnsOnCall(i int, result1 cmdStart
 	}{result1}
 }
 
+func (fake *FakeCfCmdGenerator) DeleteService(arg1 string) cmdStartWaiter.CmdStartWaiter {
+	fake.deleteServiceMutex.Lock()
+	ret, specificReturn := fake.deleteServiceReturnsOnCall[len(fake.deleteServiceArgsForCall)]
+	fake.deleteServiceArgsForCall = append(fake.deleteServiceArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteServiceStub
+	fakeReturns := fake.deleteServiceReturns
+	fake.recordInvocation("DeleteService", []interface{}{arg1})
+	fake.deleteServiceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) DeleteServiceCallCount() int {
+	fake.deleteServiceMutex.RLock()
+	defer fake.deleteServiceMutex.RUnlock()
+	return len(fake.deleteServiceArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) DeleteServiceCalls(stub func(string) cmdStartWaiter.CmdStartWaiter) {
+	fake.deleteServiceMutex.Lock()
+	defer fake.deleteServiceMutex.Unlock()
+	fake.DeleteServiceStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) DeleteServiceArgsForCall(i int) string {
+	fake.deleteServiceMutex.RLock()
+	defer fake.deleteServiceMutex.RUnlock()
+	argsForCall := fake.deleteServiceArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCfCmdGenerator) DeleteServiceReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.deleteServiceMutex.Lock()
+	defer fake.deleteServiceMutex.Unlock()
+	fake.DeleteServiceStub = nil
+	fake.deleteServiceReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) DeleteServiceReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.deleteServiceMutex.Lock()
+	defer fake.deleteServiceMutex.Unlock()
+	fake.DeleteServiceStub = nil
+	if fake.deleteServiceReturnsOnCall == nil {
+		fake.deleteServiceReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.deleteServiceReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfCmdGenerator) EnableOrgIsolation(arg1 string, arg2 string) cmdStartWaiter.CmdStartWaiter {
 	fake.enableOrgIsolationMutex.Lock()
 	ret, specificReturn := fake.enableOrgIsolationReturnsOnCall[len(fake.enableOrgIsolationArgsForCall)]
@@ -1111,6 +1581,120 @@ func (fake *FakeCfCmdGenerator) MapRouteReturnsOnCall(i int, result1 cmdStartWai
 	}{result1}
 }
 
+func (fake *FakeCfCmdGenerator) OauthToken() cmdStartWaiter.CmdStartWaiter {
+	fake.oauthTokenMutex.Lock()
+	ret, specificReturn := fake.oauthTokenReturnsOnCall[len(fake.oauthTokenArgsForCall)]
+	fake.oauthTokenArgsForCall = append(fake.oauthTokenArgsForCall, struct {
+	}{})
+	stub := fake.OauthTokenStub
+	fakeReturns := fake.oauthTokenReturns
+	fake.recordInvocation("OauthToken", []interface{}{})
+	fake.oauthTokenMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) OauthTokenCallCount() int {
+	fake.oauthTokenMutex.RLock()
+	defer fake.oauthTokenMutex.RUnlock()
+	return len(fake.oauthTokenArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) OauthTokenCalls(stub func() cmdStartWaiter.CmdStartWaiter) {
+	fake.oauthTokenMutex.Lock()
+	defer fake.oauthTokenMutex.Unlock()
+	fake.OauthTokenStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) OauthTokenReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.oauthTokenMutex.Lock()
+	defer fake.oauthTokenMutex.Unlock()
+	fake.OauthTokenStub = nil
+	fake.oauthTokenReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) OauthTokenReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.oauthTokenMutex.Lock()
+	defer fake.oauthTokenMutex.Unlock()
+	fake.OauthTokenStub = nil
+	if fake.oauthTokenReturnsOnCall == nil {
+		fake.oauthTokenReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.oauthTokenReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) PurgeServiceInstance(arg1 string) cmdStartWaiter.CmdStartWaiter {
+	fake.purgeServiceInstanceMutex.Lock()
+	ret, specificReturn := fake.purgeServiceInstanceReturnsOnCall[len(fake.purgeServiceInstanceArgsForCall)]
+	fake.purgeServiceInstanceArgsForCall = append(fake.purgeServiceInstanceArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.PurgeServiceInstanceStub
+	fakeReturns := fake.purgeServiceInstanceReturns
+	fake.recordInvocation("PurgeServiceInstance", []interface{}{arg1})
+	fake.purgeServiceInstanceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) PurgeServiceInstanceCallCount() int {
+	fake.purgeServiceInstanceMutex.RLock()
+	defer fake.purgeServiceInstanceMutex.RUnlock()
+	return len(fake.purgeServiceInstanceArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) PurgeServiceInstanceCalls(stub func(string) cmdStartWaiter.CmdStartWaiter) {
+	fake.purgeServiceInstanceMutex.Lock()
+	defer fake.purgeServiceInstanceMutex.Unlock()
+	fake.PurgeServiceInstanceStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) PurgeServiceInstanceArgsForCall(i int) string {
+	fake.purgeServiceInstanceMutex.RLock()
+	defer fake.purgeServiceInstanceMutex.RUnlock()
+	argsForCall := fake.purgeServiceInstanceArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCfCmdGenerator) PurgeServiceInstanceReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.purgeServiceInstanceMutex.Lock()
+	defer fake.purgeServiceInstanceMutex.Unlock()
+	fake.PurgeServiceInstanceStub = nil
+	fake.purgeServiceInstanceReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) PurgeServiceInstanceReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.purgeServiceInstanceMutex.Lock()
+	defer fake.purgeServiceInstanceMutex.Unlock()
+	fake.PurgeServiceInstanceStub = nil
+	if fake.purgeServiceInstanceReturnsOnCall == nil {
+		fake.purgeServiceInstanceReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.purgeServiceInstanceReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfCmdGenerator) Push(arg1 string, arg2 string, arg3 int, arg4 bool) cmdStartWaiter.CmdStartWaiter {
 	fake.pushMutex.Lock()
 	ret, specificReturn := fake.pushReturnsOnCall[len(fake.pushArgsForCall)]
@@ -1175,6 +1759,136 @@ func (fake *FakeCfCmdGenerator) PushReturnsOnCall(i int, result1 cmdStartWaiter.
 	}{result1}
 }
 
+func (fake *FakeCfCmdGenerator) PushDockerImage(arg1 string, arg2 string, arg3 string, arg4 string, arg5 int) cmdStartWaiter.CmdStartWaiter {
+	fake.pushDockerImageMutex.Lock()
+	ret, specificReturn := fake.pushDockerImageReturnsOnCall[len(fake.pushDockerImageArgsForCall)]
+	fake.pushDockerImageArgsForCall = append(fake.pushDockerImageArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 int
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.PushDockerImageStub
+	fakeReturns := fake.pushDockerImageReturns
+	fake.recordInvocation("PushDockerImage", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.pushDockerImageMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) PushDockerImageCallCount() int {
+	fake.pushDockerImageMutex.RLock()
+	defer fake.pushDockerImageMutex.RUnlock()
+	return len(fake.pushDockerImageArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) PushDockerImageCalls(stub func(string, string, string, string, int) cmdStartWaiter.CmdStartWaiter) {
+	fake.pushDockerImageMutex.Lock()
+	defer fake.pushDockerImageMutex.Unlock()
+	fake.PushDockerImageStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) PushDockerImageArgsForCall(i int) (string, string, string, string, int) {
+	fake.pushDockerImageMutex.RLock()
+	defer fake.pushDockerImageMutex.RUnlock()
+	argsForCall := fake.pushDockerImageArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeCfCmdGenerator) PushDockerImageReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.pushDockerImageMutex.Lock()
+	defer fake.pushDockerImageMutex.Unlock()
+	fake.PushDockerImageStub = nil
+	fake.pushDockerImageReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) PushDockerImageReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.pushDockerImageMutex.Lock()
+	defer fake.pushDockerImageMutex.Unlock()
+	fake.PushDockerImageStub = nil
+	if fake.pushDockerImageReturnsOnCall == nil {
+		fake.pushDockerImageReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.pushDockerImageReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) PushWithBuildpack(arg1 string, arg2 string, arg3 string, arg4 string, arg5 int) cmdStartWaiter.CmdStartWaiter {
+	fake.pushWithBuildpackMutex.Lock()
+	ret, specificReturn := fake.pushWithBuildpackReturnsOnCall[len(fake.pushWithBuildpackArgsForCall)]
+	fake.pushWithBuildpackArgsForCall = append(fake.pushWithBuildpackArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 int
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.PushWithBuildpackStub
+	fakeReturns := fake.pushWithBuildpackReturns
+	fake.recordInvocation("PushWithBuildpack", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.pushWithBuildpackMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) PushWithBuildpackCallCount() int {
+	fake.pushWithBuildpackMutex.RLock()
+	defer fake.pushWithBuildpackMutex.RUnlock()
+	return len(fake.pushWithBuildpackArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) PushWithBuildpackCalls(stub func(string, string, string, string, int) cmdStartWaiter.CmdStartWaiter) {
+	fake.pushWithBuildpackMutex.Lock()
+	defer fake.pushWithBuildpackMutex.Unlock()
+	fake.PushWithBuildpackStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) PushWithBuildpackArgsForCall(i int) (string, string, string, string, int) {
+	fake.pushWithBuildpackMutex.RLock()
+	defer fake.pushWithBuildpackMutex.RUnlock()
+	argsForCall := fake.pushWithBuildpackArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeCfCmdGenerator) PushWithBuildpackReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.pushWithBuildpackMutex.Lock()
+	defer fake.pushWithBuildpackMutex.Unlock()
+	fake.PushWithBuildpackStub = nil
+	fake.pushWithBuildpackReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) PushWithBuildpackReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.pushWithBuildpackMutex.Lock()
+	defer fake.pushWithBuildpackMutex.Unlock()
+	fake.PushWithBuildpackStub = nil
+	if fake.pushWithBuildpackReturnsOnCall == nil {
+		fake.pushWithBuildpackReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.pushWithBuildpackReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfCmdGenerator) RecentLogs(arg1 string) cmdStartWaiter.CmdStartWaiter {
 	fake.recentLogsMutex.Lock()
 	ret, specificReturn := fake.recentLogsReturnsOnCall[len(fake.recentLogsArgsForCall)]
@@ -1236,6 +1950,59 @@ func (fake *FakeCfCmdGenerator) RecentLogsReturnsOnCall(i int, result1 cmdStartW
 	}{result1}
 }
 
+func (fake *FakeCfCmdGenerator) RefreshToken() cmdStartWaiter.CmdStartWaiter {
+	fake.refreshTokenMutex.Lock()
+	ret, specificReturn := fake.refreshTokenReturnsOnCall[len(fake.refreshTokenArgsForCall)]
+	fake.refreshTokenArgsForCall = append(fake.refreshTokenArgsForCall, struct {
+	}{})
+	stub := fake.RefreshTokenStub
+	fakeReturns := fake.refreshTokenReturns
+	fake.recordInvocation("RefreshToken", []interface{}{})
+	fake.refreshTokenMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) RefreshTokenCallCount() int {
+	fake.refreshTokenMutex.RLock()
+	defer fake.refreshTokenMutex.RUnlock()
+	return len(fake.refreshTokenArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) RefreshTokenCalls(stub func() cmdStartWaiter.CmdStartWaiter) {
+	fake.refreshTokenMutex.Lock()
+	defer fake.refreshTokenMutex.Unlock()
+	fake.RefreshTokenStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) RefreshTokenReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.refreshTokenMutex.Lock()
+	defer fake.refreshTokenMutex.Unlock()
+	fake.RefreshTokenStub = nil
+	fake.refreshTokenReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) RefreshTokenReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.refreshTokenMutex.Lock()
+	defer fake.refreshTokenMutex.Unlock()
+	fake.RefreshTokenStub = nil
+	if fake.refreshTokenReturnsOnCall == nil {
+		fake.refreshTokenReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.refreshTokenReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfCmdGenerator) Restage(arg1 string) cmdStartWaiter.CmdStartWaiter {
 	fake.restageMutex.Lock()
 	ret, specificReturn := fake.restageReturnsOnCall[len(fake.restageArgsForCall)]
@@ -1297,6 +2064,195 @@ func (fake *FakeCfCmdGenerator) RestageReturnsOnCall(i int, result1 cmdStartWait
 	}{result1}
 }
 
+func (fake *FakeCfCmdGenerator) RollingPush(arg1 string, arg2 string, arg3 int) cmdStartWaiter.CmdStartWaiter {
+	fake.rollingPushMutex.Lock()
+	ret, specificReturn := fake.rollingPushReturnsOnCall[len(fake.rollingPushArgsForCall)]
+	fake.rollingPushArgsForCall = append(fake.rollingPushArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 int
+	}{arg1, arg2, arg3})
+	stub := fake.RollingPushStub
+	fakeReturns := fake.rollingPushReturns
+	fake.recordInvocation("RollingPush", []interface{}{arg1, arg2, arg3})
+	fake.rollingPushMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) RollingPushCallCount() int {
+	fake.rollingPushMutex.RLock()
+	defer fake.rollingPushMutex.RUnlock()
+	return len(fake.rollingPushArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) RollingPushCalls(stub func(string, string, int) cmdStartWaiter.CmdStartWaiter) {
+	fake.rollingPushMutex.Lock()
+	defer fake.rollingPushMutex.Unlock()
+	fake.RollingPushStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) RollingPushArgsForCall(i int) (string, string, int) {
+	fake.rollingPushMutex.RLock()
+	defer fake.rollingPushMutex.RUnlock()
+	argsForCall := fake.rollingPushArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeCfCmdGenerator) RollingPushReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.rollingPushMutex.Lock()
+	defer fake.rollingPushMutex.Unlock()
+	fake.RollingPushStub = nil
+	fake.rollingPushReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) RollingPushReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.rollingPushMutex.Lock()
+	defer fake.rollingPushMutex.Unlock()
+	fake.RollingPushStub = nil
+	if fake.rollingPushReturnsOnCall == nil {
+		fake.rollingPushReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.rollingPushReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) SetAnnotations(arg1 string, arg2 string, arg3 map[string]string) cmdStartWaiter.CmdStartWaiter {
+	fake.setAnnotationsMutex.Lock()
+	ret, specificReturn := fake.setAnnotationsReturnsOnCall[len(fake.setAnnotationsArgsForCall)]
+	fake.setAnnotationsArgsForCall = append(fake.setAnnotationsArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 map[string]string
+	}{arg1, arg2, arg3})
+	stub := fake.SetAnnotationsStub
+	fakeReturns := fake.setAnnotationsReturns
+	fake.recordInvocation("SetAnnotations", []interface{}{arg1, arg2, arg3})
+	fake.setAnnotationsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) SetAnnotationsCallCount() int {
+	fake.setAnnotationsMutex.RLock()
+	defer fake.setAnnotationsMutex.RUnlock()
+	return len(fake.setAnnotationsArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) SetAnnotationsCalls(stub func(string, string, map[string]string) cmdStartWaiter.CmdStartWaiter) {
+	fake.setAnnotationsMutex.Lock()
+	defer fake.setAnnotationsMutex.Unlock()
+	fake.SetAnnotationsStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) SetAnnotationsArgsForCall(i int) (string, string, map[string]string) {
+	fake.setAnnotationsMutex.RLock()
+	defer fake.setAnnotationsMutex.RUnlock()
+	argsForCall := fake.setAnnotationsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeCfCmdGenerator) SetAnnotationsReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.setAnnotationsMutex.Lock()
+	defer fake.setAnnotationsMutex.Unlock()
+	fake.SetAnnotationsStub = nil
+	fake.setAnnotationsReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) SetAnnotationsReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.setAnnotationsMutex.Lock()
+	defer fake.setAnnotationsMutex.Unlock()
+	fake.SetAnnotationsStub = nil
+	if fake.setAnnotationsReturnsOnCall == nil {
+		fake.setAnnotationsReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.setAnnotationsReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) SetLabels(arg1 string, arg2 string, arg3 map[string]string) cmdStartWaiter.CmdStartWaiter {
+	fake.setLabelsMutex.Lock()
+	ret, specificReturn := fake.setLabelsReturnsOnCall[len(fake.setLabelsArgsForCall)]
+	fake.setLabelsArgsForCall = append(fake.setLabelsArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 map[string]string
+	}{arg1, arg2, arg3})
+	stub := fake.SetLabelsStub
+	fakeReturns := fake.setLabelsReturns
+	fake.recordInvocation("SetLabels", []interface{}{arg1, arg2, arg3})
+	fake.setLabelsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) SetLabelsCallCount() int {
+	fake.setLabelsMutex.RLock()
+	defer fake.setLabelsMutex.RUnlock()
+	return len(fake.setLabelsArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) SetLabelsCalls(stub func(string, string, map[string]string) cmdStartWaiter.CmdStartWaiter) {
+	fake.setLabelsMutex.Lock()
+	defer fake.setLabelsMutex.Unlock()
+	fake.SetLabelsStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) SetLabelsArgsForCall(i int) (string, string, map[string]string) {
+	fake.setLabelsMutex.RLock()
+	defer fake.setLabelsMutex.RUnlock()
+	argsForCall := fake.setLabelsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeCfCmdGenerator) SetLabelsReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.setLabelsMutex.Lock()
+	defer fake.setLabelsMutex.Unlock()
+	fake.SetLabelsStub = nil
+	fake.setLabelsReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) SetLabelsReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.setLabelsMutex.Lock()
+	defer fake.setLabelsMutex.Unlock()
+	fake.SetLabelsStub = nil
+	if fake.setLabelsReturnsOnCall == nil {
+		fake.setLabelsReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.setLabelsReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfCmdGenerator) SetOrgDefaultIsolationSegment(arg1 string, arg2 string) cmdStartWaiter.CmdStartWaiter {
 	fake.setOrgDefaultIsolationSegmentMutex.Lock()
 	ret, specificReturn := fake.setOrgDefaultIsolationSegmentReturnsOnCall[len(fake.setOrgDefaultIsolationSegmentArgsForCall)]
@@ -1545,15 +2501,83 @@ func (fake *FakeCfCmdGenerator) TargetReturnsOnCall(i int, result1 cmdStartWaite
 	}{result1}
 }
 
+func (fake *FakeCfCmdGenerator) UnbindService(arg1 string, arg2 string) cmdStartWaiter.CmdStartWaiter {
+	fake.unbindServiceMutex.Lock()
+	ret, specificReturn := fake.unbindServiceReturnsOnCall[len(fake.unbindServiceArgsForCall)]
+	fake.unbindServiceArgsForCall = append(fake.unbindServiceArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.UnbindServiceStub
+	fakeReturns := fake.unbindServiceReturns
+	fake.recordInvocation("UnbindService", []interface{}{arg1, arg2})
+	fake.unbindServiceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfCmdGenerator) UnbindServiceCallCount() int {
+	fake.unbindServiceMutex.RLock()
+	defer fake.unbindServiceMutex.RUnlock()
+	return len(fake.unbindServiceArgsForCall)
+}
+
+func (fake *FakeCfCmdGenerator) UnbindServiceCalls(stub func(string, string) cmdStartWaiter.CmdStartWaiter) {
+	fake.unbindServiceMutex.Lock()
+	defer fake.unbindServiceMutex.Unlock()
+	fake.UnbindServiceStub = stub
+}
+
+func (fake *FakeCfCmdGenerator) UnbindServiceArgsForCall(i int) (string, string) {
+	fake.unbindServiceMutex.RLock()
+	defer fake.unbindServiceMutex.RUnlock()
+	argsForCall := fake.unbindServiceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCfCmdGenerator) UnbindServiceReturns(result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.unbindServiceMutex.Lock()
+	defer fake.unbindServiceMutex.Unlock()
+	fake.UnbindServiceStub = nil
+	fake.unbindServiceReturns = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfCmdGenerator) UnbindServiceReturnsOnCall(i int, result1 cmdStartWaiter.CmdStartWaiter) {
+	fake.unbindServiceMutex.Lock()
+	defer fake.unbindServiceMutex.Unlock()
+	fake.UnbindServiceStub = nil
+	if fake.unbindServiceReturnsOnCall == nil {
+		fake.unbindServiceReturnsOnCall = make(map[int]struct {
+			result1 cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.unbindServiceReturnsOnCall[i] = struct {
+		result1 cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfCmdGenerator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.accessTokenMutex.RLock()
+	defer fake.accessTokenMutex.RUnlock()
 	fake.apiMutex.RLock()
 	defer fake.apiMutex.RUnlock()
+	fake.appGuidMutex.RLock()
+	defer fake.appGuidMutex.RUnlock()
 	fake.appStatsMutex.RLock()
 	defer fake.appStatsMutex.RUnlock()
 	fake.authMutex.RLock()
 	defer fake.authMutex.RUnlock()
+	fake.authClientCredentialsMutex.RLock()
+	defer fake.authClientCredentialsMutex.RUnlock()
 	fake.bindServiceMutex.RLock()
 	defer fake.bindServiceMutex.RUnlock()
 	fake.createOrgMutex.RLock()
@@ -1564,24 +2588,44 @@ func (fake *FakeCfCmdGenerator) Invocations() map[string][][]interface{} {
 	defer fake.createSpaceMutex.RUnlock()
 	fake.createUserProvidedServiceMutex.RLock()
 	defer fake.createUserProvidedServiceMutex.RUnlock()
+	fake.curlMutex.RLock()
+	defer fake.curlMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
 	fake.deleteOrgMutex.RLock()
 	defer fake.deleteOrgMutex.RUnlock()
 	fake.deleteQuotaMutex.RLock()
 	defer fake.deleteQuotaMutex.RUnlock()
+	fake.deleteServiceMutex.RLock()
+	defer fake.deleteServiceMutex.RUnlock()
 	fake.enableOrgIsolationMutex.RLock()
 	defer fake.enableOrgIsolationMutex.RUnlock()
 	fake.logOutMutex.RLock()
 	defer fake.logOutMutex.RUnlock()
 	fake.mapRouteMutex.RLock()
 	defer fake.mapRouteMutex.RUnlock()
+	fake.oauthTokenMutex.RLock()
+	defer fake.oauthTokenMutex.RUnlock()
+	fake.purgeServiceInstanceMutex.RLock()
+	defer fake.purgeServiceInstanceMutex.RUnlock()
 	fake.pushMutex.RLock()
 	defer fake.pushMutex.RUnlock()
+	fake.pushDockerImageMutex.RLock()
+	defer fake.pushDockerImageMutex.RUnlock()
+	fake.pushWithBuildpackMutex.RLock()
+	defer fake.pushWithBuildpackMutex.RUnlock()
 	fake.recentLogsMutex.RLock()
 	defer fake.recentLogsMutex.RUnlock()
+	fake.refreshTokenMutex.RLock()
+	defer fake.refreshTokenMutex.RUnlock()
 	fake.restageMutex.RLock()
 	defer fake.restageMutex.RUnlock()
+	fake.rollingPushMutex.RLock()
+	defer fake.rollingPushMutex.RUnlock()
+	fake.setAnnotationsMutex.RLock()
+	defer fake.setAnnotationsMutex.RUnlock()
+	fake.setLabelsMutex.RLock()
+	defer fake.setLabelsMutex.RUnlock()
 	fake.setOrgDefaultIsolationSegmentMutex.RLock()
 	defer fake.setOrgDefaultIsolationSegmentMutex.RUnlock()
 	fake.setQuotaMutex.RLock()
@@ -1590,6 +2634,8 @@ func (fake *FakeCfCmdGenerator) Invocations() map[string][][]interface{} {
 	defer fake.streamLogsMutex.RUnlock()
 	fake.targetMutex.RLock()
 	defer fake.targetMutex.RUnlock()
+	fake.unbindServiceMutex.RLock()
+	defer fake.unbindServiceMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
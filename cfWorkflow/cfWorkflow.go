@@ -14,32 +14,42 @@ type CfWorkflow interface {
 	Org() string
 	Space() string
 	Quota() string
+	AppName() string
 	AppUrl() string
 	TCPDomain() string
 	TCPPort() int
 
 	Setup(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	Login(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	RefreshToken(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 	Push(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	RollingDeploy(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	PushWithBuildpack(ccg cfCmdGenerator.CfCmdGenerator, buildpack, stack string) []cmdStartWaiter.CmdStartWaiter
+	PushDockerImage(ccg cfCmdGenerator.CfCmdGenerator, image, username, password string) []cmdStartWaiter.CmdStartWaiter
 	PushNoRoute(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 	Delete(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 	TearDown(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 	RecentLogs(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 	AppStats(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 	StreamLogs(context.Context, cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	AppGuid(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	OauthToken(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 
 	MapSyslogRoute(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 	MapTCPRoute(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
-	CreateAndBindSyslogDrainService(cfCmdGenerator.CfCmdGenerator, string) []cmdStartWaiter.CmdStartWaiter
+	CreateAndBindSyslogDrainService(cfCmdGenerator.CfCmdGenerator, string, string) []cmdStartWaiter.CmdStartWaiter
+	DeleteSyslogDrainService(cfCmdGenerator.CfCmdGenerator, string) []cmdStartWaiter.CmdStartWaiter
 }
 
 type cfWorkflow struct {
 	cf *config.Cf
 
-	appPath string
-	org     string
-	space   string
-	quota   string
-	appName string
+	appPath  string
+	org      string
+	space    string
+	quota    string
+	appName  string
+	metadata Metadata
 }
 
 func (c *cfWorkflow) Org() string {
@@ -54,6 +64,10 @@ func (c *cfWorkflow) Quota() string {
 	return c.quota
 }
 
+func (c *cfWorkflow) AppName() string {
+	return c.appName
+}
+
 func (c *cfWorkflow) AppUrl() string {
 	return fmt.Sprintf("https://%s.%s", c.appName, c.cf.AppDomain)
 }
@@ -66,21 +80,32 @@ func (c *cfWorkflow) TCPPort() int {
 	return c.cf.TCPPort
 }
 
-func New(cfConfig *config.Cf, org, space, quota, appName, appPath string) CfWorkflow {
+// New returns a workflow that puts metadata on the orgs, spaces, apps and
+// service instances it creates, unless metadata is empty.
+func New(cfConfig *config.Cf, org, space, quota, appName, appPath string, metadata Metadata) CfWorkflow {
 	return &cfWorkflow{
-		cf:      cfConfig,
-		appPath: appPath,
-		org:     org,
-		space:   space,
-		quota:   quota,
-		appName: appName,
+		cf:       cfConfig,
+		appPath:  appPath,
+		org:      org,
+		space:    space,
+		quota:    quota,
+		appName:  appName,
+		metadata: metadata,
 	}
 }
 
 func (c *cfWorkflow) Setup(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	if c.cf.ExistingSpace != nil {
+		return []cmdStartWaiter.CmdStartWaiter{
+			ccg.Api(c.cf.API),
+			c.auth(ccg),
+			ccg.Target(c.org, c.space),
+		}
+	}
+
 	ret := []cmdStartWaiter.CmdStartWaiter{
 		ccg.Api(c.cf.API),
-		ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword),
+		c.auth(ccg),
 		ccg.CreateOrg(c.org),
 	}
 
@@ -92,6 +117,12 @@ func (c *cfWorkflow) Setup(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.C
 	}
 	ret = append(ret, ccg.CreateSpace(c.org, c.space))
 
+	if c.hasMetadata() {
+		ret = append(ret, ccg.Target(c.org, c.space))
+		ret = append(ret, c.setMetadata(ccg, "org", c.org)...)
+		ret = append(ret, c.setMetadata(ccg, "space", c.space)...)
+	}
+
 	if c.quota != "" {
 		ret = append(ret, ccg.CreateQuota(c.quota), ccg.SetQuota(c.org, c.quota))
 	}
@@ -99,18 +130,32 @@ func (c *cfWorkflow) Setup(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.C
 	return ret
 }
 
+// Login authenticates the CF_HOME of ccg, so that the commands of the other
+// steps can run in it without logging in again.
+func (c *cfWorkflow) Login(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{
+		ccg.Api(c.cf.API),
+		c.auth(ccg),
+	}
+}
+
+// RefreshToken renews the expired access token of the CF_HOME of ccg.
+func (c *cfWorkflow) RefreshToken(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{
+		ccg.RefreshToken(),
+	}
+}
+
 func (c *cfWorkflow) Push(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
 	appInstancesToPush := 2
 	if c.cf.UseSingleAppInstance {
 		appInstancesToPush = 1
 	}
 
-	return []cmdStartWaiter.CmdStartWaiter{
-		ccg.Api(c.cf.API),
-		ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword),
+	return c.labelApp(ccg, []cmdStartWaiter.CmdStartWaiter{
 		ccg.Target(c.org, c.space),
 		ccg.Push(c.appName, c.appPath, appInstancesToPush, false),
-	}
+	})
 }
 
 func (c *cfWorkflow) PushNoRoute(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
@@ -119,26 +164,70 @@ func (c *cfWorkflow) PushNoRoute(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWa
 		appInstancesToPush = 1
 	}
 
-	return []cmdStartWaiter.CmdStartWaiter{
-		ccg.Api(c.cf.API),
-		ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword),
+	return c.labelApp(ccg, []cmdStartWaiter.CmdStartWaiter{
 		ccg.Target(c.org, c.space),
 		ccg.Push(c.appName, c.appPath, appInstancesToPush, true),
+	})
+}
+func (c *cfWorkflow) RollingDeploy(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	appInstancesToPush := 2
+	if c.cf.UseSingleAppInstance {
+		appInstancesToPush = 1
+	}
+
+	return []cmdStartWaiter.CmdStartWaiter{
+		ccg.Target(c.org, c.space),
+		ccg.RollingPush(c.appName, c.appPath, appInstancesToPush),
+	}
+}
+
+func (c *cfWorkflow) PushWithBuildpack(ccg cfCmdGenerator.CfCmdGenerator, buildpack, stack string) []cmdStartWaiter.CmdStartWaiter {
+	appInstancesToPush := 2
+	if c.cf.UseSingleAppInstance {
+		appInstancesToPush = 1
 	}
+
+	return c.labelApp(ccg, []cmdStartWaiter.CmdStartWaiter{
+		ccg.Target(c.org, c.space),
+		ccg.PushWithBuildpack(c.appName, c.appPath, buildpack, stack, appInstancesToPush),
+	})
 }
+
+func (c *cfWorkflow) PushDockerImage(ccg cfCmdGenerator.CfCmdGenerator, image, username, password string) []cmdStartWaiter.CmdStartWaiter {
+	appInstancesToPush := 2
+	if c.cf.UseSingleAppInstance {
+		appInstancesToPush = 1
+	}
+
+	return c.labelApp(ccg, []cmdStartWaiter.CmdStartWaiter{
+		ccg.Target(c.org, c.space),
+		ccg.PushDockerImage(c.appName, image, username, password, appInstancesToPush),
+	})
+}
+
 func (c *cfWorkflow) Delete(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
 	return []cmdStartWaiter.CmdStartWaiter{
-		ccg.Api(c.cf.API),
-		ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword),
 		ccg.Target(c.org, c.space),
 		ccg.Delete(c.appName),
 	}
 }
 
+// TearDown deletes the org uptimer created, or only the app when running in an
+// existing space.
 func (c *cfWorkflow) TearDown(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	if c.cf.ExistingSpace != nil {
+		return []cmdStartWaiter.CmdStartWaiter{
+			ccg.Api(c.cf.API),
+			c.auth(ccg),
+			ccg.Target(c.org, c.space),
+			ccg.Delete(c.appName),
+			ccg.LogOut(),
+		}
+	}
+
 	ret := []cmdStartWaiter.CmdStartWaiter{
 		ccg.Api(c.cf.API),
-		ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword),
+		c.auth(ccg),
 		ccg.DeleteOrg(c.org),
 	}
 
@@ -152,8 +241,6 @@ func (c *cfWorkflow) TearDown(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaite
 
 func (c *cfWorkflow) AppStats(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
 	return []cmdStartWaiter.CmdStartWaiter{
-		ccg.Api(c.cf.API),
-		ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword),
 		ccg.Target(c.org, c.space),
 		ccg.AppStats(c.appName),
 	}
@@ -161,8 +248,6 @@ func (c *cfWorkflow) AppStats(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaite
 
 func (c *cfWorkflow) RecentLogs(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
 	return []cmdStartWaiter.CmdStartWaiter{
-		ccg.Api(c.cf.API),
-		ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword),
 		ccg.Target(c.org, c.space),
 		ccg.RecentLogs(c.appName),
 	}
@@ -170,17 +255,26 @@ func (c *cfWorkflow) RecentLogs(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWai
 
 func (c *cfWorkflow) StreamLogs(ctx context.Context, ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
 	return []cmdStartWaiter.CmdStartWaiter{
-		ccg.Api(c.cf.API),
-		ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword),
 		ccg.Target(c.org, c.space),
 		ccg.StreamLogs(ctx, c.appName),
 	}
 }
 
+func (c *cfWorkflow) AppGuid(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{
+		ccg.Target(c.org, c.space),
+		ccg.AppGuid(c.appName),
+	}
+}
+
+func (c *cfWorkflow) OauthToken(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{
+		ccg.OauthToken(),
+	}
+}
+
 func (c *cfWorkflow) MapSyslogRoute(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
 	return []cmdStartWaiter.CmdStartWaiter{
-		ccg.Api(c.cf.API),
-		ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword),
 		ccg.Target(c.org, c.space),
 		ccg.MapRoute(c.appName, c.cf.TCPDomain, c.cf.AvailablePort),
 	}
@@ -188,20 +282,81 @@ func (c *cfWorkflow) MapSyslogRoute(ccg cfCmdGenerator.CfCmdGenerator) []cmdStar
 
 func (c *cfWorkflow) MapTCPRoute(ccg cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
 	return []cmdStartWaiter.CmdStartWaiter{
-		ccg.Api(c.cf.API),
-		ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword),
 		ccg.Target(c.org, c.space),
 		ccg.MapRoute(c.appName, c.cf.TCPDomain, c.cf.TCPPort),
 	}
 }
 
-func (c *cfWorkflow) CreateAndBindSyslogDrainService(ccg cfCmdGenerator.CfCmdGenerator, serviceName string) []cmdStartWaiter.CmdStartWaiter {
-	return []cmdStartWaiter.CmdStartWaiter{
-		ccg.Api(c.cf.API),
-		ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword),
+func (c *cfWorkflow) CreateAndBindSyslogDrainService(ccg cfCmdGenerator.CfCmdGenerator, serviceName, scheme string) []cmdStartWaiter.CmdStartWaiter {
+	ret := []cmdStartWaiter.CmdStartWaiter{
 		ccg.Target(c.org, c.space),
-		ccg.CreateUserProvidedService(serviceName, fmt.Sprintf("syslog://%s:%d", c.cf.TCPDomain, c.cf.AvailablePort)),
+		ccg.CreateUserProvidedService(serviceName, c.syslogDrainUrl(scheme)),
+	}
+
+	ret = append(ret, c.setMetadata(ccg, "service-instance", serviceName)...)
+
+	return append(ret,
 		ccg.BindService(c.appName, serviceName),
 		ccg.Restage(c.appName),
+	)
+}
+
+// DeleteSyslogDrainService removes the syslog drain service from an existing
+// space. Deleting the org uptimer created removes it already, so there is
+// nothing to do otherwise.
+func (c *cfWorkflow) DeleteSyslogDrainService(ccg cfCmdGenerator.CfCmdGenerator, serviceName string) []cmdStartWaiter.CmdStartWaiter {
+	if c.cf.ExistingSpace == nil {
+		return nil
+	}
+
+	return []cmdStartWaiter.CmdStartWaiter{
+		ccg.Target(c.org, c.space),
+		ccg.UnbindService(c.appName, serviceName),
+		ccg.DeleteService(serviceName),
+	}
+}
+
+// labelApp adds putting metadata on the pushed app to the commands pushing
+// it.
+func (c *cfWorkflow) labelApp(ccg cfCmdGenerator.CfCmdGenerator, cmds []cmdStartWaiter.CmdStartWaiter) []cmdStartWaiter.CmdStartWaiter {
+	return append(cmds, c.setMetadata(ccg, "app", c.appName)...)
+}
+
+func (c *cfWorkflow) hasMetadata() bool {
+	return len(c.metadata.Labels) > 0 || len(c.metadata.Annotations) > 0
+}
+
+// setMetadata returns the commands putting the workflow's labels and
+// annotations on a resource, if it has any.
+func (c *cfWorkflow) setMetadata(ccg cfCmdGenerator.CfCmdGenerator, resourceType, name string) []cmdStartWaiter.CmdStartWaiter {
+	var cmds []cmdStartWaiter.CmdStartWaiter
+	if len(c.metadata.Labels) > 0 {
+		cmds = append(cmds, ccg.SetLabels(resourceType, name, c.metadata.Labels))
+	}
+	if len(c.metadata.Annotations) > 0 {
+		cmds = append(cmds, ccg.SetAnnotations(resourceType, name, c.metadata.Annotations))
+	}
+
+	return cmds
+}
+
+// auth authenticates with the configured client credentials, or else as the
+// admin user.
+func (c *cfWorkflow) auth(ccg cfCmdGenerator.CfCmdGenerator) cmdStartWaiter.CmdStartWaiter {
+	if c.cf.UsesClientCredentials() {
+		return ccg.AuthClientCredentials(c.cf.ClientID, c.cf.ClientSecret)
+	}
+
+	return ccg.Auth(c.cf.AdminUser, c.cf.AdminPassword)
+}
+
+func (c *cfWorkflow) syslogDrainUrl(scheme string) string {
+	switch scheme {
+	case "https":
+		return fmt.Sprintf("https://%s:%d/drain", c.cf.TCPDomain, c.cf.AvailablePort)
+	case "syslog-tls":
+		return fmt.Sprintf("syslog-tls://%s:%d", c.cf.TCPDomain, c.cf.AvailablePort)
+	default:
+		return fmt.Sprintf("syslog://%s:%d", c.cf.TCPDomain, c.cf.AvailablePort)
 	}
 }
@@ -3,6 +3,7 @@ package cfWorkflow_test
 import (
 	"context"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
@@ -17,13 +18,14 @@ import (
 
 var _ = Describe("CfWorkflow", func() {
 	var (
-		cfc     *config.Cf
-		ccg     cfCmdGenerator.CfCmdGenerator
-		org     string
-		space   string
-		quota   string
-		appName string
-		appPath string
+		cfc      *config.Cf
+		ccg      cfCmdGenerator.CfCmdGenerator
+		org      string
+		space    string
+		quota    string
+		appName  string
+		appPath  string
+		metadata Metadata
 
 		cw CfWorkflow
 	)
@@ -46,10 +48,11 @@ var _ = Describe("CfWorkflow", func() {
 		quota = "someQuota"
 		appName = "doraApp"
 		appPath = "this/is/an/app/path"
+		metadata = Metadata{}
 	})
 
 	JustBeforeEach(func() {
-		cw = New(cfc, org, space, quota, appName, appPath)
+		cw = New(cfc, org, space, quota, appName, appPath, metadata)
 	})
 
 	Describe("Org", func() {
@@ -70,6 +73,12 @@ var _ = Describe("CfWorkflow", func() {
 		})
 	})
 
+	Describe("AppName", func() {
+		It("returns the correct app name", func() {
+			Expect(cw.AppName()).To(Equal(appName))
+		})
+	})
+
 	Describe("AppUrl", func() {
 		It("returns the correct app url", func() {
 			Expect(cw.AppUrl()).To(Equal("https://doraApp.app.jigglypuff.cf-app.com"))
@@ -92,14 +101,34 @@ var _ = Describe("CfWorkflow", func() {
 
 			Expect(cmds).To(Equal(
 				[]cmdStartWaiter.CmdStartWaiter{
-					ccg.Api("jigglypuff.cf-app.com"),
-					ccg.Auth("pika", "chu"),
 					ccg.Target("someOrg", "someSpace"),
 					ccg.Push("doraApp", "this/is/an/app/path", 2, false),
 				},
 			))
 		})
 
+		Context("when metadata is given", func() {
+			BeforeEach(func() {
+				metadata = Metadata{
+					Labels:      map[string]string{"some-label": "some-value"},
+					Annotations: map[string]string{"some-annotation": "some value"},
+				}
+			})
+
+			It("labels and annotates the app after pushing it", func() {
+				cmds := cw.Push(ccg)
+
+				Expect(cmds).To(Equal(
+					[]cmdStartWaiter.CmdStartWaiter{
+						ccg.Target("someOrg", "someSpace"),
+						ccg.Push("doraApp", "this/is/an/app/path", 2, false),
+						ccg.SetLabels("app", "doraApp", metadata.Labels),
+						ccg.SetAnnotations("app", "doraApp", metadata.Annotations),
+					},
+				))
+			})
+		})
+
 		Context("when the UseSingleAppInstance flag is used", func() {
 			BeforeEach(func() {
 				cfc.UseSingleAppInstance = true
@@ -110,8 +139,6 @@ var _ = Describe("CfWorkflow", func() {
 
 				Expect(cmds).To(Equal(
 					[]cmdStartWaiter.CmdStartWaiter{
-						ccg.Api("jigglypuff.cf-app.com"),
-						ccg.Auth("pika", "chu"),
 						ccg.Target("someOrg", "someSpace"),
 						ccg.Push("doraApp", "this/is/an/app/path", 1, false),
 					},
@@ -119,14 +146,87 @@ var _ = Describe("CfWorkflow", func() {
 			})
 		})
 	})
+	Describe("RollingDeploy", func() {
+		It("returns a series of commands to deploy a new revision of the app with a rolling strategy", func() {
+			cmds := cw.RollingDeploy(ccg)
+
+			Expect(cmds).To(Equal(
+				[]cmdStartWaiter.CmdStartWaiter{
+					ccg.Target("someOrg", "someSpace"),
+					ccg.RollingPush("doraApp", "this/is/an/app/path", 2),
+				},
+			))
+		})
+
+		Context("when the UseSingleAppInstance flag is used", func() {
+			BeforeEach(func() {
+				cfc.UseSingleAppInstance = true
+			})
+
+			It("deploys a single instance", func() {
+				cmds := cw.RollingDeploy(ccg)
+
+				Expect(cmds).To(ContainElement(ccg.RollingPush("doraApp", "this/is/an/app/path", 1)))
+			})
+		})
+	})
+
+	Describe("PushWithBuildpack", func() {
+		It("returns a series of commands to push the app with the buildpack and stack", func() {
+			cmds := cw.PushWithBuildpack(ccg, "java_buildpack", "cflinuxfs4")
+
+			Expect(cmds).To(Equal(
+				[]cmdStartWaiter.CmdStartWaiter{
+					ccg.Target("someOrg", "someSpace"),
+					ccg.PushWithBuildpack("doraApp", "this/is/an/app/path", "java_buildpack", "cflinuxfs4", 2),
+				},
+			))
+		})
+
+		Context("when the UseSingleAppInstance flag is used", func() {
+			BeforeEach(func() {
+				cfc.UseSingleAppInstance = true
+			})
+
+			It("pushes a single instance", func() {
+				cmds := cw.PushWithBuildpack(ccg, "java_buildpack", "")
+
+				Expect(cmds).To(ContainElement(ccg.PushWithBuildpack("doraApp", "this/is/an/app/path", "java_buildpack", "", 1)))
+			})
+		})
+	})
+
+	Describe("PushDockerImage", func() {
+		It("returns a series of commands to push the docker image as the app", func() {
+			cmds := cw.PushDockerImage(ccg, "some/image", "someUser", "somePassword")
+
+			Expect(cmds).To(Equal(
+				[]cmdStartWaiter.CmdStartWaiter{
+					ccg.Target("someOrg", "someSpace"),
+					ccg.PushDockerImage("doraApp", "some/image", "someUser", "somePassword", 2),
+				},
+			))
+		})
+
+		Context("when the UseSingleAppInstance flag is used", func() {
+			BeforeEach(func() {
+				cfc.UseSingleAppInstance = true
+			})
+
+			It("pushes a single instance", func() {
+				cmds := cw.PushDockerImage(ccg, "some/image", "", "")
+
+				Expect(cmds).To(ContainElement(ccg.PushDockerImage("doraApp", "some/image", "", "", 1)))
+			})
+		})
+	})
+
 	Describe("PushNoRoute", func() {
 		It("calls the push command with the noStart flag as true", func() {
 			cmds := cw.PushNoRoute(ccg)
 
 			Expect(cmds).To(Equal(
 				[]cmdStartWaiter.CmdStartWaiter{
-					ccg.Api("jigglypuff.cf-app.com"),
-					ccg.Auth("pika", "chu"),
 					ccg.Target("someOrg", "someSpace"),
 					ccg.Push("doraApp", "this/is/an/app/path", 2, true),
 				},
@@ -140,8 +240,6 @@ var _ = Describe("CfWorkflow", func() {
 
 			Expect(cmds).To(Equal(
 				[]cmdStartWaiter.CmdStartWaiter{
-					ccg.Api("jigglypuff.cf-app.com"),
-					ccg.Auth("pika", "chu"),
 					ccg.Target("someOrg", "someSpace"),
 					ccg.Delete("doraApp"),
 				},
@@ -199,6 +297,94 @@ var _ = Describe("CfWorkflow", func() {
 				))
 			})
 		})
+		When("metadata is given", func() {
+			BeforeEach(func() {
+				metadata = Metadata{
+					Labels:      map[string]string{"some-label": "some-value"},
+					Annotations: map[string]string{"some-annotation": "some value"},
+				}
+			})
+
+			It("labels and annotates the org and space after creating them", func() {
+				cmds := cw.Setup(ccg)
+
+				Expect(cmds).To(Equal(
+					[]cmdStartWaiter.CmdStartWaiter{
+						ccg.Api("jigglypuff.cf-app.com"),
+						ccg.Auth("pika", "chu"),
+						ccg.CreateOrg("someOrg"),
+						ccg.CreateSpace("someOrg", "someSpace"),
+						ccg.Target("someOrg", "someSpace"),
+						ccg.SetLabels("org", "someOrg", metadata.Labels),
+						ccg.SetAnnotations("org", "someOrg", metadata.Annotations),
+						ccg.SetLabels("space", "someSpace", metadata.Labels),
+						ccg.SetAnnotations("space", "someSpace", metadata.Annotations),
+						ccg.CreateQuota("someQuota"),
+						ccg.SetQuota("someOrg", "someQuota"),
+					},
+				))
+			})
+		})
+		When("an existing space is configured", func() {
+			BeforeEach(func() {
+				cfc.ExistingSpace = &config.ExistingSpace{Org: "someOrg", Space: "someSpace"}
+			})
+
+			It("returns a series of commands that only target the space", func() {
+				cmds := cw.Setup(ccg)
+
+				Expect(cmds).To(Equal(
+					[]cmdStartWaiter.CmdStartWaiter{
+						ccg.Api("jigglypuff.cf-app.com"),
+						ccg.Auth("pika", "chu"),
+						ccg.Target("someOrg", "someSpace"),
+					},
+				))
+			})
+		})
+	})
+
+	Describe("Login", func() {
+		It("returns a set of commands to log in to the CF API", func() {
+			cmds := cw.Login(ccg)
+
+			Expect(cmds).To(Equal(
+				[]cmdStartWaiter.CmdStartWaiter{
+					ccg.Api("jigglypuff.cf-app.com"),
+					ccg.Auth("pika", "chu"),
+				},
+			))
+		})
+
+		Context("when client credentials are configured", func() {
+			BeforeEach(func() {
+				cfc.AdminUser, cfc.AdminPassword = "", ""
+				cfc.ClientID, cfc.ClientSecret = "uptimer", "secret"
+			})
+
+			It("returns a set of commands to log in as the client", func() {
+				cmds := cw.Login(ccg)
+
+				Expect(cmds).To(Equal(
+					[]cmdStartWaiter.CmdStartWaiter{
+						ccg.Api("jigglypuff.cf-app.com"),
+						ccg.AuthClientCredentials("uptimer", "secret"),
+					},
+				))
+			})
+		})
+	})
+
+	Describe("RefreshToken", func() {
+		It("returns a set of commands to refresh the access token", func() {
+			cmds := cw.RefreshToken(ccg)
+
+			Expect(cmds).To(Equal(
+				[]cmdStartWaiter.CmdStartWaiter{
+					ccg.RefreshToken(),
+				},
+			))
+		})
 	})
 
 	Describe("TearDown", func() {
@@ -230,6 +416,25 @@ var _ = Describe("CfWorkflow", func() {
 				))
 			})
 		})
+		When("an existing space is configured", func() {
+			BeforeEach(func() {
+				cfc.ExistingSpace = &config.ExistingSpace{Org: "someOrg", Space: "someSpace"}
+			})
+
+			It("returns a set of commands to delete only the app", func() {
+				cmds := cw.TearDown(ccg)
+
+				Expect(cmds).To(Equal(
+					[]cmdStartWaiter.CmdStartWaiter{
+						ccg.Api("jigglypuff.cf-app.com"),
+						ccg.Auth("pika", "chu"),
+						ccg.Target("someOrg", "someSpace"),
+						ccg.Delete("doraApp"),
+						ccg.LogOut(),
+					},
+				))
+			})
+		})
 	})
 
 	Describe("AppStats", func() {
@@ -238,8 +443,6 @@ var _ = Describe("CfWorkflow", func() {
 
 			Expect(cmds).To(Equal(
 				[]cmdStartWaiter.CmdStartWaiter{
-					ccg.Api("jigglypuff.cf-app.com"),
-					ccg.Auth("pika", "chu"),
 					ccg.Target("someOrg", "someSpace"),
 					ccg.AppStats("doraApp"),
 				},
@@ -253,8 +456,6 @@ var _ = Describe("CfWorkflow", func() {
 
 			Expect(cmds).To(Equal(
 				[]cmdStartWaiter.CmdStartWaiter{
-					ccg.Api("jigglypuff.cf-app.com"),
-					ccg.Auth("pika", "chu"),
 					ccg.Target("someOrg", "someSpace"),
 					ccg.RecentLogs("doraApp"),
 				},
@@ -270,8 +471,6 @@ var _ = Describe("CfWorkflow", func() {
 
 			Expect(cmds).To(BeComparableTo(
 				[]cmdStartWaiter.CmdStartWaiter{
-					ccg.Api("jigglypuff.cf-app.com"),
-					ccg.Auth("pika", "chu"),
 					ccg.Target("someOrg", "someSpace"),
 					ccg.StreamLogs(ctx, "doraApp"),
 				},
@@ -281,14 +480,37 @@ var _ = Describe("CfWorkflow", func() {
 		})
 	})
 
+	Describe("AppGuid", func() {
+		It("returns a set of commands to get the guid of an app", func() {
+			cmds := cw.AppGuid(ccg)
+
+			Expect(cmds).To(Equal(
+				[]cmdStartWaiter.CmdStartWaiter{
+					ccg.Target("someOrg", "someSpace"),
+					ccg.AppGuid("doraApp"),
+				},
+			))
+		})
+	})
+
+	Describe("OauthToken", func() {
+		It("returns a set of commands to get an oauth token", func() {
+			cmds := cw.OauthToken(ccg)
+
+			Expect(cmds).To(Equal(
+				[]cmdStartWaiter.CmdStartWaiter{
+					ccg.OauthToken(),
+				},
+			))
+		})
+	})
+
 	Describe("MapTCPRoute", func() {
 		It("returns a set of commands to map a route to a tcp app", func() {
 			cmds := cw.MapTCPRoute(ccg)
 
 			Expect(cmds).To(Equal(
 				[]cmdStartWaiter.CmdStartWaiter{
-					ccg.Api("jigglypuff.cf-app.com"),
-					ccg.Auth("pika", "chu"),
 					ccg.Target("someOrg", "someSpace"),
 					ccg.MapRoute("doraApp", "tcp.jigglypuff.cf-app.com", 1026),
 				},
@@ -301,8 +523,6 @@ var _ = Describe("CfWorkflow", func() {
 
 			Expect(cmds).To(Equal(
 				[]cmdStartWaiter.CmdStartWaiter{
-					ccg.Api("jigglypuff.cf-app.com"),
-					ccg.Auth("pika", "chu"),
 					ccg.Target("someOrg", "someSpace"),
 					ccg.MapRoute("doraApp", "tcp.jigglypuff.cf-app.com", 1025),
 				},
@@ -312,18 +532,96 @@ var _ = Describe("CfWorkflow", func() {
 
 	Describe("CreateAndBindSyslogDrainService", func() {
 		It("Creates and binds a user-provided syslog drain service to an app and restages the app", func() {
-			cmds := cw.CreateAndBindSyslogDrainService(ccg, "syslogUPS")
+			cmds := cw.CreateAndBindSyslogDrainService(ccg, "syslogUPS", "")
+
+			Expect(cmds).To(Equal(
+				[]cmdStartWaiter.CmdStartWaiter{
+					ccg.Target("someOrg", "someSpace"),
+					ccg.CreateUserProvidedService("syslogUPS", "syslog://tcp.jigglypuff.cf-app.com:1025"),
+					ccg.BindService("doraApp", "syslogUPS"),
+					ccg.Restage("doraApp"),
+				},
+			))
+		})
+
+		It("labels the service when labels are given", func() {
+			metadata = Metadata{Labels: map[string]string{"some-label": "some-value"}}
+			cw = New(cfc, org, space, quota, appName, appPath, metadata)
+
+			cmds := cw.CreateAndBindSyslogDrainService(ccg, "syslogUPS", "")
 
 			Expect(cmds).To(Equal(
 				[]cmdStartWaiter.CmdStartWaiter{
-					ccg.Api("jigglypuff.cf-app.com"),
-					ccg.Auth("pika", "chu"),
 					ccg.Target("someOrg", "someSpace"),
 					ccg.CreateUserProvidedService("syslogUPS", "syslog://tcp.jigglypuff.cf-app.com:1025"),
+					ccg.SetLabels("service-instance", "syslogUPS", metadata.Labels),
 					ccg.BindService("doraApp", "syslogUPS"),
 					ccg.Restage("doraApp"),
 				},
 			))
 		})
+
+		It("creates a syslog-tls drain when asked to", func() {
+			cmds := cw.CreateAndBindSyslogDrainService(ccg, "syslogUPS", "syslog-tls")
+
+			Expect(cmds[1]).To(Equal(
+				ccg.CreateUserProvidedService("syslogUPS", "syslog-tls://tcp.jigglypuff.cf-app.com:1025"),
+			))
+		})
+
+		It("creates an https drain when asked to", func() {
+			cmds := cw.CreateAndBindSyslogDrainService(ccg, "syslogUPS", "https")
+
+			Expect(cmds[1]).To(Equal(
+				ccg.CreateUserProvidedService("syslogUPS", "https://tcp.jigglypuff.cf-app.com:1025/drain"),
+			))
+		})
+	})
+
+	Describe("DeleteSyslogDrainService", func() {
+		It("returns no commands, as deleting the org deletes the service", func() {
+			Expect(cw.DeleteSyslogDrainService(ccg, "syslogUPS")).To(BeEmpty())
+		})
+
+		When("an existing space is configured", func() {
+			BeforeEach(func() {
+				cfc.ExistingSpace = &config.ExistingSpace{Org: "someOrg", Space: "someSpace"}
+			})
+
+			It("returns a set of commands to unbind and delete the service", func() {
+				cmds := cw.DeleteSyslogDrainService(ccg, "syslogUPS")
+
+				Expect(cmds).To(Equal(
+					[]cmdStartWaiter.CmdStartWaiter{
+						ccg.Target("someOrg", "someSpace"),
+						ccg.UnbindService("doraApp", "syslogUPS"),
+						ccg.DeleteService("syslogUPS"),
+					},
+				))
+			})
+		})
+	})
+})
+
+var _ = Describe("RunMetadata", func() {
+	It("labels the run with its ID and annotates it with the rest", func() {
+		startedAt := time.Date(2024, 5, 1, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+		Expect(RunMetadata("some-run-id", "v1.2.3+dev", startedAt, "_ci-worker.example.com_")).To(Equal(Metadata{
+			Labels: map[string]string{
+				RunIDLabel: "some-run-id",
+			},
+			Annotations: map[string]string{
+				VersionAnnotation:   "v1.2.3+dev",
+				StartedAtAnnotation: "2024-05-01T12:00:00Z",
+				HostAnnotation:      "_ci-worker.example.com_",
+			},
+		}))
+	})
+
+	It("makes the run ID fit the rules for label values", func() {
+		runID := "_" + strings.Repeat("a", 62) + "+b"
+
+		Expect(RunMetadata(runID, "dev", time.Now(), "host").Labels[RunIDLabel]).To(Equal(strings.Repeat("a", 62)))
 	})
 })
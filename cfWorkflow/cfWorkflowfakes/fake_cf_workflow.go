@@ -11,6 +11,27 @@ import (
 )
 
 type FakeCfWorkflow struct {
+	AppGuidStub        func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	appGuidMutex       sync.RWMutex
+	appGuidArgsForCall []struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+	}
+	appGuidReturns struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
+	appGuidReturnsOnCall map[int]struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
+	AppNameStub        func() string
+	appNameMutex       sync.RWMutex
+	appNameArgsForCall []struct {
+	}
+	appNameReturns struct {
+		result1 string
+	}
+	appNameReturnsOnCall map[int]struct {
+		result1 string
+	}
 	AppStatsStub        func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 	appStatsMutex       sync.RWMutex
 	appStatsArgsForCall []struct {
@@ -32,11 +53,12 @@ type FakeCfWorkflow struct {
 	appUrlReturnsOnCall map[int]struct {
 		result1 string
 	}
-	CreateAndBindSyslogDrainServiceStub        func(cfCmdGenerator.CfCmdGenerator, string) []cmdStartWaiter.CmdStartWaiter
+	CreateAndBindSyslogDrainServiceStub        func(cfCmdGenerator.CfCmdGenerator, string, string) []cmdStartWaiter.CmdStartWaiter
 	createAndBindSyslogDrainServiceMutex       sync.RWMutex
 	createAndBindSyslogDrainServiceArgsForCall []struct {
 		arg1 cfCmdGenerator.CfCmdGenerator
 		arg2 string
+		arg3 string
 	}
 	createAndBindSyslogDrainServiceReturns struct {
 		result1 []cmdStartWaiter.CmdStartWaiter
@@ -55,6 +77,29 @@ type FakeCfWorkflow struct {
 	deleteReturnsOnCall map[int]struct {
 		result1 []cmdStartWaiter.CmdStartWaiter
 	}
+	DeleteSyslogDrainServiceStub        func(cfCmdGenerator.CfCmdGenerator, string) []cmdStartWaiter.CmdStartWaiter
+	deleteSyslogDrainServiceMutex       sync.RWMutex
+	deleteSyslogDrainServiceArgsForCall []struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+		arg2 string
+	}
+	deleteSyslogDrainServiceReturns struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
+	deleteSyslogDrainServiceReturnsOnCall map[int]struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
+	LoginStub        func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	loginMutex       sync.RWMutex
+	loginArgsForCall []struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+	}
+	loginReturns struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
+	loginReturnsOnCall map[int]struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
 	MapSyslogRouteStub        func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 	mapSyslogRouteMutex       sync.RWMutex
 	mapSyslogRouteArgsForCall []struct {
@@ -77,6 +122,17 @@ type FakeCfWorkflow struct {
 	mapTCPRouteReturnsOnCall map[int]struct {
 		result1 []cmdStartWaiter.CmdStartWaiter
 	}
+	OauthTokenStub        func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	oauthTokenMutex       sync.RWMutex
+	oauthTokenArgsForCall []struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+	}
+	oauthTokenReturns struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
+	oauthTokenReturnsOnCall map[int]struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
 	OrgStub        func() string
 	orgMutex       sync.RWMutex
 	orgArgsForCall []struct {
@@ -98,6 +154,20 @@ type FakeCfWorkflow struct {
 	pushReturnsOnCall map[int]struct {
 		result1 []cmdStartWaiter.CmdStartWaiter
 	}
+	PushDockerImageStub        func(cfCmdGenerator.CfCmdGenerator, string, string, string) []cmdStartWaiter.CmdStartWaiter
+	pushDockerImageMutex       sync.RWMutex
+	pushDockerImageArgsForCall []struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+		arg2 string
+		arg3 string
+		arg4 string
+	}
+	pushDockerImageReturns struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
+	pushDockerImageReturnsOnCall map[int]struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
 	PushNoRouteStub        func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 	pushNoRouteMutex       sync.RWMutex
 	pushNoRouteArgsForCall []struct {
@@ -109,6 +179,19 @@ type FakeCfWorkflow struct {
 	pushNoRouteReturnsOnCall map[int]struct {
 		result1 []cmdStartWaiter.CmdStartWaiter
 	}
+	PushWithBuildpackStub        func(cfCmdGenerator.CfCmdGenerator, string, string) []cmdStartWaiter.CmdStartWaiter
+	pushWithBuildpackMutex       sync.RWMutex
+	pushWithBuildpackArgsForCall []struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+		arg2 string
+		arg3 string
+	}
+	pushWithBuildpackReturns struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
+	pushWithBuildpackReturnsOnCall map[int]struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
 	QuotaStub        func() string
 	quotaMutex       sync.RWMutex
 	quotaArgsForCall []struct {
@@ -130,6 +213,28 @@ type FakeCfWorkflow struct {
 	recentLogsReturnsOnCall map[int]struct {
 		result1 []cmdStartWaiter.CmdStartWaiter
 	}
+	RefreshTokenStub        func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	refreshTokenMutex       sync.RWMutex
+	refreshTokenArgsForCall []struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+	}
+	refreshTokenReturns struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
+	refreshTokenReturnsOnCall map[int]struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
+	RollingDeployStub        func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	rollingDeployMutex       sync.RWMutex
+	rollingDeployArgsForCall []struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+	}
+	rollingDeployReturns struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
+	rollingDeployReturnsOnCall map[int]struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}
 	SetupStub        func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
 	setupMutex       sync.RWMutex
 	setupArgsForCall []struct {
@@ -198,6 +303,120 @@ type FakeCfWorkflow struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeCfWorkflow) AppGuid(arg1 cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	fake.appGuidMutex.Lock()
+	ret, specificReturn := fake.appGuidReturnsOnCall[len(fake.appGuidArgsForCall)]
+	fake.appGuidArgsForCall = append(fake.appGuidArgsForCall, struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+	}{arg1})
+	stub := fake.AppGuidStub
+	fakeReturns := fake.appGuidReturns
+	fake.recordInvocation("AppGuid", []interface{}{arg1})
+	fake.appGuidMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfWorkflow) AppGuidCallCount() int {
+	fake.appGuidMutex.RLock()
+	defer fake.appGuidMutex.RUnlock()
+	return len(fake.appGuidArgsForCall)
+}
+
+func (fake *FakeCfWorkflow) AppGuidCalls(stub func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter) {
+	fake.appGuidMutex.Lock()
+	defer fake.appGuidMutex.Unlock()
+	fake.AppGuidStub = stub
+}
+
+func (fake *FakeCfWorkflow) AppGuidArgsForCall(i int) cfCmdGenerator.CfCmdGenerator {
+	fake.appGuidMutex.RLock()
+	defer fake.appGuidMutex.RUnlock()
+	argsForCall := fake.appGuidArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCfWorkflow) AppGuidReturns(result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.appGuidMutex.Lock()
+	defer fake.appGuidMutex.Unlock()
+	fake.AppGuidStub = nil
+	fake.appGuidReturns = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) AppGuidReturnsOnCall(i int, result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.appGuidMutex.Lock()
+	defer fake.appGuidMutex.Unlock()
+	fake.AppGuidStub = nil
+	if fake.appGuidReturnsOnCall == nil {
+		fake.appGuidReturnsOnCall = make(map[int]struct {
+			result1 []cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.appGuidReturnsOnCall[i] = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) AppName() string {
+	fake.appNameMutex.Lock()
+	ret, specificReturn := fake.appNameReturnsOnCall[len(fake.appNameArgsForCall)]
+	fake.appNameArgsForCall = append(fake.appNameArgsForCall, struct {
+	}{})
+	stub := fake.AppNameStub
+	fakeReturns := fake.appNameReturns
+	fake.recordInvocation("AppName", []interface{}{})
+	fake.appNameMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfWorkflow) AppNameCallCount() int {
+	fake.appNameMutex.RLock()
+	defer fake.appNameMutex.RUnlock()
+	return len(fake.appNameArgsForCall)
+}
+
+func (fake *FakeCfWorkflow) AppNameCalls(stub func() string) {
+	fake.appNameMutex.Lock()
+	defer fake.appNameMutex.Unlock()
+	fake.AppNameStub = stub
+}
+
+func (fake *FakeCfWorkflow) AppNameReturns(result1 string) {
+	fake.appNameMutex.Lock()
+	defer fake.appNameMutex.Unlock()
+	fake.AppNameStub = nil
+	fake.appNameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) AppNameReturnsOnCall(i int, result1 string) {
+	fake.appNameMutex.Lock()
+	defer fake.appNameMutex.Unlock()
+	fake.AppNameStub = nil
+	if fake.appNameReturnsOnCall == nil {
+		fake.appNameReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.appNameReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
 func (fake *FakeCfWorkflow) AppStats(arg1 cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
 	fake.appStatsMutex.Lock()
 	ret, specificReturn := fake.appStatsReturnsOnCall[len(fake.appStatsArgsForCall)]
@@ -312,19 +531,20 @@ func (fake *FakeCfWorkflow) AppUrlReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
-func (fake *FakeCfWorkflow) CreateAndBindSyslogDrainService(arg1 cfCmdGenerator.CfCmdGenerator, arg2 string) []cmdStartWaiter.CmdStartWaiter {
+func (fake *FakeCfWorkflow) CreateAndBindSyslogDrainService(arg1 cfCmdGenerator.CfCmdGenerator, arg2 string, arg3 string) []cmdStartWaiter.CmdStartWaiter {
 	fake.createAndBindSyslogDrainServiceMutex.Lock()
 	ret, specificReturn := fake.createAndBindSyslogDrainServiceReturnsOnCall[len(fake.createAndBindSyslogDrainServiceArgsForCall)]
 	fake.createAndBindSyslogDrainServiceArgsForCall = append(fake.createAndBindSyslogDrainServiceArgsForCall, struct {
 		arg1 cfCmdGenerator.CfCmdGenerator
 		arg2 string
-	}{arg1, arg2})
+		arg3 string
+	}{arg1, arg2, arg3})
 	stub := fake.CreateAndBindSyslogDrainServiceStub
 	fakeReturns := fake.createAndBindSyslogDrainServiceReturns
-	fake.recordInvocation("CreateAndBindSyslogDrainService", []interface{}{arg1, arg2})
+	fake.recordInvocation("CreateAndBindSyslogDrainService", []interface{}{arg1, arg2, arg3})
 	fake.createAndBindSyslogDrainServiceMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1
@@ -338,17 +558,17 @@ func (fake *FakeCfWorkflow) CreateAndBindSyslogDrainServiceCallCount() int {
 	return len(fake.createAndBindSyslogDrainServiceArgsForCall)
 }
 
-func (fake *FakeCfWorkflow) CreateAndBindSyslogDrainServiceCalls(stub func(cfCmdGenerator.CfCmdGenerator, string) []cmdStartWaiter.CmdStartWaiter) {
+func (fake *FakeCfWorkflow) CreateAndBindSyslogDrainServiceCalls(stub func(cfCmdGenerator.CfCmdGenerator, string, string) []cmdStartWaiter.CmdStartWaiter) {
 	fake.createAndBindSyslogDrainServiceMutex.Lock()
 	defer fake.createAndBindSyslogDrainServiceMutex.Unlock()
 	fake.CreateAndBindSyslogDrainServiceStub = stub
 }
 
-func (fake *FakeCfWorkflow) CreateAndBindSyslogDrainServiceArgsForCall(i int) (cfCmdGenerator.CfCmdGenerator, string) {
+func (fake *FakeCfWorkflow) CreateAndBindSyslogDrainServiceArgsForCall(i int) (cfCmdGenerator.CfCmdGenerator, string, string) {
 	fake.createAndBindSyslogDrainServiceMutex.RLock()
 	defer fake.createAndBindSyslogDrainServiceMutex.RUnlock()
 	argsForCall := fake.createAndBindSyslogDrainServiceArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeCfWorkflow) CreateAndBindSyslogDrainServiceReturns(result1 []cmdStartWaiter.CmdStartWaiter) {
@@ -435,6 +655,129 @@ func (fake *FakeCfWorkflow) DeleteReturnsOnCall(i int, result1 []cmdStartWaiter.
 	}{result1}
 }
 
+func (fake *FakeCfWorkflow) DeleteSyslogDrainService(arg1 cfCmdGenerator.CfCmdGenerator, arg2 string) []cmdStartWaiter.CmdStartWaiter {
+	fake.deleteSyslogDrainServiceMutex.Lock()
+	ret, specificReturn := fake.deleteSyslogDrainServiceReturnsOnCall[len(fake.deleteSyslogDrainServiceArgsForCall)]
+	fake.deleteSyslogDrainServiceArgsForCall = append(fake.deleteSyslogDrainServiceArgsForCall, struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteSyslogDrainServiceStub
+	fakeReturns := fake.deleteSyslogDrainServiceReturns
+	fake.recordInvocation("DeleteSyslogDrainService", []interface{}{arg1, arg2})
+	fake.deleteSyslogDrainServiceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfWorkflow) DeleteSyslogDrainServiceCallCount() int {
+	fake.deleteSyslogDrainServiceMutex.RLock()
+	defer fake.deleteSyslogDrainServiceMutex.RUnlock()
+	return len(fake.deleteSyslogDrainServiceArgsForCall)
+}
+
+func (fake *FakeCfWorkflow) DeleteSyslogDrainServiceCalls(stub func(cfCmdGenerator.CfCmdGenerator, string) []cmdStartWaiter.CmdStartWaiter) {
+	fake.deleteSyslogDrainServiceMutex.Lock()
+	defer fake.deleteSyslogDrainServiceMutex.Unlock()
+	fake.DeleteSyslogDrainServiceStub = stub
+}
+
+func (fake *FakeCfWorkflow) DeleteSyslogDrainServiceArgsForCall(i int) (cfCmdGenerator.CfCmdGenerator, string) {
+	fake.deleteSyslogDrainServiceMutex.RLock()
+	defer fake.deleteSyslogDrainServiceMutex.RUnlock()
+	argsForCall := fake.deleteSyslogDrainServiceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCfWorkflow) DeleteSyslogDrainServiceReturns(result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.deleteSyslogDrainServiceMutex.Lock()
+	defer fake.deleteSyslogDrainServiceMutex.Unlock()
+	fake.DeleteSyslogDrainServiceStub = nil
+	fake.deleteSyslogDrainServiceReturns = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) DeleteSyslogDrainServiceReturnsOnCall(i int, result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.deleteSyslogDrainServiceMutex.Lock()
+	defer fake.deleteSyslogDrainServiceMutex.Unlock()
+	fake.DeleteSyslogDrainServiceStub = nil
+	if fake.deleteSyslogDrainServiceReturnsOnCall == nil {
+		fake.deleteSyslogDrainServiceReturnsOnCall = make(map[int]struct {
+			result1 []cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.deleteSyslogDrainServiceReturnsOnCall[i] = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) Login(arg1 cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	fake.loginMutex.Lock()
+	ret, specificReturn := fake.loginReturnsOnCall[len(fake.loginArgsForCall)]
+	fake.loginArgsForCall = append(fake.loginArgsForCall, struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+	}{arg1})
+	stub := fake.LoginStub
+	fakeReturns := fake.loginReturns
+	fake.recordInvocation("Login", []interface{}{arg1})
+	fake.loginMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfWorkflow) LoginCallCount() int {
+	fake.loginMutex.RLock()
+	defer fake.loginMutex.RUnlock()
+	return len(fake.loginArgsForCall)
+}
+
+func (fake *FakeCfWorkflow) LoginCalls(stub func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter) {
+	fake.loginMutex.Lock()
+	defer fake.loginMutex.Unlock()
+	fake.LoginStub = stub
+}
+
+func (fake *FakeCfWorkflow) LoginArgsForCall(i int) cfCmdGenerator.CfCmdGenerator {
+	fake.loginMutex.RLock()
+	defer fake.loginMutex.RUnlock()
+	argsForCall := fake.loginArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCfWorkflow) LoginReturns(result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.loginMutex.Lock()
+	defer fake.loginMutex.Unlock()
+	fake.LoginStub = nil
+	fake.loginReturns = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) LoginReturnsOnCall(i int, result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.loginMutex.Lock()
+	defer fake.loginMutex.Unlock()
+	fake.LoginStub = nil
+	if fake.loginReturnsOnCall == nil {
+		fake.loginReturnsOnCall = make(map[int]struct {
+			result1 []cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.loginReturnsOnCall[i] = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfWorkflow) MapSyslogRoute(arg1 cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
 	fake.mapSyslogRouteMutex.Lock()
 	ret, specificReturn := fake.mapSyslogRouteReturnsOnCall[len(fake.mapSyslogRouteArgsForCall)]
@@ -557,6 +900,67 @@ func (fake *FakeCfWorkflow) MapTCPRouteReturnsOnCall(i int, result1 []cmdStartWa
 	}{result1}
 }
 
+func (fake *FakeCfWorkflow) OauthToken(arg1 cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	fake.oauthTokenMutex.Lock()
+	ret, specificReturn := fake.oauthTokenReturnsOnCall[len(fake.oauthTokenArgsForCall)]
+	fake.oauthTokenArgsForCall = append(fake.oauthTokenArgsForCall, struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+	}{arg1})
+	stub := fake.OauthTokenStub
+	fakeReturns := fake.oauthTokenReturns
+	fake.recordInvocation("OauthToken", []interface{}{arg1})
+	fake.oauthTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfWorkflow) OauthTokenCallCount() int {
+	fake.oauthTokenMutex.RLock()
+	defer fake.oauthTokenMutex.RUnlock()
+	return len(fake.oauthTokenArgsForCall)
+}
+
+func (fake *FakeCfWorkflow) OauthTokenCalls(stub func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter) {
+	fake.oauthTokenMutex.Lock()
+	defer fake.oauthTokenMutex.Unlock()
+	fake.OauthTokenStub = stub
+}
+
+func (fake *FakeCfWorkflow) OauthTokenArgsForCall(i int) cfCmdGenerator.CfCmdGenerator {
+	fake.oauthTokenMutex.RLock()
+	defer fake.oauthTokenMutex.RUnlock()
+	argsForCall := fake.oauthTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCfWorkflow) OauthTokenReturns(result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.oauthTokenMutex.Lock()
+	defer fake.oauthTokenMutex.Unlock()
+	fake.OauthTokenStub = nil
+	fake.oauthTokenReturns = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) OauthTokenReturnsOnCall(i int, result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.oauthTokenMutex.Lock()
+	defer fake.oauthTokenMutex.Unlock()
+	fake.OauthTokenStub = nil
+	if fake.oauthTokenReturnsOnCall == nil {
+		fake.oauthTokenReturnsOnCall = make(map[int]struct {
+			result1 []cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.oauthTokenReturnsOnCall[i] = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfWorkflow) Org() string {
 	fake.orgMutex.Lock()
 	ret, specificReturn := fake.orgReturnsOnCall[len(fake.orgArgsForCall)]
@@ -671,6 +1075,70 @@ func (fake *FakeCfWorkflow) PushReturnsOnCall(i int, result1 []cmdStartWaiter.Cm
 	}{result1}
 }
 
+func (fake *FakeCfWorkflow) PushDockerImage(arg1 cfCmdGenerator.CfCmdGenerator, arg2 string, arg3 string, arg4 string) []cmdStartWaiter.CmdStartWaiter {
+	fake.pushDockerImageMutex.Lock()
+	ret, specificReturn := fake.pushDockerImageReturnsOnCall[len(fake.pushDockerImageArgsForCall)]
+	fake.pushDockerImageArgsForCall = append(fake.pushDockerImageArgsForCall, struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+		arg2 string
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.PushDockerImageStub
+	fakeReturns := fake.pushDockerImageReturns
+	fake.recordInvocation("PushDockerImage", []interface{}{arg1, arg2, arg3, arg4})
+	fake.pushDockerImageMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfWorkflow) PushDockerImageCallCount() int {
+	fake.pushDockerImageMutex.RLock()
+	defer fake.pushDockerImageMutex.RUnlock()
+	return len(fake.pushDockerImageArgsForCall)
+}
+
+func (fake *FakeCfWorkflow) PushDockerImageCalls(stub func(cfCmdGenerator.CfCmdGenerator, string, string, string) []cmdStartWaiter.CmdStartWaiter) {
+	fake.pushDockerImageMutex.Lock()
+	defer fake.pushDockerImageMutex.Unlock()
+	fake.PushDockerImageStub = stub
+}
+
+func (fake *FakeCfWorkflow) PushDockerImageArgsForCall(i int) (cfCmdGenerator.CfCmdGenerator, string, string, string) {
+	fake.pushDockerImageMutex.RLock()
+	defer fake.pushDockerImageMutex.RUnlock()
+	argsForCall := fake.pushDockerImageArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeCfWorkflow) PushDockerImageReturns(result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.pushDockerImageMutex.Lock()
+	defer fake.pushDockerImageMutex.Unlock()
+	fake.PushDockerImageStub = nil
+	fake.pushDockerImageReturns = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) PushDockerImageReturnsOnCall(i int, result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.pushDockerImageMutex.Lock()
+	defer fake.pushDockerImageMutex.Unlock()
+	fake.PushDockerImageStub = nil
+	if fake.pushDockerImageReturnsOnCall == nil {
+		fake.pushDockerImageReturnsOnCall = make(map[int]struct {
+			result1 []cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.pushDockerImageReturnsOnCall[i] = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfWorkflow) PushNoRoute(arg1 cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
 	fake.pushNoRouteMutex.Lock()
 	ret, specificReturn := fake.pushNoRouteReturnsOnCall[len(fake.pushNoRouteArgsForCall)]
@@ -732,6 +1200,69 @@ func (fake *FakeCfWorkflow) PushNoRouteReturnsOnCall(i int, result1 []cmdStartWa
 	}{result1}
 }
 
+func (fake *FakeCfWorkflow) PushWithBuildpack(arg1 cfCmdGenerator.CfCmdGenerator, arg2 string, arg3 string) []cmdStartWaiter.CmdStartWaiter {
+	fake.pushWithBuildpackMutex.Lock()
+	ret, specificReturn := fake.pushWithBuildpackReturnsOnCall[len(fake.pushWithBuildpackArgsForCall)]
+	fake.pushWithBuildpackArgsForCall = append(fake.pushWithBuildpackArgsForCall, struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.PushWithBuildpackStub
+	fakeReturns := fake.pushWithBuildpackReturns
+	fake.recordInvocation("PushWithBuildpack", []interface{}{arg1, arg2, arg3})
+	fake.pushWithBuildpackMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfWorkflow) PushWithBuildpackCallCount() int {
+	fake.pushWithBuildpackMutex.RLock()
+	defer fake.pushWithBuildpackMutex.RUnlock()
+	return len(fake.pushWithBuildpackArgsForCall)
+}
+
+func (fake *FakeCfWorkflow) PushWithBuildpackCalls(stub func(cfCmdGenerator.CfCmdGenerator, string, string) []cmdStartWaiter.CmdStartWaiter) {
+	fake.pushWithBuildpackMutex.Lock()
+	defer fake.pushWithBuildpackMutex.Unlock()
+	fake.PushWithBuildpackStub = stub
+}
+
+func (fake *FakeCfWorkflow) PushWithBuildpackArgsForCall(i int) (cfCmdGenerator.CfCmdGenerator, string, string) {
+	fake.pushWithBuildpackMutex.RLock()
+	defer fake.pushWithBuildpackMutex.RUnlock()
+	argsForCall := fake.pushWithBuildpackArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeCfWorkflow) PushWithBuildpackReturns(result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.pushWithBuildpackMutex.Lock()
+	defer fake.pushWithBuildpackMutex.Unlock()
+	fake.PushWithBuildpackStub = nil
+	fake.pushWithBuildpackReturns = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) PushWithBuildpackReturnsOnCall(i int, result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.pushWithBuildpackMutex.Lock()
+	defer fake.pushWithBuildpackMutex.Unlock()
+	fake.PushWithBuildpackStub = nil
+	if fake.pushWithBuildpackReturnsOnCall == nil {
+		fake.pushWithBuildpackReturnsOnCall = make(map[int]struct {
+			result1 []cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.pushWithBuildpackReturnsOnCall[i] = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfWorkflow) Quota() string {
 	fake.quotaMutex.Lock()
 	ret, specificReturn := fake.quotaReturnsOnCall[len(fake.quotaArgsForCall)]
@@ -846,6 +1377,128 @@ func (fake *FakeCfWorkflow) RecentLogsReturnsOnCall(i int, result1 []cmdStartWai
 	}{result1}
 }
 
+func (fake *FakeCfWorkflow) RefreshToken(arg1 cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	fake.refreshTokenMutex.Lock()
+	ret, specificReturn := fake.refreshTokenReturnsOnCall[len(fake.refreshTokenArgsForCall)]
+	fake.refreshTokenArgsForCall = append(fake.refreshTokenArgsForCall, struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+	}{arg1})
+	stub := fake.RefreshTokenStub
+	fakeReturns := fake.refreshTokenReturns
+	fake.recordInvocation("RefreshToken", []interface{}{arg1})
+	fake.refreshTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfWorkflow) RefreshTokenCallCount() int {
+	fake.refreshTokenMutex.RLock()
+	defer fake.refreshTokenMutex.RUnlock()
+	return len(fake.refreshTokenArgsForCall)
+}
+
+func (fake *FakeCfWorkflow) RefreshTokenCalls(stub func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter) {
+	fake.refreshTokenMutex.Lock()
+	defer fake.refreshTokenMutex.Unlock()
+	fake.RefreshTokenStub = stub
+}
+
+func (fake *FakeCfWorkflow) RefreshTokenArgsForCall(i int) cfCmdGenerator.CfCmdGenerator {
+	fake.refreshTokenMutex.RLock()
+	defer fake.refreshTokenMutex.RUnlock()
+	argsForCall := fake.refreshTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCfWorkflow) RefreshTokenReturns(result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.refreshTokenMutex.Lock()
+	defer fake.refreshTokenMutex.Unlock()
+	fake.RefreshTokenStub = nil
+	fake.refreshTokenReturns = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) RefreshTokenReturnsOnCall(i int, result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.refreshTokenMutex.Lock()
+	defer fake.refreshTokenMutex.Unlock()
+	fake.RefreshTokenStub = nil
+	if fake.refreshTokenReturnsOnCall == nil {
+		fake.refreshTokenReturnsOnCall = make(map[int]struct {
+			result1 []cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.refreshTokenReturnsOnCall[i] = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) RollingDeploy(arg1 cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	fake.rollingDeployMutex.Lock()
+	ret, specificReturn := fake.rollingDeployReturnsOnCall[len(fake.rollingDeployArgsForCall)]
+	fake.rollingDeployArgsForCall = append(fake.rollingDeployArgsForCall, struct {
+		arg1 cfCmdGenerator.CfCmdGenerator
+	}{arg1})
+	stub := fake.RollingDeployStub
+	fakeReturns := fake.rollingDeployReturns
+	fake.recordInvocation("RollingDeploy", []interface{}{arg1})
+	fake.rollingDeployMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCfWorkflow) RollingDeployCallCount() int {
+	fake.rollingDeployMutex.RLock()
+	defer fake.rollingDeployMutex.RUnlock()
+	return len(fake.rollingDeployArgsForCall)
+}
+
+func (fake *FakeCfWorkflow) RollingDeployCalls(stub func(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter) {
+	fake.rollingDeployMutex.Lock()
+	defer fake.rollingDeployMutex.Unlock()
+	fake.RollingDeployStub = stub
+}
+
+func (fake *FakeCfWorkflow) RollingDeployArgsForCall(i int) cfCmdGenerator.CfCmdGenerator {
+	fake.rollingDeployMutex.RLock()
+	defer fake.rollingDeployMutex.RUnlock()
+	argsForCall := fake.rollingDeployArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCfWorkflow) RollingDeployReturns(result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.rollingDeployMutex.Lock()
+	defer fake.rollingDeployMutex.Unlock()
+	fake.RollingDeployStub = nil
+	fake.rollingDeployReturns = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
+func (fake *FakeCfWorkflow) RollingDeployReturnsOnCall(i int, result1 []cmdStartWaiter.CmdStartWaiter) {
+	fake.rollingDeployMutex.Lock()
+	defer fake.rollingDeployMutex.Unlock()
+	fake.RollingDeployStub = nil
+	if fake.rollingDeployReturnsOnCall == nil {
+		fake.rollingDeployReturnsOnCall = make(map[int]struct {
+			result1 []cmdStartWaiter.CmdStartWaiter
+		})
+	}
+	fake.rollingDeployReturnsOnCall[i] = struct {
+		result1 []cmdStartWaiter.CmdStartWaiter
+	}{result1}
+}
+
 func (fake *FakeCfWorkflow) Setup(arg1 cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
 	fake.setupMutex.Lock()
 	ret, specificReturn := fake.setupReturnsOnCall[len(fake.setupArgsForCall)]
@@ -1192,6 +1845,10 @@ func (fake *FakeCfWorkflow) TearDownReturnsOnCall(i int, result1 []cmdStartWaite
 func (fake *FakeCfWorkflow) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.appGuidMutex.RLock()
+	defer fake.appGuidMutex.RUnlock()
+	fake.appNameMutex.RLock()
+	defer fake.appNameMutex.RUnlock()
 	fake.appStatsMutex.RLock()
 	defer fake.appStatsMutex.RUnlock()
 	fake.appUrlMutex.RLock()
@@ -1200,20 +1857,34 @@ func (fake *FakeCfWorkflow) Invocations() map[string][][]interface{} {
 	defer fake.createAndBindSyslogDrainServiceMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
+	fake.deleteSyslogDrainServiceMutex.RLock()
+	defer fake.deleteSyslogDrainServiceMutex.RUnlock()
+	fake.loginMutex.RLock()
+	defer fake.loginMutex.RUnlock()
 	fake.mapSyslogRouteMutex.RLock()
 	defer fake.mapSyslogRouteMutex.RUnlock()
 	fake.mapTCPRouteMutex.RLock()
 	defer fake.mapTCPRouteMutex.RUnlock()
+	fake.oauthTokenMutex.RLock()
+	defer fake.oauthTokenMutex.RUnlock()
 	fake.orgMutex.RLock()
 	defer fake.orgMutex.RUnlock()
 	fake.pushMutex.RLock()
 	defer fake.pushMutex.RUnlock()
+	fake.pushDockerImageMutex.RLock()
+	defer fake.pushDockerImageMutex.RUnlock()
 	fake.pushNoRouteMutex.RLock()
 	defer fake.pushNoRouteMutex.RUnlock()
+	fake.pushWithBuildpackMutex.RLock()
+	defer fake.pushWithBuildpackMutex.RUnlock()
 	fake.quotaMutex.RLock()
 	defer fake.quotaMutex.RUnlock()
 	fake.recentLogsMutex.RLock()
 	defer fake.recentLogsMutex.RUnlock()
+	fake.refreshTokenMutex.RLock()
+	defer fake.refreshTokenMutex.RUnlock()
+	fake.rollingDeployMutex.RLock()
+	defer fake.rollingDeployMutex.RUnlock()
 	fake.setupMutex.RLock()
 	defer fake.setupMutex.RUnlock()
 	fake.spaceMutex.RLock()
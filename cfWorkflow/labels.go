@@ -0,0 +1,56 @@
+package cfWorkflow
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The key of the CF metadata label uptimer puts on the resources it creates,
+// by which they can be selected.
+const RunIDLabel = "uptimer.cloudfoundry.org/run-id"
+
+// The keys of the CF metadata annotations uptimer puts on the resources it
+// creates. Annotations, unlike labels, take values of any form.
+const (
+	VersionAnnotation   = "uptimer.cloudfoundry.org/version"
+	StartedAtAnnotation = "uptimer.cloudfoundry.org/started-at"
+	HostAnnotation      = "uptimer.cloudfoundry.org/host"
+)
+
+const maxLabelValueLength = 63
+
+var invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// Metadata is the CF metadata a workflow puts on the resources it creates.
+type Metadata struct {
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// RunMetadata identifies a run of uptimer. The run ID is a label, made to fit
+// the rules the Cloud Controller has for label values; the other values are
+// annotations, as they are.
+func RunMetadata(runID, version string, startedAt time.Time, host string) Metadata {
+	return Metadata{
+		Labels: map[string]string{
+			RunIDLabel: labelValue(runID),
+		},
+		Annotations: map[string]string{
+			VersionAnnotation:   version,
+			StartedAtAnnotation: startedAt.UTC().Format(time.RFC3339),
+			HostAnnotation:      host,
+		},
+	}
+}
+
+// labelValue replaces the characters label values cannot have and trims
+// them to at most 63 characters that start and end alphanumerically.
+func labelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(value, "-")
+	if len(value) > maxLabelValueLength {
+		value = value[:maxLabelValueLength]
+	}
+
+	return strings.Trim(value, "._-")
+}
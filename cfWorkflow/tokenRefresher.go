@@ -0,0 +1,113 @@
+package cfWorkflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/measurement"
+)
+
+// expiryMargin is how long before its expiry an access token is refreshed,
+// so that it does not expire in the middle of an attempt.
+const expiryMargin = 5 * time.Minute
+
+type tokenRefresher struct {
+	workflow CfWorkflow
+	ccg      cfCmdGenerator.CfCmdGenerator
+	clock    clock.Clock
+	runner   cmdRunner.CmdRunner
+	outBuf   *bytes.Buffer
+	errBuf   *bytes.Buffer
+}
+
+// NewTokenRefresher returns a TokenRefresher for the CF_HOME of ccg. It
+// refreshes the access token when it is about to expire, and logs in again
+// when the refresh token has expired as well.
+func NewTokenRefresher(
+	workflow CfWorkflow,
+	ccg cfCmdGenerator.CfCmdGenerator,
+	clock clock.Clock,
+	runner cmdRunner.CmdRunner,
+	outBuf, errBuf *bytes.Buffer,
+) measurement.TokenRefresher {
+	return &tokenRefresher{
+		workflow: workflow,
+		ccg:      ccg,
+		clock:    clock,
+		runner:   runner,
+		outBuf:   outBuf,
+		errBuf:   errBuf,
+	}
+}
+
+func (t *tokenRefresher) RefreshIfExpiring(ctx context.Context) error {
+	if !t.expiring() {
+		return nil
+	}
+
+	defer t.outBuf.Reset()
+	defer t.errBuf.Reset()
+
+	if err := t.runner.RunInSequenceWithContext(ctx, t.workflow.RefreshToken(t.ccg)...); err == nil {
+		return nil
+	}
+
+	t.outBuf.Reset()
+	t.errBuf.Reset()
+	if err := t.runner.RunInSequenceWithContext(ctx, t.workflow.Login(t.ccg)...); err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(t.errBuf.String()))
+	}
+
+	return nil
+}
+
+// expiring reports whether the access token expires within the margin. A
+// token whose expiry cannot be read is treated as expiring.
+func (t *tokenRefresher) expiring() bool {
+	token, err := t.ccg.AccessToken()
+	if err != nil {
+		return true
+	}
+
+	expiry, err := tokenExpiry(token)
+	if err != nil {
+		return true
+	}
+
+	return !t.clock.Now().Add(expiryMargin).Before(expiry)
+}
+
+// tokenExpiry reads the exp claim of a JWT.
+func tokenExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, errors.New("access token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, err
+	}
+	if claims.Exp == 0 {
+		return time.Time{}, errors.New("access token has no expiry")
+	}
+
+	return time.Unix(claims.Exp, 0), nil
+}
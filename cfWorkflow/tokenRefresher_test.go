@@ -0,0 +1,165 @@
+package cfWorkflow_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	. "github.com/cloudfoundry/uptimer/cfWorkflow"
+	"github.com/cloudfoundry/uptimer/cfWorkflow/cfWorkflowfakes"
+	"github.com/cloudfoundry/uptimer/cmdRunner/cmdRunnerfakes"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+	"github.com/cloudfoundry/uptimer/measurement"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TokenRefresher", func() {
+	var (
+		fakeWorkflow *cfWorkflowfakes.FakeCfWorkflow
+		fakeRunner   *cmdRunnerfakes.FakeCmdRunner
+		mockClock    *clock.Mock
+		cfHome       string
+		ccg          cfCmdGenerator.CfCmdGenerator
+		outBuf       *bytes.Buffer
+		errBuf       *bytes.Buffer
+		refreshCmds  []cmdStartWaiter.CmdStartWaiter
+		loginCmds    []cmdStartWaiter.CmdStartWaiter
+
+		tr measurement.TokenRefresher
+	)
+
+	writeConfig := func(accessToken string) {
+		Expect(os.MkdirAll(filepath.Join(cfHome, ".cf"), 0700)).To(Succeed())
+		config := fmt.Sprintf(`{"AccessToken": %q}`, accessToken)
+		Expect(os.WriteFile(filepath.Join(cfHome, ".cf", "config.json"), []byte(config), 0600)).To(Succeed())
+	}
+
+	jwt := func(claims string) string {
+		return "bearer header." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+	}
+
+	BeforeEach(func() {
+		fakeWorkflow = &cfWorkflowfakes.FakeCfWorkflow{}
+		fakeRunner = &cmdRunnerfakes.FakeCmdRunner{}
+		mockClock = clock.NewMock()
+		mockClock.Set(time.Unix(1000000, 0))
+		cfHome = GinkgoT().TempDir()
+		ccg = cfCmdGenerator.New(cfHome, false)
+		outBuf = bytes.NewBufferString("some output")
+		errBuf = bytes.NewBufferString("")
+
+		refreshCmds = []cmdStartWaiter.CmdStartWaiter{ccg.RefreshToken()}
+		loginCmds = []cmdStartWaiter.CmdStartWaiter{ccg.Api("api"), ccg.Auth("user", "pass")}
+		fakeWorkflow.RefreshTokenReturns(refreshCmds)
+		fakeWorkflow.LoginReturns(loginCmds)
+
+		tr = NewTokenRefresher(fakeWorkflow, ccg, mockClock, fakeRunner, outBuf, errBuf)
+	})
+
+	Context("when the access token is valid for longer than the margin", func() {
+		BeforeEach(func() {
+			writeConfig(jwt(`{"exp": 1000301}`))
+		})
+
+		It("does not refresh it", func() {
+			Expect(tr.RefreshIfExpiring(context.TODO())).To(Succeed())
+
+			Expect(fakeRunner.RunInSequenceWithContextCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the access token expires within the margin", func() {
+		BeforeEach(func() {
+			writeConfig(jwt(`{"exp": 1000300}`))
+		})
+
+		It("runs the workflow's refresh token commands in the generator's CF_HOME", func() {
+			ctx := context.WithValue(context.Background(), "some-key", "some-value")
+
+			Expect(tr.RefreshIfExpiring(ctx)).To(Succeed())
+
+			Expect(fakeWorkflow.RefreshTokenCallCount()).To(Equal(1))
+			Expect(fakeWorkflow.RefreshTokenArgsForCall(0)).To(Equal(ccg))
+			Expect(fakeRunner.RunInSequenceWithContextCallCount()).To(Equal(1))
+			runCtx, cmds := fakeRunner.RunInSequenceWithContextArgsForCall(0)
+			Expect(runCtx).To(Equal(ctx))
+			Expect(cmds).To(Equal(refreshCmds))
+			Expect(fakeWorkflow.LoginCallCount()).To(Equal(0))
+		})
+
+		It("resets the buffers", func() {
+			Expect(tr.RefreshIfExpiring(context.TODO())).To(Succeed())
+
+			Expect(outBuf.Len()).To(Equal(0))
+			Expect(errBuf.Len()).To(Equal(0))
+		})
+
+		Context("when the token cannot be refreshed", func() {
+			BeforeEach(func() {
+				fakeRunner.RunInSequenceWithContextReturnsOnCall(0, fmt.Errorf("refresh failed"))
+			})
+
+			It("logs in again", func() {
+				Expect(tr.RefreshIfExpiring(context.TODO())).To(Succeed())
+
+				Expect(fakeRunner.RunInSequenceWithContextCallCount()).To(Equal(2))
+				_, cmds := fakeRunner.RunInSequenceWithContextArgsForCall(1)
+				Expect(cmds).To(Equal(loginCmds))
+			})
+
+			It("returns the error and its output when logging in fails as well", func() {
+				fakeRunner.RunInSequenceWithContextStub = func(context.Context, ...cmdStartWaiter.CmdStartWaiter) error {
+					if fakeRunner.RunInSequenceWithContextCallCount() == 2 {
+						errBuf.WriteString("Credentials were rejected\n")
+						return fmt.Errorf("login failed")
+					}
+					return fmt.Errorf("refresh failed")
+				}
+
+				Expect(tr.RefreshIfExpiring(context.TODO())).To(MatchError("login failed: Credentials were rejected"))
+				Expect(errBuf.Len()).To(Equal(0))
+			})
+		})
+	})
+
+	Context("when the access token has no expiry", func() {
+		BeforeEach(func() {
+			writeConfig(jwt(`{"user_name": "admin"}`))
+		})
+
+		It("refreshes it", func() {
+			Expect(tr.RefreshIfExpiring(context.TODO())).To(Succeed())
+
+			Expect(fakeRunner.RunInSequenceWithContextCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the access token is not a JWT", func() {
+		BeforeEach(func() {
+			writeConfig("bearer some-token")
+		})
+
+		It("refreshes it", func() {
+			Expect(tr.RefreshIfExpiring(context.TODO())).To(Succeed())
+
+			Expect(fakeRunner.RunInSequenceWithContextCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when CF_HOME has no config", func() {
+		It("refreshes the token", func() {
+			Expect(tr.RefreshIfExpiring(context.TODO())).To(Succeed())
+
+			Expect(fakeRunner.RunInSequenceWithContextCallCount()).To(Equal(1))
+		})
+	})
+})
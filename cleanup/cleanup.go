@@ -0,0 +1,266 @@
+package cleanup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	"github.com/cloudfoundry/uptimer/cfWorkflow"
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+)
+
+// The Cloud Controller lists at most 5000 resources per page.
+const listQuery = "per_page=5000"
+
+// labelledQuery selects the resources labelled with the ID of an uptimer
+// run.
+const labelledQuery = "label_selector=" + cfWorkflow.RunIDLabel + "&" + listQuery
+
+// Resource is something a run of uptimer creates on a foundation.
+type Resource struct {
+	Kind      string
+	Name      string
+	Guid      string
+	CreatedAt time.Time
+	// RunID is the ID of the run that created the resource, unless its kind
+	// cannot be labelled.
+	RunID string
+}
+
+func (r Resource) String() string {
+	if r.RunID == "" {
+		return fmt.Sprintf("%s %s (created %s)", r.Kind, r.Name, r.CreatedAt.UTC().Format(time.RFC3339))
+	}
+
+	return fmt.Sprintf("%s %s (run %s, created %s)", r.Kind, r.Name, r.RunID, r.CreatedAt.UTC().Format(time.RFC3339))
+}
+
+// kind is a kind of resource uptimer creates, and where to list it. Labelled
+// kinds are found by their label; kinds that cannot be labelled are told
+// apart by the prefix of their names. Unlabelled resources of labelled kinds,
+// left behind by versions of uptimer that did not label them yet, are only
+// found by their prefix when asked for.
+type kind struct {
+	name     string
+	prefix   string
+	path     string
+	labelled bool
+}
+
+// kinds are listed in the order their resources are deleted: service
+// instances may be in spaces of other orgs, and a quota cannot be deleted
+// while an org uses it.
+var kinds = []kind{
+	{name: "service", prefix: "uptimer-srv-", path: "/v3/service_instances?type=user-provided&", labelled: true},
+	{name: "org", prefix: "uptimer-org-", path: "/v3/organizations?", labelled: true},
+	{name: "quota", prefix: "uptimer-quota-", path: "/v3/organization_quotas?"},
+}
+
+//go:generate counterfeiter . Cleaner
+
+// Cleaner finds and deletes the orgs, quotas and syslog drain services that
+// runs of uptimer left behind on a foundation, e.g. when they crashed.
+type Cleaner interface {
+	Find(olderThan time.Duration) ([]Resource, error)
+	Delete(resources []Resource) error
+}
+
+type cleaner struct {
+	logger *log.Logger
+	clock  clock.Clock
+	ccg    cfCmdGenerator.CfCmdGenerator
+	runner cmdRunner.CmdRunner
+	outBuf *bytes.Buffer
+	errBuf *bytes.Buffer
+
+	unlabelled bool
+}
+
+// New returns a Cleaner that runs the commands of ccg, which must be logged
+// in, with runner. runner writes their output to outBuf and errBuf. With
+// unlabelled, it also finds the orgs and services named like uptimer's that
+// are not labelled, which may belong to other tools.
+func New(
+	logger *log.Logger,
+	clock clock.Clock,
+	ccg cfCmdGenerator.CfCmdGenerator,
+	runner cmdRunner.CmdRunner,
+	outBuf, errBuf *bytes.Buffer,
+	unlabelled bool,
+) Cleaner {
+	return &cleaner{
+		logger:     logger,
+		clock:      clock,
+		ccg:        ccg,
+		runner:     runner,
+		outBuf:     outBuf,
+		errBuf:     errBuf,
+		unlabelled: unlabelled,
+	}
+}
+
+// Find returns the resources created more than olderThan ago, so that the
+// resources of runs still in progress can be spared.
+func (c *cleaner) Find(olderThan time.Duration) ([]Resource, error) {
+	cutoff := c.clock.Now().Add(-olderThan)
+
+	var found []Resource
+	for _, k := range kinds {
+		resources, err := c.list(k)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list %ss: %s", k.name, err)
+		}
+		for _, r := range resources {
+			if !r.CreatedAt.After(cutoff) {
+				found = append(found, r)
+			}
+		}
+	}
+
+	return found, nil
+}
+
+func (c *cleaner) list(k kind) ([]Resource, error) {
+	if !k.labelled {
+		return c.listPages(k, k.path+listQuery, k.prefix)
+	}
+
+	resources, err := c.listPages(k, k.path+labelledQuery, "")
+	if err != nil || !c.unlabelled {
+		return resources, err
+	}
+
+	prefixed, err := c.listPages(k, k.path+listQuery, k.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, r := range resources {
+		seen[r.Guid] = true
+	}
+	for _, r := range prefixed {
+		if !seen[r.Guid] {
+			resources = append(resources, r)
+		}
+	}
+
+	return resources, nil
+}
+
+// listPages lists the resources at path whose names start with prefix,
+// following the pages of the list.
+func (c *cleaner) listPages(k kind, path, prefix string) ([]Resource, error) {
+	var resources []Resource
+	for path != "" {
+		page, next, err := c.listPage(k, path, prefix)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, page...)
+		path = next
+	}
+
+	return resources, nil
+}
+
+// listPage lists the resources on the page at path whose names start with
+// prefix, and returns the path of the next page, if there is one.
+func (c *cleaner) listPage(k kind, path, prefix string) ([]Resource, string, error) {
+	defer c.outBuf.Reset()
+	defer c.errBuf.Reset()
+
+	if err := c.runner.Run(c.ccg.Curl(path)); err != nil {
+		return nil, "", fmt.Errorf("%s: %s", err, strings.TrimSpace(c.errBuf.String()))
+	}
+
+	list := &struct {
+		Pagination struct {
+			Next *struct {
+				Href string `json:"href"`
+			} `json:"next"`
+		} `json:"pagination"`
+		Resources []struct {
+			Guid      string    `json:"guid"`
+			Name      string    `json:"name"`
+			CreatedAt time.Time `json:"created_at"`
+			Metadata  struct {
+				Labels map[string]string `json:"labels"`
+			} `json:"metadata"`
+		} `json:"resources"`
+	}{}
+	if err := json.Unmarshal(c.outBuf.Bytes(), list); err != nil {
+		return nil, "", err
+	}
+
+	var resources []Resource
+	for _, r := range list.Resources {
+		if strings.HasPrefix(r.Name, prefix) {
+			resources = append(resources, Resource{
+				Kind:      k.name,
+				Name:      r.Name,
+				Guid:      r.Guid,
+				CreatedAt: r.CreatedAt,
+				RunID:     r.Metadata.Labels[cfWorkflow.RunIDLabel],
+			})
+		}
+	}
+
+	var next string
+	if list.Pagination.Next != nil {
+		// cf curl takes the path of the next page's URL.
+		nextUrl, err := url.Parse(list.Pagination.Next.Href)
+		if err != nil {
+			return nil, "", err
+		}
+		next = nextUrl.RequestURI()
+	}
+
+	return resources, next, nil
+}
+
+// Delete deletes each resource, and logs whether it did. It goes on after a
+// failure, and returns all of them.
+func (c *cleaner) Delete(resources []Resource) error {
+	var errs []error
+	for _, r := range resources {
+		if err := c.delete(r); err != nil {
+			c.logger.Printf("Failed to delete %s: %s", r, err)
+			errs = append(errs, fmt.Errorf("%s %s: %s", r.Kind, r.Name, err))
+			continue
+		}
+		c.logger.Printf("Deleted %s", r)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *cleaner) delete(r Resource) error {
+	defer c.outBuf.Reset()
+	defer c.errBuf.Reset()
+
+	var err error
+	switch r.Kind {
+	case "service":
+		err = c.runner.Run(c.ccg.PurgeServiceInstance(r.Guid))
+	case "org":
+		err = c.runner.Run(c.ccg.DeleteOrg(r.Name))
+	case "quota":
+		err = c.runner.Run(c.ccg.DeleteQuota(r.Name))
+	default:
+		return fmt.Errorf("unknown kind of resource %q", r.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(c.errBuf.String()))
+	}
+
+	return nil
+}
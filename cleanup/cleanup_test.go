@@ -0,0 +1,268 @@
+package cleanup_test
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	. "github.com/cloudfoundry/uptimer/cleanup"
+	"github.com/cloudfoundry/uptimer/cmdRunner/cmdRunnerfakes"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cleaner", func() {
+	var (
+		logBuf     *bytes.Buffer
+		mockClock  *clock.Mock
+		fakeRunner *cmdRunnerfakes.FakeCmdRunner
+		outBuf     *bytes.Buffer
+		errBuf     *bytes.Buffer
+		responses  map[string]string
+		failures   map[string]string
+		ran        []string
+		unlabelled bool
+
+		cleaner Cleaner
+	)
+
+	BeforeEach(func() {
+		logBuf = bytes.NewBuffer([]byte{})
+		mockClock = clock.NewMock()
+		mockClock.Set(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+		fakeRunner = &cmdRunnerfakes.FakeCmdRunner{}
+		outBuf = bytes.NewBuffer([]byte{})
+		errBuf = bytes.NewBuffer([]byte{})
+		responses = map[string]string{
+			"cf curl --fail /v3/service_instances?type=user-provided&label_selector=uptimer.cloudfoundry.org/run-id&per_page=5000": `{"resources": [
+				{"guid": "srv-guid", "name": "uptimer-srv-1", "created_at": "2024-05-01T09:00:00Z", "metadata": {"labels": {"uptimer.cloudfoundry.org/run-id": "old-run"}}}
+			]}`,
+			"cf curl --fail /v3/organizations?label_selector=uptimer.cloudfoundry.org/run-id&per_page=5000": `{"resources": [
+				{"guid": "old-org-guid", "name": "uptimer-org-old", "created_at": "2024-05-01T09:00:00Z", "metadata": {"labels": {"uptimer.cloudfoundry.org/run-id": "old-run"}}},
+				{"guid": "new-org-guid", "name": "uptimer-org-new", "created_at": "2024-05-01T11:30:00Z", "metadata": {"labels": {"uptimer.cloudfoundry.org/run-id": "new-run"}}}
+			]}`,
+			"cf curl --fail /v3/service_instances?type=user-provided&per_page=5000": `{"resources": [
+				{"guid": "srv-guid", "name": "uptimer-srv-1", "created_at": "2024-05-01T09:00:00Z", "metadata": {"labels": {"uptimer.cloudfoundry.org/run-id": "old-run"}}}
+			]}`,
+			"cf curl --fail /v3/organizations?per_page=5000": `{"resources": [
+				{"guid": "old-org-guid", "name": "uptimer-org-old", "created_at": "2024-05-01T09:00:00Z", "metadata": {"labels": {"uptimer.cloudfoundry.org/run-id": "old-run"}}},
+				{"guid": "system-guid", "name": "system", "created_at": "2024-01-01T00:00:00Z", "metadata": {"labels": {}}}
+			]}`,
+			"cf curl --fail /v3/organization_quotas?per_page=5000": `{"resources": [
+				{"guid": "quota-guid", "name": "uptimer-quota-1", "created_at": "2024-05-01T09:00:00Z"},
+				{"guid": "default-guid", "name": "default", "created_at": "2024-01-01T00:00:00Z"}
+			]}`,
+		}
+		failures = map[string]string{}
+		ran = nil
+		unlabelled = false
+		fakeRunner.RunStub = func(csw cmdStartWaiter.CmdStartWaiter) error {
+			cmdLine := strings.Join(csw.(*exec.Cmd).Args, " ")
+			ran = append(ran, cmdLine)
+			if msg, ok := failures[cmdLine]; ok {
+				errBuf.WriteString(msg)
+				return fmt.Errorf("exit status 1")
+			}
+			outBuf.WriteString(responses[cmdLine])
+			return nil
+		}
+
+	})
+
+	JustBeforeEach(func() {
+		cleaner = New(
+			log.New(logBuf, "", 0),
+			mockClock,
+			cfCmdGenerator.New("/cfhome", false),
+			fakeRunner,
+			outBuf,
+			errBuf,
+			unlabelled,
+		)
+	})
+
+	Describe("Find", func() {
+		It("finds the orgs, syslog drain services and quotas of uptimer", func() {
+			resources, err := cleaner.Find(0)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resources).To(Equal([]Resource{
+				{Kind: "service", Name: "uptimer-srv-1", Guid: "srv-guid", CreatedAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), RunID: "old-run"},
+				{Kind: "org", Name: "uptimer-org-old", Guid: "old-org-guid", CreatedAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), RunID: "old-run"},
+				{Kind: "org", Name: "uptimer-org-new", Guid: "new-org-guid", CreatedAt: time.Date(2024, 5, 1, 11, 30, 0, 0, time.UTC), RunID: "new-run"},
+				{Kind: "quota", Name: "uptimer-quota-1", Guid: "quota-guid", CreatedAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)},
+			}))
+		})
+
+		It("only finds resources older than the given age", func() {
+			resources, err := cleaner.Find(time.Hour)
+
+			Expect(err).NotTo(HaveOccurred())
+			var names []string
+			for _, r := range resources {
+				names = append(names, r.Name)
+			}
+			Expect(names).To(Equal([]string{"uptimer-srv-1", "uptimer-org-old", "uptimer-quota-1"}))
+		})
+
+		Context("with unlabelled orgs and services named like uptimer's", func() {
+			BeforeEach(func() {
+				responses["cf curl --fail /v3/service_instances?type=user-provided&per_page=5000"] = `{"resources": [
+					{"guid": "srv-guid", "name": "uptimer-srv-1", "created_at": "2024-05-01T09:00:00Z", "metadata": {"labels": {"uptimer.cloudfoundry.org/run-id": "old-run"}}},
+					{"guid": "unlabelled-srv-guid", "name": "uptimer-srv-2", "created_at": "2024-05-01T08:00:00Z", "metadata": {"labels": {}}},
+					{"guid": "other-srv-guid", "name": "my-drain", "created_at": "2024-05-01T08:00:00Z", "metadata": {"labels": {}}}
+				]}`
+				responses["cf curl --fail /v3/organizations?label_selector=uptimer.cloudfoundry.org/run-id&per_page=5000"] = `{"resources": [
+					{"guid": "old-org-guid", "name": "uptimer-org-old", "created_at": "2024-05-01T09:00:00Z", "metadata": {"labels": {"uptimer.cloudfoundry.org/run-id": "old-run"}}}
+				]}`
+				responses["cf curl --fail /v3/organizations?per_page=5000"] = `{"resources": [
+					{"guid": "old-org-guid", "name": "uptimer-org-old", "created_at": "2024-05-01T09:00:00Z", "metadata": {"labels": {"uptimer.cloudfoundry.org/run-id": "old-run"}}},
+					{"guid": "unlabelled-org-guid", "name": "uptimer-org-unlabelled", "created_at": "2024-05-01T08:00:00Z", "metadata": {"labels": {}}},
+					{"guid": "system-guid", "name": "system", "created_at": "2024-01-01T00:00:00Z", "metadata": {"labels": {}}}
+				]}`
+			})
+
+			It("does not find them", func() {
+				resources, err := cleaner.Find(time.Hour)
+
+				Expect(err).NotTo(HaveOccurred())
+				var names []string
+				for _, r := range resources {
+					names = append(names, r.Name)
+				}
+				Expect(names).To(Equal([]string{"uptimer-srv-1", "uptimer-org-old", "uptimer-quota-1"}))
+				Expect(ran).NotTo(ContainElement("cf curl --fail /v3/organizations?per_page=5000"))
+			})
+
+			Context("when asked to find unlabelled resources", func() {
+				BeforeEach(func() {
+					unlabelled = true
+				})
+
+				It("also finds them by the prefix of their names, once", func() {
+					resources, err := cleaner.Find(time.Hour)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resources).To(Equal([]Resource{
+						{Kind: "service", Name: "uptimer-srv-1", Guid: "srv-guid", CreatedAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), RunID: "old-run"},
+						{Kind: "service", Name: "uptimer-srv-2", Guid: "unlabelled-srv-guid", CreatedAt: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+						{Kind: "org", Name: "uptimer-org-old", Guid: "old-org-guid", CreatedAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), RunID: "old-run"},
+						{Kind: "org", Name: "uptimer-org-unlabelled", Guid: "unlabelled-org-guid", CreatedAt: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+						{Kind: "quota", Name: "uptimer-quota-1", Guid: "quota-guid", CreatedAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)},
+					}))
+				})
+			})
+		})
+
+		It("follows the pages of a list", func() {
+			responses["cf curl --fail /v3/organizations?label_selector=uptimer.cloudfoundry.org/run-id&per_page=5000"] = `{
+				"pagination": {"next": {"href": "https://api.example.com/v3/organizations?label_selector=uptimer.cloudfoundry.org%2Frun-id&page=2&per_page=5000"}},
+				"resources": [
+					{"guid": "old-org-guid", "name": "uptimer-org-old", "created_at": "2024-05-01T09:00:00Z", "metadata": {"labels": {"uptimer.cloudfoundry.org/run-id": "old-run"}}}
+				]
+			}`
+			responses["cf curl --fail /v3/organizations?label_selector=uptimer.cloudfoundry.org%2Frun-id&page=2&per_page=5000"] = `{
+				"pagination": {"next": null},
+				"resources": [
+					{"guid": "other-org-guid", "name": "uptimer-org-other", "created_at": "2024-05-01T09:00:00Z", "metadata": {"labels": {"uptimer.cloudfoundry.org/run-id": "other-run"}}}
+				]
+			}`
+
+			resources, err := cleaner.Find(time.Hour)
+
+			Expect(err).NotTo(HaveOccurred())
+			var names []string
+			for _, r := range resources {
+				names = append(names, r.Name)
+			}
+			Expect(names).To(Equal([]string{"uptimer-srv-1", "uptimer-org-old", "uptimer-org-other", "uptimer-quota-1"}))
+		})
+
+		It("does not accumulate output", func() {
+			_, err := cleaner.Find(0)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outBuf.Len()).To(Equal(0))
+		})
+
+		It("returns an error when a list fails", func() {
+			failures["cf curl --fail /v3/organizations?label_selector=uptimer.cloudfoundry.org/run-id&per_page=5000"] = "You are not authorized"
+
+			_, err := cleaner.Find(0)
+
+			Expect(err).To(MatchError("Failed to list orgs: exit status 1: You are not authorized"))
+		})
+
+		It("returns an error when a list cannot be decoded", func() {
+			responses["cf curl --fail /v3/organization_quotas?per_page=5000"] = "not json"
+
+			_, err := cleaner.Find(0)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("Failed to list quotas: "))
+		})
+	})
+
+	Describe("Delete", func() {
+		var resources []Resource
+
+		BeforeEach(func() {
+			resources = []Resource{
+				{Kind: "service", Name: "uptimer-srv-1", Guid: "srv-guid"},
+				{Kind: "org", Name: "uptimer-org-old", Guid: "old-org-guid"},
+				{Kind: "quota", Name: "uptimer-quota-1", Guid: "quota-guid"},
+			}
+		})
+
+		It("deletes each resource in turn", func() {
+			Expect(cleaner.Delete(resources)).To(Succeed())
+
+			Expect(ran).To(Equal([]string{
+				"cf curl --fail -X DELETE /v3/service_instances/srv-guid?purge=true",
+				"cf delete-org uptimer-org-old -f",
+				"cf delete-quota uptimer-quota-1 -f",
+			}))
+		})
+
+		It("reports what it deleted", func() {
+			Expect(cleaner.Delete(resources)).To(Succeed())
+
+			Expect(logBuf.String()).To(ContainSubstring("Deleted service uptimer-srv-1"))
+			Expect(logBuf.String()).To(ContainSubstring("Deleted org uptimer-org-old"))
+			Expect(logBuf.String()).To(ContainSubstring("Deleted quota uptimer-quota-1"))
+		})
+
+		It("goes on after a failure and returns every failure", func() {
+			failures["cf delete-org uptimer-org-old -f"] = "Org is busy"
+
+			err := cleaner.Delete(resources)
+
+			Expect(err).To(MatchError("org uptimer-org-old: exit status 1: Org is busy"))
+			Expect(ran).To(HaveLen(3))
+			Expect(logBuf.String()).To(ContainSubstring("Failed to delete org uptimer-org-old"))
+			Expect(logBuf.String()).To(ContainSubstring("Deleted quota uptimer-quota-1"))
+		})
+	})
+})
+
+var _ = Describe("Resource", func() {
+	It("names the run that created it", func() {
+		r := Resource{Kind: "org", Name: "uptimer-org-1", CreatedAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), RunID: "some-run"}
+
+		Expect(r.String()).To(Equal("org uptimer-org-1 (run some-run, created 2024-05-01T09:00:00Z)"))
+	})
+
+	It("leaves out the run when it is not known", func() {
+		r := Resource{Kind: "quota", Name: "uptimer-quota-1", CreatedAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)}
+
+		Expect(r.String()).To(Equal("quota uptimer-quota-1 (created 2024-05-01T09:00:00Z)"))
+	})
+})
@@ -0,0 +1,196 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package cleanupfakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/uptimer/cleanup"
+)
+
+type FakeCleaner struct {
+	DeleteStub        func([]cleanup.Resource) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		arg1 []cleanup.Resource
+	}
+	deleteReturns struct {
+		result1 error
+	}
+	deleteReturnsOnCall map[int]struct {
+		result1 error
+	}
+	FindStub        func(time.Duration) ([]cleanup.Resource, error)
+	findMutex       sync.RWMutex
+	findArgsForCall []struct {
+		arg1 time.Duration
+	}
+	findReturns struct {
+		result1 []cleanup.Resource
+		result2 error
+	}
+	findReturnsOnCall map[int]struct {
+		result1 []cleanup.Resource
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCleaner) Delete(arg1 []cleanup.Resource) error {
+	var arg1Copy []cleanup.Resource
+	if arg1 != nil {
+		arg1Copy = make([]cleanup.Resource, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.deleteMutex.Lock()
+	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		arg1 []cleanup.Resource
+	}{arg1Copy})
+	stub := fake.DeleteStub
+	fakeReturns := fake.deleteReturns
+	fake.recordInvocation("Delete", []interface{}{arg1Copy})
+	fake.deleteMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCleaner) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *FakeCleaner) DeleteCalls(stub func([]cleanup.Resource) error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = stub
+}
+
+func (fake *FakeCleaner) DeleteArgsForCall(i int) []cleanup.Resource {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	argsForCall := fake.deleteArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCleaner) DeleteReturns(result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCleaner) DeleteReturnsOnCall(i int, result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	if fake.deleteReturnsOnCall == nil {
+		fake.deleteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCleaner) Find(arg1 time.Duration) ([]cleanup.Resource, error) {
+	fake.findMutex.Lock()
+	ret, specificReturn := fake.findReturnsOnCall[len(fake.findArgsForCall)]
+	fake.findArgsForCall = append(fake.findArgsForCall, struct {
+		arg1 time.Duration
+	}{arg1})
+	stub := fake.FindStub
+	fakeReturns := fake.findReturns
+	fake.recordInvocation("Find", []interface{}{arg1})
+	fake.findMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCleaner) FindCallCount() int {
+	fake.findMutex.RLock()
+	defer fake.findMutex.RUnlock()
+	return len(fake.findArgsForCall)
+}
+
+func (fake *FakeCleaner) FindCalls(stub func(time.Duration) ([]cleanup.Resource, error)) {
+	fake.findMutex.Lock()
+	defer fake.findMutex.Unlock()
+	fake.FindStub = stub
+}
+
+func (fake *FakeCleaner) FindArgsForCall(i int) time.Duration {
+	fake.findMutex.RLock()
+	defer fake.findMutex.RUnlock()
+	argsForCall := fake.findArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCleaner) FindReturns(result1 []cleanup.Resource, result2 error) {
+	fake.findMutex.Lock()
+	defer fake.findMutex.Unlock()
+	fake.FindStub = nil
+	fake.findReturns = struct {
+		result1 []cleanup.Resource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCleaner) FindReturnsOnCall(i int, result1 []cleanup.Resource, result2 error) {
+	fake.findMutex.Lock()
+	defer fake.findMutex.Unlock()
+	fake.FindStub = nil
+	if fake.findReturnsOnCall == nil {
+		fake.findReturnsOnCall = make(map[int]struct {
+			result1 []cleanup.Resource
+			result2 error
+		})
+	}
+	fake.findReturnsOnCall[i] = struct {
+		result1 []cleanup.Resource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCleaner) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	fake.findMutex.RLock()
+	defer fake.findMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeCleaner) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ cleanup.Cleaner = new(FakeCleaner)
@@ -2,7 +2,11 @@ package cmdRunner
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"os/exec"
+	"sync/atomic"
+	"time"
 
 	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
 )
@@ -20,6 +24,7 @@ type cmdRunner struct {
 	outWriter io.Writer
 	errWriter io.Writer
 	copyFunc  copyFunc
+	timeouts  Timeouts
 }
 
 type copyFunc func(io.Writer, io.Reader) (int64, error)
@@ -32,6 +37,22 @@ func New(outWriter, errWriter io.Writer, copyFunc copyFunc) CmdRunner {
 	}
 }
 
+// NewWithTimeouts returns a CmdRunner like New's that kills commands running
+// longer than their timeout, along with the processes they started, and
+// fails them with an error saying they timed out. Only exec.Cmds can be
+// killed; other commands are left to finish.
+//
+// Every CmdRunner kills the exec.Cmds it runs with a context, along with the
+// processes they started, once the context is done.
+func NewWithTimeouts(outWriter, errWriter io.Writer, copyFunc copyFunc, timeouts Timeouts) CmdRunner {
+	return &cmdRunner{
+		outWriter: outWriter,
+		errWriter: errWriter,
+		copyFunc:  copyFunc,
+		timeouts:  timeouts,
+	}
+}
+
 func (r *cmdRunner) Run(cmdStartWaiter cmdStartWaiter.CmdStartWaiter) error {
 	return r.RunWithContext(context.TODO(), cmdStartWaiter)
 }
@@ -41,6 +62,9 @@ func (r *cmdRunner) RunInSequence(cmdStartWaiters ...cmdStartWaiter.CmdStartWait
 
 func (r *cmdRunner) RunInSequenceWithContext(ctx context.Context, csws ...cmdStartWaiter.CmdStartWaiter) error {
 	for _, cmd := range csws {
+		if wasCanceledOrTimedOut(ctx) {
+			return nil
+		}
 		if err := r.RunWithContext(ctx, cmd); err != nil {
 			return err
 		}
@@ -60,10 +84,33 @@ func (r *cmdRunner) RunWithContext(ctx context.Context, csw cmdStartWaiter.CmdSt
 		return err
 	}
 
+	timeout := r.timeouts.For(csw)
+	cancelable := ctx.Done() != nil
+	cmd, killable := csw.(*exec.Cmd)
+	if killable && (timeout > 0 || cancelable) {
+		startProcessGroup(cmd)
+	}
+
 	if err := csw.Start(); err != nil {
 		return err
 	}
 
+	if killable && cancelable {
+		stop := context.AfterFunc(ctx, func() {
+			killProcessGroup(cmd)
+		})
+		defer stop()
+	}
+
+	var timedOut atomic.Bool
+	if timeout > 0 && killable {
+		timer := time.AfterFunc(timeout, func() {
+			timedOut.Store(true)
+			killProcessGroup(cmd)
+		})
+		defer timer.Stop()
+	}
+
 	if _, err := r.copyFunc(r.outWriter, stdoutPipe); err != nil {
 		return err
 	}
@@ -72,8 +119,13 @@ func (r *cmdRunner) RunWithContext(ctx context.Context, csw cmdStartWaiter.CmdSt
 		return err
 	}
 
+	err = csw.Wait()
+	if timedOut.Load() {
+		return fmt.Errorf("`%s` timed out after %gs", commandLine(csw), timeout.Seconds())
+	}
+
 	// Ignore error due to context cancelation/timeout
-	if err = csw.Wait(); err != nil && !wasCanceledOrTimedOut(ctx) {
+	if err != nil && !wasCanceledOrTimedOut(ctx) {
 		return err
 	}
 
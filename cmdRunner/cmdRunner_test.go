@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os/exec"
 	"time"
 
 	. "github.com/cloudfoundry/uptimer/cmdRunner"
@@ -153,6 +154,49 @@ var _ = Describe("CmdRunner", func() {
 
 			Expect(err).To(MatchError("some error dude"))
 		})
+
+		It("kills a command once the context is done, along with the processes it started", func() {
+			ctx, cancelFunc := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancelFunc()
+			start := time.Now()
+
+			err := runner.RunWithContext(ctx, exec.Command("sh", "-c", "echo started; sleep 10 & sleep 10"))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			Expect(outBuf.String()).To(Equal("started\n"))
+		})
+	})
+
+	Describe("with timeouts", func() {
+		BeforeEach(func() {
+			runner = NewWithTimeouts(outBuf, errBuf, io.Copy, Timeouts{
+				Default:  time.Minute,
+				Commands: map[string]time.Duration{"sh": 50 * time.Millisecond},
+			})
+		})
+
+		It("kills a command that runs too long, along with the processes it started", func() {
+			start := time.Now()
+
+			err := runner.Run(exec.Command("sh", "-c", "echo started; sleep 10 & sleep 10"))
+
+			Expect(err).To(MatchError("`sh -c echo started; sleep 10 & sleep 10` timed out after 0.05s"))
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			Expect(outBuf.String()).To(Equal("started\n"))
+		})
+
+		It("runs commands that finish in time as usual", func() {
+			Expect(runner.Run(exec.Command("sh", "-c", "echo done"))).To(Succeed())
+
+			Expect(outBuf.String()).To(Equal("done\n"))
+		})
+
+		It("returns the error of a command that fails in time", func() {
+			err := runner.Run(exec.Command("sh", "-c", "exit 3"))
+
+			Expect(err).To(MatchError("exit status 3"))
+		})
 	})
 
 	Describe("RunInSequence", func() {
@@ -193,5 +237,19 @@ var _ = Describe("CmdRunner", func() {
 			Expect(err).To(MatchError("something even worse happened"))
 			Expect(outBuf.String()).To(Equal("1"))
 		})
+
+		It("does not run the commands left once the context is done", func() {
+			ctx, cancelFunc := context.WithCancel(context.Background())
+			fakeCmdStartWaiter.WaitStub = func() error {
+				cancelFunc()
+				return nil
+			}
+
+			err := runner.RunInSequenceWithContext(ctx, fakeCmdStartWaiter, fakeCmdStartWaiter2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeCmdStartWaiter2.StartCallCount()).To(BeZero())
+			Expect(outBuf.String()).To(Equal("1"))
+		})
 	})
 })
@@ -0,0 +1,84 @@
+package cmdRunner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+type dryRun struct {
+	writer     io.Writer
+	workingDir string
+}
+
+// NewDryRun returns a CmdRunner that writes a description of each command to
+// writer instead of running it. Commands without a directory of their own
+// are described as running in workingDir.
+func NewDryRun(writer io.Writer, workingDir string) CmdRunner {
+	return &dryRun{
+		writer:     writer,
+		workingDir: workingDir,
+	}
+}
+
+func (r *dryRun) Run(csw cmdStartWaiter.CmdStartWaiter) error {
+	return r.RunWithContext(context.TODO(), csw)
+}
+
+func (r *dryRun) RunInSequence(csws ...cmdStartWaiter.CmdStartWaiter) error {
+	return r.RunInSequenceWithContext(context.TODO(), csws...)
+}
+
+func (r *dryRun) RunInSequenceWithContext(ctx context.Context, csws ...cmdStartWaiter.CmdStartWaiter) error {
+	for _, csw := range csws {
+		if err := r.RunWithContext(ctx, csw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *dryRun) RunWithContext(_ context.Context, csw cmdStartWaiter.CmdStartWaiter) error {
+	_, err := fmt.Fprintln(r.writer, r.describe(csw))
+	return err
+}
+
+// describe returns the command line of an exec.Cmd with its CF_HOME and
+// directory. Other commands, such as the operations of the API backend,
+// describe themselves.
+func (r *dryRun) describe(csw cmdStartWaiter.CmdStartWaiter) string {
+	cmd, ok := csw.(*exec.Cmd)
+	if !ok {
+		return commandLine(csw)
+	}
+
+	var parts []string
+	for _, env := range cmd.Env {
+		if strings.HasPrefix(env, "CF_HOME=") {
+			parts = append(parts, env)
+		}
+	}
+
+	dir := cmd.Dir
+	if dir == "" {
+		dir = r.workingDir
+	}
+	parts = append(parts, "(in "+dir+")", commandLine(cmd))
+
+	return strings.Join(parts, " ")
+}
+
+// commandLine names a command by its arguments, or by its description if it
+// is not an exec.Cmd.
+func commandLine(csw cmdStartWaiter.CmdStartWaiter) string {
+	if cmd, ok := csw.(*exec.Cmd); ok {
+		return strings.Join(cmd.Args, " ")
+	}
+
+	return fmt.Sprintf("%v", csw)
+}
@@ -0,0 +1,78 @@
+package cmdRunner_test
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+
+	. "github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter/cmdStartWaiterfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type describedCmd struct {
+	*cmdStartWaiterfakes.FakeCmdStartWaiter
+}
+
+func (describedCmd) String() string {
+	return "auth admin"
+}
+
+var _ = Describe("DryRun", func() {
+	var (
+		outBuf *bytes.Buffer
+
+		runner CmdRunner
+	)
+
+	BeforeEach(func() {
+		outBuf = bytes.NewBuffer([]byte{})
+
+		runner = NewDryRun(outBuf, "/home/uptimer")
+	})
+
+	It("describes commands with their CF_HOME and directory", func() {
+		cmd := exec.Command("cf", "push", "my-app", "-p", "/path/to/app")
+		cmd.Env = []string{"PATH=/usr/bin", "CF_HOME=/tmp/cf-home"}
+		cmd.Dir = "/path/to/app"
+
+		err := runner.Run(cmd)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outBuf.String()).To(Equal("CF_HOME=/tmp/cf-home (in /path/to/app) cf push my-app -p /path/to/app\n"))
+	})
+
+	It("describes commands without a directory as running in the working directory", func() {
+		err := runner.Run(exec.Command("sleep", "600"))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outBuf.String()).To(Equal("(in /home/uptimer) sleep 600\n"))
+	})
+
+	It("lets other commands describe themselves without running them", func() {
+		fakeCmdStartWaiter := &cmdStartWaiterfakes.FakeCmdStartWaiter{}
+
+		err := runner.RunInSequence(describedCmd{fakeCmdStartWaiter}, describedCmd{fakeCmdStartWaiter})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outBuf.String()).To(Equal("auth admin\nauth admin\n"))
+		Expect(fakeCmdStartWaiter.StartCallCount()).To(Equal(0))
+		Expect(fakeCmdStartWaiter.WaitCallCount()).To(Equal(0))
+	})
+
+	It("returns an error when the description cannot be written", func() {
+		runner = NewDryRun(failingWriter{}, "/home/uptimer")
+
+		err := runner.Run(exec.Command("sleep", "600"))
+
+		Expect(err).To(MatchError("write failed"))
+	})
+})
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, fmt.Errorf("write failed")
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+// +build !windows
+
+package cmdRunner
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// startProcessGroup makes cmd start a process group of its own, so that the
+// processes it starts can be killed along with it.
+func startProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+
+	// The group may have exited already; then there is nothing to kill.
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
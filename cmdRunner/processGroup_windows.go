@@ -0,0 +1,16 @@
+package cmdRunner
+
+import (
+	"os/exec"
+)
+
+// startProcessGroup does nothing on Windows, where only cmd itself is killed.
+func startProcessGroup(*exec.Cmd) {}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+
+	_ = cmd.Process.Kill()
+}
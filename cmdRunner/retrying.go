@@ -0,0 +1,159 @@
+package cmdRunner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+// Backoff is how often a failed command is retried, and how long to wait
+// before each retry: Initial at first, twice as long after each further
+// failure, but never longer than Max, unless Max is 0.
+type Backoff struct {
+	Retries int
+	Initial time.Duration
+	Max     time.Duration
+}
+
+func (b Backoff) wait(retry int) time.Duration {
+	wait := b.Initial
+	for i := 1; i < retry && (b.Max == 0 || wait < b.Max); i++ {
+		wait *= 2
+	}
+	if b.Max > 0 && wait > b.Max {
+		return b.Max
+	}
+
+	return wait
+}
+
+// Renewable is implemented by commands other than exec.Cmds that can be
+// retried. Renew returns a copy of the command that has not been started.
+type Renewable interface {
+	Renew() cmdStartWaiter.CmdStartWaiter
+}
+
+type retrying struct {
+	runner  CmdRunner
+	logger  *log.Logger
+	clock   clock.Clock
+	backoff Backoff
+	outBuf  *bytes.Buffer
+	errBuf  *bytes.Buffer
+}
+
+// NewRetrying returns a CmdRunner that runs each command with runner, which
+// writes their output to outBuf and errBuf, and retries the commands that
+// fail according to backoff. Commands are retried as a whole, so they have to
+// be idempotent.
+//
+// The buffers are reset before each command, so that they only hold the
+// output of the command that failed. Each failure that is retried is logged
+// with that output; after the last one, the output is left in the buffers.
+func NewRetrying(
+	runner CmdRunner,
+	logger *log.Logger,
+	clock clock.Clock,
+	backoff Backoff,
+	outBuf, errBuf *bytes.Buffer,
+) CmdRunner {
+	return &retrying{
+		runner:  runner,
+		logger:  logger,
+		clock:   clock,
+		backoff: backoff,
+		outBuf:  outBuf,
+		errBuf:  errBuf,
+	}
+}
+
+func (r *retrying) Run(csw cmdStartWaiter.CmdStartWaiter) error {
+	return r.RunWithContext(context.TODO(), csw)
+}
+
+func (r *retrying) RunInSequence(csws ...cmdStartWaiter.CmdStartWaiter) error {
+	return r.RunInSequenceWithContext(context.TODO(), csws...)
+}
+
+func (r *retrying) RunInSequenceWithContext(ctx context.Context, csws ...cmdStartWaiter.CmdStartWaiter) error {
+	for _, csw := range csws {
+		if wasCanceledOrTimedOut(ctx) {
+			return nil
+		}
+		if err := r.RunWithContext(ctx, csw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *retrying) RunWithContext(ctx context.Context, csw cmdStartWaiter.CmdStartWaiter) error {
+	name := commandLine(csw)
+	attempts := r.backoff.Retries + 1
+
+	for attempt := 1; ; attempt++ {
+		r.outBuf.Reset()
+		r.errBuf.Reset()
+
+		err := r.runner.RunWithContext(ctx, csw)
+		if err == nil {
+			return nil
+		}
+
+		// A command that cannot be found will not be found on a retry either.
+		var execErr *exec.Error
+		if attempt == attempts || errors.As(err, &execErr) {
+			if attempt == 1 {
+				return fmt.Errorf("step `%s` failed: %w", name, err)
+			}
+			return fmt.Errorf("step `%s` failed %d times: %w", name, attempt, err)
+		}
+
+		renewed, ok := renew(csw)
+		if !ok {
+			return fmt.Errorf("step `%s` failed and cannot be retried: %w", name, err)
+		}
+		csw = renewed
+
+		wait := r.backoff.wait(attempt)
+		r.logger.Printf(
+			"Step `%s` failed (attempt %d of %d), retrying in %s: %v\nstdout:\n%s\nstderr:\n%s\n",
+			name,
+			attempt,
+			attempts,
+			wait,
+			err,
+			r.outBuf.String(),
+			r.errBuf.String(),
+		)
+		r.clock.Sleep(wait)
+	}
+}
+
+// renew returns a copy of csw that can be started, if there is a way to get
+// one.
+func renew(csw cmdStartWaiter.CmdStartWaiter) (cmdStartWaiter.CmdStartWaiter, bool) {
+	switch cmd := csw.(type) {
+	case *exec.Cmd:
+		return &exec.Cmd{
+			Path: cmd.Path,
+			Args: cmd.Args,
+			Env:  cmd.Env,
+			Dir:  cmd.Dir,
+			Err:  cmd.Err,
+		}, true
+	case Renewable:
+		return cmd.Renew(), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,204 @@
+package cmdRunner_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os/exec"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	. "github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/cmdRunner/cmdRunnerfakes"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter/cmdStartWaiterfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type renewableCmd struct {
+	*cmdStartWaiterfakes.FakeCmdStartWaiter
+	renewals *int
+}
+
+func (c renewableCmd) Renew() cmdStartWaiter.CmdStartWaiter {
+	*c.renewals++
+	return c
+}
+
+func (renewableCmd) String() string {
+	return "create-org my-org"
+}
+
+var _ = Describe("Retrying", func() {
+	var (
+		logBuf     *bytes.Buffer
+		outBuf     *bytes.Buffer
+		errBuf     *bytes.Buffer
+		fakeRunner *cmdRunnerfakes.FakeCmdRunner
+		backoff    Backoff
+		failures   int
+		ran        []cmdStartWaiter.CmdStartWaiter
+
+		runner CmdRunner
+	)
+
+	BeforeEach(func() {
+		logBuf = bytes.NewBuffer([]byte{})
+		outBuf = bytes.NewBuffer([]byte{})
+		errBuf = bytes.NewBuffer([]byte{})
+		backoff = Backoff{Retries: 3, Initial: time.Millisecond, Max: 3 * time.Millisecond}
+		failures = 0
+		ran = nil
+
+		fakeRunner = &cmdRunnerfakes.FakeCmdRunner{}
+		fakeRunner.RunWithContextStub = func(_ context.Context, csw cmdStartWaiter.CmdStartWaiter) error {
+			ran = append(ran, csw)
+			outBuf.WriteString("Creating org my-org...\n")
+			if len(ran) <= failures {
+				errBuf.WriteString("502 Bad Gateway\n")
+				return errors.New("exit status 1")
+			}
+			return nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		runner = NewRetrying(fakeRunner, log.New(logBuf, "", 0), clock.New(), backoff, outBuf, errBuf)
+	})
+
+	It("runs each command once when it succeeds", func() {
+		cmd1 := exec.Command("cf", "create-org", "my-org")
+		cmd2 := exec.Command("cf", "create-space", "my-space")
+
+		Expect(runner.RunInSequence(cmd1, cmd2)).To(Succeed())
+
+		Expect(ran).To(Equal([]cmdStartWaiter.CmdStartWaiter{cmd1, cmd2}))
+		Expect(logBuf.String()).To(BeEmpty())
+	})
+
+	It("only keeps the output of the last command", func() {
+		Expect(runner.RunInSequence(exec.Command("cf", "api"), exec.Command("cf", "auth"))).To(Succeed())
+
+		Expect(outBuf.String()).To(Equal("Creating org my-org...\n"))
+	})
+
+	It("does not run the commands left once the context is done", func() {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		cmd1 := exec.Command("cf", "create-org", "my-org")
+		fakeRunner.RunWithContextStub = func(_ context.Context, csw cmdStartWaiter.CmdStartWaiter) error {
+			ran = append(ran, csw)
+			cancelFunc()
+			return nil
+		}
+
+		Expect(runner.RunInSequenceWithContext(ctx, cmd1, exec.Command("cf", "create-space", "my-space"))).To(Succeed())
+
+		Expect(ran).To(Equal([]cmdStartWaiter.CmdStartWaiter{cmd1}))
+	})
+
+	Context("when a command fails before succeeding", func() {
+		BeforeEach(func() {
+			failures = 2
+		})
+
+		It("retries it with a copy of the command", func() {
+			cmd := exec.Command("cf", "create-org", "my-org")
+			cmd.Env = []string{"CF_HOME=/cfhome"}
+			cmd.Dir = "/some/dir"
+
+			Expect(runner.Run(cmd)).To(Succeed())
+
+			Expect(ran).To(HaveLen(3))
+			for _, retried := range ran[1:] {
+				retriedCmd, ok := retried.(*exec.Cmd)
+				Expect(ok).To(BeTrue())
+				Expect(retriedCmd).NotTo(BeIdenticalTo(cmd))
+				Expect(retriedCmd.Path).To(Equal(cmd.Path))
+				Expect(retriedCmd.Args).To(Equal(cmd.Args))
+				Expect(retriedCmd.Env).To(Equal(cmd.Env))
+				Expect(retriedCmd.Dir).To(Equal(cmd.Dir))
+			}
+		})
+
+		It("logs each failure by name with its output", func() {
+			Expect(runner.Run(exec.Command("cf", "create-org", "my-org"))).To(Succeed())
+
+			Expect(logBuf.String()).To(Equal(
+				"Step `cf create-org my-org` failed (attempt 1 of 4), retrying in 1ms: exit status 1\n" +
+					"stdout:\nCreating org my-org...\n\nstderr:\n502 Bad Gateway\n\n" +
+					"Step `cf create-org my-org` failed (attempt 2 of 4), retrying in 2ms: exit status 1\n" +
+					"stdout:\nCreating org my-org...\n\nstderr:\n502 Bad Gateway\n\n",
+			))
+		})
+
+		It("renews commands that are not exec.Cmds", func() {
+			renewals := 0
+			cmd := renewableCmd{FakeCmdStartWaiter: &cmdStartWaiterfakes.FakeCmdStartWaiter{}, renewals: &renewals}
+
+			Expect(runner.Run(cmd)).To(Succeed())
+
+			Expect(renewals).To(Equal(2))
+			Expect(logBuf.String()).To(ContainSubstring("Step `create-org my-org` failed (attempt 1 of 4)"))
+		})
+
+		It("does not retry commands that cannot be found", func() {
+			fakeRunner.RunWithContextStub = func(_ context.Context, csw cmdStartWaiter.CmdStartWaiter) error {
+				ran = append(ran, csw)
+				return &exec.Error{Name: "cf", Err: exec.ErrNotFound}
+			}
+
+			err := runner.Run(exec.Command("cf", "create-org", "my-org"))
+
+			Expect(err).To(MatchError("step `cf create-org my-org` failed: exec: \"cf\": executable file not found in $PATH"))
+			Expect(ran).To(HaveLen(1))
+		})
+
+		It("does not retry commands it cannot renew", func() {
+			err := runner.Run(&cmdStartWaiterfakes.FakeCmdStartWaiter{})
+
+			Expect(err).To(MatchError(ContainSubstring("failed and cannot be retried: exit status 1")))
+			Expect(ran).To(HaveLen(1))
+		})
+	})
+
+	Context("when a command keeps failing", func() {
+		BeforeEach(func() {
+			failures = 100
+		})
+
+		It("gives up after the retries, leaving the last output in the buffers", func() {
+			err := runner.RunInSequence(exec.Command("cf", "create-org", "my-org"), exec.Command("cf", "create-space", "my-space"))
+
+			Expect(err).To(MatchError("step `cf create-org my-org` failed 4 times: exit status 1"))
+			Expect(ran).To(HaveLen(4))
+			Expect(outBuf.String()).To(Equal("Creating org my-org...\n"))
+			Expect(errBuf.String()).To(Equal("502 Bad Gateway\n"))
+		})
+
+		It("doubles the wait up to the maximum", func() {
+			Expect(runner.Run(exec.Command("cf", "create-org", "my-org"))).NotTo(Succeed())
+
+			Expect(logBuf.String()).To(ContainSubstring("(attempt 1 of 4), retrying in 1ms"))
+			Expect(logBuf.String()).To(ContainSubstring("(attempt 2 of 4), retrying in 2ms"))
+			Expect(logBuf.String()).To(ContainSubstring("(attempt 3 of 4), retrying in 3ms"))
+		})
+
+		Context("without retries", func() {
+			BeforeEach(func() {
+				backoff.Retries = 0
+			})
+
+			It("fails right away", func() {
+				err := runner.Run(exec.Command("cf", "create-org", "my-org"))
+
+				Expect(err).To(MatchError("step `cf create-org my-org` failed: exit status 1"))
+				Expect(ran).To(HaveLen(1))
+				Expect(logBuf.String()).To(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,40 @@
+package cmdRunner
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+// Timeouts limit how long commands may run, by kind of command, i.e. the cf
+// subcommand such as "push". Commands of a kind without a timeout of its own
+// get Default. A timeout of 0 means none.
+type Timeouts struct {
+	Default  time.Duration
+	Commands map[string]time.Duration
+}
+
+// For returns the timeout of csw.
+func (t Timeouts) For(csw cmdStartWaiter.CmdStartWaiter) time.Duration {
+	if timeout, ok := t.Commands[commandKind(csw)]; ok {
+		return timeout
+	}
+
+	return t.Default
+}
+
+// commandKind is the cf subcommand csw runs, or the first word of its
+// description for commands that are not cf CLI commands.
+func commandKind(csw cmdStartWaiter.CmdStartWaiter) string {
+	words := strings.Fields(commandLine(csw))
+	if len(words) == 0 {
+		return ""
+	}
+	if len(words) > 1 && filepath.Base(words[0]) == "cf" {
+		return words[1]
+	}
+
+	return words[0]
+}
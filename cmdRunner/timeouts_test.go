@@ -0,0 +1,51 @@
+package cmdRunner_test
+
+import (
+	"os/exec"
+	"time"
+
+	. "github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter/cmdStartWaiterfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Timeouts", func() {
+	var timeouts Timeouts
+
+	BeforeEach(func() {
+		timeouts = Timeouts{
+			Default: time.Minute,
+			Commands: map[string]time.Duration{
+				"push": 10 * time.Minute,
+				"logs": 0,
+				"auth": 30 * time.Second,
+			},
+		}
+	})
+
+	It("returns the timeout of the cf subcommand", func() {
+		Expect(timeouts.For(exec.Command("cf", "push", "my-app", "-p", "/some/path"))).To(Equal(10 * time.Minute))
+		Expect(timeouts.For(exec.Command("/usr/local/bin/cf", "push", "my-app"))).To(Equal(10 * time.Minute))
+	})
+
+	It("returns no timeout for a kind of command set to 0", func() {
+		Expect(timeouts.For(exec.Command("cf", "logs", "my-app"))).To(BeZero())
+	})
+
+	It("returns the default for other commands", func() {
+		Expect(timeouts.For(exec.Command("cf", "delete", "my-app", "-f"))).To(Equal(time.Minute))
+		Expect(timeouts.For(exec.Command("curl", "push"))).To(Equal(time.Minute))
+	})
+
+	It("uses the first word of the description of commands that are not exec.Cmds", func() {
+		cmd := describedCmd{FakeCmdStartWaiter: &cmdStartWaiterfakes.FakeCmdStartWaiter{}}
+
+		Expect(timeouts.For(cmd)).To(Equal(30 * time.Second))
+	})
+
+	It("returns no timeouts when there are none", func() {
+		Expect(Timeouts{}.For(exec.Command("cf", "push", "my-app"))).To(BeZero())
+	})
+})
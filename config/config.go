@@ -1,16 +1,24 @@
 package config
 
-import (
-	"encoding/json"
-	"errors"
-	"os"
-)
-
 type Config struct {
 	While           []*Command      `json:"while"`
 	CF              *Cf             `json:"cf"`
 	OptionalTests   OptionalTests   `json:"optional_tests"`
 	AllowedFailures AllowedFailures `json:"allowed_failures"`
+	SyslogDrain     SyslogDrain     `json:"syslog_drain"`
+	DockerImage     DockerImage     `json:"docker_image"`
+	Apps            Apps            `json:"apps"`
+	SetupRetries    *SetupRetries   `json:"setup_retries"`
+	Timeouts        *Timeouts       `json:"timeouts"`
+
+	PushabilityMatrix []PushabilityMatrixEntry `json:"pushability_matrix"`
+	Foundations       []*Foundation            `json:"foundations"`
+}
+
+// Foundation is one of several CF foundations measured in the same run.
+type Foundation struct {
+	Name string `json:"name"`
+	CF   *Cf    `json:"cf"`
 }
 
 type Command struct {
@@ -23,15 +31,39 @@ type Cf struct {
 	AppDomain        string `json:"app_domain"`
 	AdminUser        string `json:"admin_user"`
 	AdminPassword    string `json:"admin_password"`
+	ClientID         string `json:"client_id"`
+	ClientSecret     string `json:"client_secret"`
 	IsolationSegment string `json:"isolation_segment"`
+	LogCacheURL      string `json:"log_cache_url"`
 
 	TCPDomain     string `json:"tcp_domain"`
 	TCPPort       int    `json:"tcp_port"`
 	AvailablePort int    `json:"available_port"`
 
 	UseSingleAppInstance bool `json:"use_single_app_instance"`
+
+	// ExistingSpace makes uptimer run in an org and space that already
+	// exist instead of creating its own.
+	ExistingSpace *ExistingSpace `json:"existing_space"`
+
+	// Backend selects how uptimer talks to CF: BackendCLI (the default) runs
+	// the cf CLI, BackendAPI calls the Cloud Controller and UAA APIs.
+	Backend string `json:"backend"`
 }
 
+// ExistingSpace names an org and space that uptimer pushes its apps to
+// without creating, changing or deleting them. This needs no more than the
+// space developer role.
+type ExistingSpace struct {
+	Org   string `json:"org"`
+	Space string `json:"space"`
+}
+
+const (
+	BackendCLI = "cli"
+	BackendAPI = "api"
+)
+
 type AllowedFailures struct {
 	AppPushability        int `json:"app_pushability"`
 	HttpAvailability      int `json:"http_availability"`
@@ -40,35 +72,121 @@ type AllowedFailures struct {
 	AppStats              int `json:"app_stats"`
 	AppSyslogAvailability int `json:"app_syslog_availability"`
 	TCPAvailability       int `json:"tcp_availability"`
+
+	AggregateSyslogAvailability int `json:"aggregate_syslog_availability"`
+	LogCacheAvailability        int `json:"log_cache_availability"`
+	RollingDeploy               int `json:"rolling_deploy"`
+	DockerPushability           int `json:"docker_pushability"`
+}
+
+type SyslogDrain struct {
+	Scheme         string  `json:"scheme"`
+	TLSCertFile    string  `json:"tls_cert_file"`
+	TLSKeyFile     string  `json:"tls_key_file"`
+	MaxLossPercent float64 `json:"max_loss_percent"`
+
+	AggregateSinkURL string `json:"aggregate_sink_url"`
 }
 
 type OptionalTests struct {
 	RunAppSyslogAvailability bool `json:"run_app_syslog_availability"`
 	RunTcpAvailability       bool `json:"run_tcp_availability"`
+
+	RunAggregateSyslogAvailability bool `json:"run_aggregate_syslog_availability"`
+	RunLogCacheAvailability        bool `json:"run_log_cache_availability"`
+	RunRollingDeploy               bool `json:"run_rolling_deploy"`
+	RunDockerPushability           bool `json:"run_docker_pushability"`
 }
 
-func Load(filename string) (*Config, error) {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
-	}
+// Apps holds paths to user supplied apps, each a directory or zip file with
+// a manifest.yml, to push instead of the included ones.
+type Apps struct {
+	App        string `json:"app"`
+	TCPApp     string `json:"tcp_app"`
+	SyslogSink string `json:"syslog_sink"`
+}
 
-	newConfig := &Config{}
-	err = json.Unmarshal(data, newConfig)
+// PushabilityMatrixEntry is one buildpack, stack and app combination that is
+// measured as its own pushability measurement.
+type PushabilityMatrixEntry struct {
+	Name            string `json:"name"`
+	Buildpack       string `json:"buildpack"`
+	Stack           string `json:"stack"`
+	App             string `json:"app"`
+	AllowedFailures int    `json:"allowed_failures"`
+}
 
-	return newConfig, err
+// SetupRetries is how often a setup step that fails is retried, and how
+// long to wait before each retry: InitialBackoffSeconds at first, then twice
+// as long after each further failure, up to MaxBackoffSeconds.
+type SetupRetries struct {
+	Retries               int `json:"retries"`
+	InitialBackoffSeconds int `json:"initial_backoff_seconds"`
+	MaxBackoffSeconds     int `json:"max_backoff_seconds"`
 }
 
-func (c Config) Validate() error {
-	if c.OptionalTests.RunAppSyslogAvailability {
-		if c.CF != nil && (c.CF.TCPDomain == "" || c.CF.AvailablePort == 0) {
-			return errors.New("`cf.tcp_domain` and `cf.available_port` must be set in order to run App Syslog Availability tests")
-		}
+// DefaultSetupRetries retry a failed setup step after 5, 10 and 20 seconds.
+var DefaultSetupRetries = SetupRetries{
+	Retries:               3,
+	InitialBackoffSeconds: 5,
+	MaxBackoffSeconds:     60,
+}
+
+// Timeouts limit how long cf commands and measurement attempts may take, in
+// seconds. A command whose cf subcommand, e.g. "push", is listed in Commands
+// gets the timeout listed for it; other commands get CommandSeconds. 0 means
+// no limit.
+type Timeouts struct {
+	CommandSeconds int            `json:"command_seconds"`
+	Commands       map[string]int `json:"commands"`
+	AttemptSeconds int            `json:"attempt_seconds"`
+}
+
+// DefaultTimeouts kill a cf command after 10 minutes, and fail a measurement
+// attempt after 15.
+var DefaultTimeouts = Timeouts{
+	CommandSeconds: 600,
+	AttemptSeconds: 900,
+}
+
+type DockerImage struct {
+	Image    string `json:"image"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// AllFoundations returns the configured foundations, or a single unnamed
+// foundation for the `cf` section when no foundations are listed.
+func (c Config) AllFoundations() []*Foundation {
+	if len(c.Foundations) == 0 {
+		return []*Foundation{{CF: c.CF}}
+	}
+
+	return c.Foundations
+}
+
+// SetupRetriesOrDefault returns the configured setup retries, or
+// DefaultSetupRetries when there are none.
+func (c Config) SetupRetriesOrDefault() SetupRetries {
+	if c.SetupRetries == nil {
+		return DefaultSetupRetries
 	}
-	if c.OptionalTests.RunTcpAvailability {
-		if c.CF != nil && (c.CF.TCPDomain == "" || c.CF.TCPPort == 0) {
-			return errors.New("`cf.tcp_domain` and `cf.tcp_port` must be set in order to run TCP Availability tests")
-		}
+
+	return *c.SetupRetries
+}
+
+// TimeoutsOrDefault returns the configured timeouts, or DefaultTimeouts when
+// there are none.
+func (c Config) TimeoutsOrDefault() Timeouts {
+	if c.Timeouts == nil {
+		return DefaultTimeouts
 	}
-	return nil
+
+	return *c.Timeouts
+}
+
+// UsesClientCredentials reports whether uptimer authenticates as a UAA client
+// rather than as an admin user.
+func (c *Cf) UsesClientCredentials() bool {
+	return c.ClientID != "" || c.ClientSecret != ""
 }
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeKey lists the files a config file is laid over, relative to it.
+const includeKey = "include"
+
+var interpolation = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Load reads a JSON or YAML config file. Its included files are merged
+// first, in order, and its own settings are laid over them. `${VAR}` in
+// string values is replaced with the environment variable VAR. Unknown keys
+// are rejected.
+func Load(filename string) (*Config, error) {
+	settings, err := loadSettings(filename, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	newConfig := &Config{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(newConfig); err != nil {
+		return newConfig, fmt.Errorf("invalid config %s: %s", filename, err)
+	}
+
+	return newConfig, newConfig.resolveSecrets()
+}
+
+// loadSettings returns the settings of a config file merged over those of
+// the files it includes. including holds the files being loaded, to detect
+// include cycles.
+func loadSettings(filename string, including map[string]bool) (map[string]interface{}, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	if including[path] {
+		return nil, fmt.Errorf("config %s includes itself", filename)
+	}
+	including[path] = true
+	defer delete(including, path)
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed interface{}
+	if json.Valid(data) {
+		err = json.Unmarshal(data, &parsed)
+	} else {
+		err = yaml.Unmarshal(data, &parsed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %s", filename, err)
+	}
+	if parsed == nil {
+		parsed = map[string]interface{}{}
+	}
+	settings, ok := parsed.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("config %s must be a mapping of settings", filename)
+	}
+
+	interpolated, err := interpolate(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to interpolate config %s: %s", filename, err)
+	}
+	settings = interpolated.(map[string]interface{})
+
+	includes, err := includedFiles(settings[includeKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid `%s` in config %s: %s", includeKey, filename, err)
+	}
+	delete(settings, includeKey)
+
+	merged := map[string]interface{}{}
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(filename), include)
+		}
+		included, err := loadSettings(include, including)
+		if err != nil {
+			return nil, err
+		}
+		merged = merge(merged, included)
+	}
+
+	return merge(merged, settings), nil
+}
+
+func includedFiles(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		files := make([]string, 0, len(v))
+		for _, file := range v {
+			s, ok := file.(string)
+			if !ok {
+				return nil, fmt.Errorf("must list file paths, got %v", file)
+			}
+			files = append(files, s)
+		}
+		return files, nil
+	}
+
+	return nil, fmt.Errorf("must be a file path or a list of them, got %v", value)
+}
+
+// interpolate replaces `${VAR}` in all strings of value with the environment
+// variable VAR, which has to be set.
+func interpolate(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		var err error
+		interpolated := interpolation.ReplaceAllStringFunc(v, func(match string) string {
+			name := interpolation.FindStringSubmatch(match)[1]
+			env, ok := os.LookupEnv(name)
+			if !ok && err == nil {
+				err = fmt.Errorf("environment variable %s is not set", name)
+			}
+			return env
+		})
+		return interpolated, err
+	case map[string]interface{}:
+		for key, elem := range v {
+			interpolated, err := interpolate(elem)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = interpolated
+		}
+	case []interface{}:
+		for i, elem := range v {
+			interpolated, err := interpolate(elem)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = interpolated
+		}
+	}
+
+	return value, nil
+}
+
+// merge lays overlay over base: mappings are merged key by key, and any
+// other overlay value, including a list, replaces the base value.
+func merge(base, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range overlay {
+		baseMap, baseIsMap := merged[key].(map[string]interface{})
+		overlayMap, overlayIsMap := value.(map[string]interface{})
+		if baseIsMap && overlayIsMap {
+			merged[key] = merge(baseMap, overlayMap)
+			continue
+		}
+		merged[key] = value
+	}
+
+	return merged
+}
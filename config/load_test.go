@@ -0,0 +1,143 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/uptimer/config"
+)
+
+var _ = Describe("Load", func() {
+	var (
+		dir string
+		cfg *config.Config
+		err error
+	)
+
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		ExpectWithOffset(1, os.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("reads JSON", func() {
+		cfg, err = config.Load(writeFile("config.json", `{
+			"while": [{"command": "sleep", "command_args": ["10"]}],
+			"cf": {"api": "api.example.com", "tcp_port": 1025}
+		}`))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.While).To(Equal([]*config.Command{{Command: "sleep", CommandArgs: []string{"10"}}}))
+		Expect(cfg.CF.API).To(Equal("api.example.com"))
+		Expect(cfg.CF.TCPPort).To(Equal(1025))
+	})
+
+	It("reads YAML", func() {
+		cfg, err = config.Load(writeFile("config.yml", `
+while:
+- command: sleep
+  command_args: ["10"]
+cf:
+  api: api.example.com
+  tcp_port: 1025
+syslog_drain:
+  max_loss_percent: 2.5
+`))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.While).To(Equal([]*config.Command{{Command: "sleep", CommandArgs: []string{"10"}}}))
+		Expect(cfg.CF.TCPPort).To(Equal(1025))
+		Expect(cfg.SyslogDrain.MaxLossPercent).To(Equal(2.5))
+	})
+
+	It("replaces ${VAR} with environment variables", func() {
+		GinkgoT().Setenv("UPTIMER_TEST_SYSTEM_DOMAIN", "sys.example.com")
+
+		cfg, err = config.Load(writeFile("config.yml", `
+cf:
+  api: api.${UPTIMER_TEST_SYSTEM_DOMAIN}
+  app_domain: apps.example.com
+`))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.CF.API).To(Equal("api.sys.example.com"))
+		Expect(cfg.CF.AppDomain).To(Equal("apps.example.com"))
+	})
+
+	It("fails when an interpolated variable is not set", func() {
+		_, err = config.Load(writeFile("config.yml", `
+cf:
+  api: api.${UPTIMER_TEST_UNSET}
+`))
+
+		Expect(err).To(MatchError(ContainSubstring("environment variable UPTIMER_TEST_UNSET is not set")))
+	})
+
+	It("lays a config over the files it includes, in order", func() {
+		writeFile("base.yml", `
+while:
+- command: sleep
+cf:
+  api: api.base.com
+  admin_user: admin
+  admin_password: base
+allowed_failures:
+  app_pushability: 2
+  http_availability: 5
+`)
+		writeFile("creds.json", `{"cf": {"admin_password": "creds"}}`)
+
+		cfg, err = config.Load(writeFile("env.yml", `
+include: [base.yml, creds.json]
+cf:
+  api: api.env.com
+allowed_failures:
+  http_availability: 10
+`))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.While).To(HaveLen(1))
+		Expect(cfg.CF.API).To(Equal("api.env.com"))
+		Expect(cfg.CF.AdminUser).To(Equal("admin"))
+		Expect(cfg.CF.AdminPassword).To(Equal("creds"))
+		Expect(cfg.AllowedFailures.AppPushability).To(Equal(2))
+		Expect(cfg.AllowedFailures.HttpAvailability).To(Equal(10))
+	})
+
+	It("fails when configs include each other", func() {
+		writeFile("a.yml", "include: b.yml\n")
+		writeFile("b.yml", "include: a.yml\n")
+
+		_, err = config.Load(filepath.Join(dir, "a.yml"))
+
+		Expect(err).To(MatchError(ContainSubstring("includes itself")))
+	})
+
+	It("rejects unknown keys", func() {
+		_, err = config.Load(writeFile("config.yml", `
+allowed_failure:
+  http_availability: 10
+`))
+
+		Expect(err).To(MatchError(ContainSubstring(`unknown field "allowed_failure"`)))
+	})
+
+	It("rejects unknown nested keys", func() {
+		_, err = config.Load(writeFile("config.json", `{"cf": {"admin_usr": "admin"}}`))
+
+		Expect(err).To(MatchError(ContainSubstring(`unknown field "admin_usr"`)))
+	})
+
+	It("rejects a quota for an existing space, which uptimer never sets", func() {
+		_, err = config.Load(writeFile("config.json", `{"cf": {"existing_space": {"org": "my-org", "space": "my-space", "quota": "my-quota"}}}`))
+
+		Expect(err).To(MatchError(ContainSubstring(`unknown field "quota"`)))
+	})
+})
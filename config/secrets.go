@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var envReference = regexp.MustCompile(`^\$(?:([A-Za-z_][A-Za-z0-9_]*)|\{([A-Za-z_][A-Za-z0-9_]*)\})$`)
+
+// resolveSecret returns the value a secret setting refers to. "$NAME",
+// "${NAME}" and "env:NAME" are read from the environment variable NAME, and
+// "file:PATH" from the file at PATH, without trailing newlines. A leading
+// "$$" stands for a literal "$"; other values are the secret itself.
+func resolveSecret(value string) (string, error) {
+	if match := envReference.FindStringSubmatch(value); match != nil {
+		return lookupEnv(match[1] + match[2])
+	}
+
+	switch {
+	case strings.HasPrefix(value, "$$"):
+		return strings.TrimPrefix(value, "$"), nil
+	case strings.HasPrefix(value, "env:"):
+		return lookupEnv(strings.TrimPrefix(value, "env:"))
+	case strings.HasPrefix(value, "file:"):
+		contents, err := os.ReadFile(strings.TrimPrefix(value, "file:"))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(contents), "\r\n"), nil
+	}
+
+	return value, nil
+}
+
+func lookupEnv(name string) (string, error) {
+	secret, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	return secret, nil
+}
+
+// resolveSecrets replaces the references of all secret settings with the
+// secrets they refer to.
+func (c *Config) resolveSecrets() error {
+	for i, f := range c.AllFoundations() {
+		if f.CF == nil {
+			continue
+		}
+		cfPath := "cf"
+		if len(c.Foundations) > 0 {
+			cfPath = fmt.Sprintf("foundations[%d].cf", i)
+		}
+
+		for _, secret := range []struct {
+			name  string
+			value *string
+		}{
+			{"admin_user", &f.CF.AdminUser},
+			{"admin_password", &f.CF.AdminPassword},
+			{"client_id", &f.CF.ClientID},
+			{"client_secret", &f.CF.ClientSecret},
+		} {
+			resolved, err := resolveSecret(*secret.value)
+			if err != nil {
+				return fmt.Errorf("failed to resolve `%s.%s`: %s", cfPath, secret.name, err)
+			}
+			*secret.value = resolved
+		}
+	}
+
+	resolved, err := resolveSecret(c.DockerImage.Password)
+	if err != nil {
+		return fmt.Errorf("failed to resolve `docker_image.password`: %s", err)
+	}
+	c.DockerImage.Password = resolved
+
+	return nil
+}
+
+// Secrets returns the resolved values of all secret settings, which must
+// never appear in uptimer's output.
+func (c Config) Secrets() []string {
+	var secrets []string
+	for _, f := range c.AllFoundations() {
+		if f.CF != nil {
+			secrets = append(secrets, f.CF.AdminPassword, f.CF.ClientSecret)
+		}
+	}
+
+	return append(secrets, c.DockerImage.Password)
+}
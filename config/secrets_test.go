@@ -0,0 +1,137 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/uptimer/config"
+)
+
+var _ = Describe("Secrets", func() {
+	var (
+		dir        string
+		configPath string
+		cfg        *config.Config
+		err        error
+	)
+
+	writeConfig := func(contents string) {
+		ExpectWithOffset(1, os.WriteFile(configPath, []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		configPath = filepath.Join(dir, "config.json")
+		GinkgoT().Setenv("UPTIMER_TEST_PASSWORD", "env-password")
+		Expect(os.WriteFile(filepath.Join(dir, "secret"), []byte("file-secret\n"), 0600)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		cfg, err = config.Load(configPath)
+	})
+
+	Context("when secrets are given as they are", func() {
+		BeforeEach(func() {
+			writeConfig(`{"cf": {"admin_user": "admin", "admin_password": "plain-password"}}`)
+		})
+
+		It("uses them", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.CF.AdminPassword).To(Equal("plain-password"))
+		})
+	})
+
+	Context("when secrets refer to environment variables with $", func() {
+		BeforeEach(func() {
+			writeConfig(`{
+				"cf": {"admin_user": "admin", "admin_password": "$UPTIMER_TEST_PASSWORD"},
+				"docker_image": {"username": "user", "password": "${UPTIMER_TEST_PASSWORD}"}
+			}`)
+		})
+
+		It("resolves them", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.CF.AdminPassword).To(Equal("env-password"))
+			Expect(cfg.DockerImage.Password).To(Equal("env-password"))
+		})
+	})
+
+	Context("when a secret starts with $ but is no reference", func() {
+		BeforeEach(func() {
+			writeConfig(`{"cf": {"admin_user": "admin", "admin_password": "$UPTIMER_TEST_PASSWORD!"}}`)
+		})
+
+		It("uses it as it is", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.CF.AdminPassword).To(Equal("$UPTIMER_TEST_PASSWORD!"))
+		})
+	})
+
+	Context("when a secret starts with $$", func() {
+		BeforeEach(func() {
+			writeConfig(`{"cf": {"admin_user": "admin", "admin_password": "$$UPTIMER_TEST_PASSWORD"}}`)
+		})
+
+		It("uses it with a single literal $", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.CF.AdminPassword).To(Equal("$UPTIMER_TEST_PASSWORD"))
+		})
+	})
+
+	Context("when secrets refer to environment variables and files", func() {
+		BeforeEach(func() {
+			writeConfig(`{
+				"foundations": [
+					{"name": "east", "cf": {"admin_user": "admin", "admin_password": "env:UPTIMER_TEST_PASSWORD"}},
+					{"name": "west", "cf": {"client_id": "uptimer", "client_secret": "file:` + filepath.Join(dir, "secret") + `"}}
+				],
+				"docker_image": {"username": "user", "password": "env:UPTIMER_TEST_PASSWORD"}
+			}`)
+		})
+
+		It("resolves them", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Foundations[0].CF.AdminUser).To(Equal("admin"))
+			Expect(cfg.Foundations[0].CF.AdminPassword).To(Equal("env-password"))
+			Expect(cfg.Foundations[1].CF.ClientSecret).To(Equal("file-secret"))
+			Expect(cfg.DockerImage.Password).To(Equal("env-password"))
+		})
+
+		It("lists them as secrets", func() {
+			Expect(cfg.Secrets()).To(ContainElements("env-password", "file-secret"))
+		})
+	})
+
+	Context("when an environment variable is not set", func() {
+		BeforeEach(func() {
+			writeConfig(`{"cf": {"admin_user": "admin", "admin_password": "env:UPTIMER_TEST_UNSET"}}`)
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("failed to resolve `cf.admin_password`: environment variable UPTIMER_TEST_UNSET is not set"))
+		})
+	})
+
+	Context("when an environment variable referred to with $ is not set", func() {
+		BeforeEach(func() {
+			writeConfig(`{"cf": {"admin_user": "admin", "admin_password": "$UPTIMER_TEST_UNSET"}}`)
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("failed to resolve `cf.admin_password`: environment variable UPTIMER_TEST_UNSET is not set"))
+		})
+	})
+
+	Context("when a file cannot be read", func() {
+		BeforeEach(func() {
+			writeConfig(`{"cf": {"client_id": "uptimer", "client_secret": "file:/does/not/exist"}}`)
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(ContainSubstring("failed to resolve `cf.client_secret`")))
+		})
+	})
+})
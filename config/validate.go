@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// validation collects every problem found in a config, each naming the
+// JSON path of the setting at fault.
+type validation struct {
+	errs []error
+}
+
+func (v *validation) fail(format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+// Validate checks the whole config and returns all problems it finds, one
+// per line, or nil if there are none.
+func (c Config) Validate() error {
+	v := &validation{}
+
+	c.validateWhile(v)
+	c.validateFoundations(v)
+	validateNotNegative(v, "allowed_failures", c.AllowedFailures)
+	c.validateSyslogDrain(v)
+	if c.DockerImage.Password != "" && c.DockerImage.Username == "" {
+		v.fail("`docker_image.username` must be set when `docker_image.password` is set")
+	}
+	c.validatePushabilityMatrix(v)
+	if c.SetupRetries != nil {
+		validateNotNegative(v, "setup_retries", *c.SetupRetries)
+	}
+	if c.Timeouts != nil {
+		c.validateTimeouts(v)
+	}
+
+	return errors.Join(v.errs...)
+}
+
+func (c Config) validateWhile(v *validation) {
+	if len(c.While) == 0 {
+		v.fail("`while` must list at least one command")
+	}
+	for i, cmd := range c.While {
+		if cmd == nil || cmd.Command == "" {
+			v.fail("`while[%d].command` must be set", i)
+		}
+	}
+}
+
+func (c Config) validateFoundations(v *validation) {
+	if c.CF != nil && len(c.Foundations) > 0 {
+		v.fail("only one of `cf` and `foundations` may be set")
+	}
+	if c.CF == nil && len(c.Foundations) == 0 {
+		v.fail("one of `cf` and `foundations` must be set")
+		return
+	}
+
+	foundationNames := map[string]bool{}
+	for i, f := range c.Foundations {
+		if f == nil {
+			v.fail("`foundations[%d]` must be set", i)
+			continue
+		}
+		if f.Name == "" {
+			v.fail("`foundations[%d].name` must be set", i)
+		} else if foundationNames[f.Name] {
+			v.fail("`foundations[%d].name` %q is used more than once", i, f.Name)
+		}
+		foundationNames[f.Name] = true
+		if f.CF == nil {
+			v.fail("`foundations[%d].cf` must be set", i)
+		}
+	}
+
+	for i, f := range c.AllFoundations() {
+		if f == nil || f.CF == nil {
+			continue
+		}
+		cfPath := "cf"
+		if len(c.Foundations) > 0 {
+			cfPath = fmt.Sprintf("foundations[%d].cf", i)
+		}
+		f.CF.validate(v, cfPath, c.OptionalTests)
+	}
+}
+
+func (c *Cf) validate(v *validation, cfPath string, optionalTests OptionalTests) {
+	if c.API == "" {
+		v.fail("`%s.api` must be set", cfPath)
+	}
+	if c.AppDomain == "" {
+		v.fail("`%s.app_domain` must be set", cfPath)
+	}
+
+	c.validateCredentials(v, cfPath)
+
+	if c.ExistingSpace != nil {
+		if c.ExistingSpace.Org == "" || c.ExistingSpace.Space == "" {
+			v.fail("`%[1]s.existing_space.org` and `%[1]s.existing_space.space` must be set together", cfPath)
+		}
+		if c.IsolationSegment != "" {
+			v.fail("`%[1]s.isolation_segment` cannot be set with `%[1]s.existing_space`", cfPath)
+		}
+	}
+
+	switch c.Backend {
+	case "", BackendCLI, BackendAPI:
+	default:
+		v.fail("`%s.backend` must be one of %q or %q, got %q", cfPath, BackendCLI, BackendAPI, c.Backend)
+	}
+
+	validatePort(v, cfPath+".tcp_port", c.TCPPort)
+	validatePort(v, cfPath+".available_port", c.AvailablePort)
+
+	if optionalTests.RunAppSyslogAvailability && (c.TCPDomain == "" || c.AvailablePort == 0) {
+		v.fail("`%[1]s.tcp_domain` and `%[1]s.available_port` must be set in order to run App Syslog Availability tests", cfPath)
+	}
+	if optionalTests.RunTcpAvailability && (c.TCPDomain == "" || c.TCPPort == 0) {
+		v.fail("`%[1]s.tcp_domain` and `%[1]s.tcp_port` must be set in order to run TCP Availability tests", cfPath)
+	}
+}
+
+func (c *Cf) validateCredentials(v *validation, cfPath string) {
+	usesUser := c.AdminUser != "" || c.AdminPassword != ""
+	if usesUser == c.UsesClientCredentials() {
+		v.fail("exactly one of `%[1]s.admin_user` and `%[1]s.client_id` must be set", cfPath)
+		return
+	}
+	if usesUser && (c.AdminUser == "" || c.AdminPassword == "") {
+		v.fail("`%[1]s.admin_user` and `%[1]s.admin_password` must be set together", cfPath)
+	}
+	if c.UsesClientCredentials() && (c.ClientID == "" || c.ClientSecret == "") {
+		v.fail("`%[1]s.client_id` and `%[1]s.client_secret` must be set together", cfPath)
+	}
+}
+
+// validatePort checks an optional port, which is unset when 0. The tests that
+// need it check that it is set.
+func validatePort(v *validation, path string, port int) {
+	if port < 0 || port > 65535 {
+		v.fail("`%s` must be a port between 1 and 65535, or 0 when unset, got %d", path, port)
+	}
+}
+
+// validateNotNegative checks that none of the int settings of the struct s
+// is negative.
+func validateNotNegative(v *validation, path string, s interface{}) {
+	value := reflect.ValueOf(s)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Type.Kind() != reflect.Int {
+			continue
+		}
+		if n := value.Field(i).Int(); n < 0 {
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			v.fail("`%s.%s` must not be negative, got %d", path, name, n)
+		}
+	}
+}
+
+func (c Config) validateTimeouts(v *validation) {
+	validateNotNegative(v, "timeouts", *c.Timeouts)
+
+	kinds := make([]string, 0, len(c.Timeouts.Commands))
+	for kind := range c.Timeouts.Commands {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		if seconds := c.Timeouts.Commands[kind]; seconds < 0 {
+			v.fail("`timeouts.commands.%s` must not be negative, got %d", kind, seconds)
+		}
+	}
+}
+
+func (c Config) validateSyslogDrain(v *validation) {
+	if c.OptionalTests.RunAggregateSyslogAvailability && c.SyslogDrain.AggregateSinkURL == "" {
+		v.fail("`syslog_drain.aggregate_sink_url` must be set in order to run Aggregate Syslog Availability tests")
+	}
+	switch c.SyslogDrain.Scheme {
+	case "", "syslog", "syslog-tls", "https":
+	default:
+		v.fail("`syslog_drain.scheme` must be one of \"syslog\", \"syslog-tls\" or \"https\", got %q", c.SyslogDrain.Scheme)
+	}
+	if (c.SyslogDrain.TLSCertFile == "") != (c.SyslogDrain.TLSKeyFile == "") {
+		v.fail("`syslog_drain.tls_cert_file` and `syslog_drain.tls_key_file` must be set together")
+	}
+	if c.SyslogDrain.MaxLossPercent < 0 || c.SyslogDrain.MaxLossPercent > 100 {
+		v.fail("`syslog_drain.max_loss_percent` must be between 0 and 100, got %g", c.SyslogDrain.MaxLossPercent)
+	}
+}
+
+func (c Config) validatePushabilityMatrix(v *validation) {
+	matrixNames := map[string]bool{}
+	for i, entry := range c.PushabilityMatrix {
+		if entry.Name == "" {
+			v.fail("`pushability_matrix[%d].name` must be set", i)
+		} else if matrixNames[entry.Name] {
+			v.fail("`pushability_matrix[%d].name` %q is used more than once", i, entry.Name)
+		}
+		matrixNames[entry.Name] = true
+		if entry.AllowedFailures < 0 {
+			v.fail("`pushability_matrix[%d].allowed_failures` must not be negative, got %d", i, entry.AllowedFailures)
+		}
+	}
+}
@@ -14,19 +14,149 @@ var _ = Describe("Validate", func() {
 		err error
 	)
 
-	Context("when measuring TCP availability", func() {
+	BeforeEach(func() {
+		cfg = config.Config{
+			While: []*config.Command{{Command: "sleep", CommandArgs: []string{"600"}}},
+			CF: &config.Cf{
+				API:           "api.my-cf.com",
+				AppDomain:     "my-cf.com",
+				AdminUser:     "admin",
+				AdminPassword: "password",
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		err = cfg.Validate()
+	})
+
+	It("succeeds", func() {
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("reports every problem at once", func() {
+		cfg.While = nil
+		cfg.CF.API = ""
+		cfg.AllowedFailures.HttpAvailability = -1
+
+		Expect(cfg.Validate()).To(MatchError(
+			"`while` must list at least one command\n" +
+				"`cf.api` must be set\n" +
+				"`allowed_failures.http_availability` must not be negative, got -1",
+		))
+	})
+
+	Context("when no while commands are listed", func() {
+		BeforeEach(func() {
+			cfg.While = nil
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("`while` must list at least one command"))
+		})
+	})
+
+	Context("when a while command is empty", func() {
+		BeforeEach(func() {
+			cfg.While = append(cfg.While, &config.Command{CommandArgs: []string{"10"}})
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("`while[1].command` must be set"))
+		})
+	})
+
+	Context("when neither cf nor foundations are set", func() {
+		BeforeEach(func() {
+			cfg.CF = nil
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("one of `cf` and `foundations` must be set"))
+		})
+	})
+
+	Context("when the API and app domain are not set", func() {
+		BeforeEach(func() {
+			cfg.CF.API = ""
+			cfg.CF.AppDomain = ""
+		})
+
+		It("returns an error for each", func() {
+			Expect(err).To(MatchError("`cf.api` must be set\n`cf.app_domain` must be set"))
+		})
+	})
+
+	Context("when a port is out of range", func() {
 		BeforeEach(func() {
-			cfg = config.Config{
-				CF: &config.Cf{
-					TCPDomain: "tcp.my-cf.com",
-					TCPPort:   1025,
-				},
-				OptionalTests: config.OptionalTests{RunTcpAvailability: true},
+			cfg.CF.TCPPort = 70000
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("`cf.tcp_port` must be a port between 1 and 65535, or 0 when unset, got 70000"))
+		})
+	})
+
+	Context("when a port is negative", func() {
+		BeforeEach(func() {
+			cfg.CF.AvailablePort = -1
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("`cf.available_port` must be a port between 1 and 65535, or 0 when unset, got -1"))
+		})
+	})
+
+	Context("when allowed failures are negative", func() {
+		BeforeEach(func() {
+			cfg.AllowedFailures.AppPushability = -1
+			cfg.AllowedFailures.DockerPushability = -2
+		})
+
+		It("returns an error for each", func() {
+			Expect(err).To(MatchError(
+				"`allowed_failures.app_pushability` must not be negative, got -1\n" +
+					"`allowed_failures.docker_pushability` must not be negative, got -2",
+			))
+		})
+	})
+
+	Context("when setup retries are negative", func() {
+		BeforeEach(func() {
+			cfg.SetupRetries = &config.SetupRetries{Retries: -1, InitialBackoffSeconds: 5, MaxBackoffSeconds: -60}
+		})
+
+		It("returns an error for each", func() {
+			Expect(err).To(MatchError(
+				"`setup_retries.retries` must not be negative, got -1\n" +
+					"`setup_retries.max_backoff_seconds` must not be negative, got -60",
+			))
+		})
+	})
+
+	Context("when timeouts are negative", func() {
+		BeforeEach(func() {
+			cfg.Timeouts = &config.Timeouts{
+				CommandSeconds: 600,
+				Commands:       map[string]int{"push": -1, "logs": 0, "delete": -5},
+				AttemptSeconds: -900,
 			}
 		})
 
-		JustBeforeEach(func() {
-			err = cfg.Validate()
+		It("returns an error for each", func() {
+			Expect(err).To(MatchError(
+				"`timeouts.attempt_seconds` must not be negative, got -900\n" +
+					"`timeouts.commands.delete` must not be negative, got -5\n" +
+					"`timeouts.commands.push` must not be negative, got -1",
+			))
+		})
+	})
+
+	Context("when measuring TCP availability", func() {
+		BeforeEach(func() {
+			cfg.CF.TCPDomain = "tcp.my-cf.com"
+			cfg.CF.TCPPort = 1025
+			cfg.OptionalTests = config.OptionalTests{RunTcpAvailability: true}
 		})
 
 		It("succeeds", func() {
@@ -67,17 +197,9 @@ var _ = Describe("Validate", func() {
 
 	Context("when measuring app syslog availability", func() {
 		BeforeEach(func() {
-			cfg = config.Config{
-				CF: &config.Cf{
-					TCPDomain:     "tcp.my-cf.com",
-					AvailablePort: 1025,
-				},
-				OptionalTests: config.OptionalTests{RunAppSyslogAvailability: true},
-			}
-		})
-
-		JustBeforeEach(func() {
-			err = cfg.Validate()
+			cfg.CF.TCPDomain = "tcp.my-cf.com"
+			cfg.CF.AvailablePort = 1025
+			cfg.OptionalTests = config.OptionalTests{RunAppSyslogAvailability: true}
 		})
 
 		It("succeeds", func() {
@@ -115,4 +237,299 @@ var _ = Describe("Validate", func() {
 			})
 		})
 	})
+
+	Context("when configuring the syslog drain", func() {
+		BeforeEach(func() {
+			cfg.SyslogDrain = config.SyslogDrain{Scheme: "syslog-tls", MaxLossPercent: 1}
+		})
+
+		It("succeeds", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the scheme is not supported", func() {
+			BeforeEach(func() {
+				cfg.SyslogDrain.Scheme = "udp"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`syslog_drain.scheme` must be one of \"syslog\", \"syslog-tls\" or \"https\", got \"udp\""))
+			})
+		})
+
+		Context("when only a TLS certificate is provided", func() {
+			BeforeEach(func() {
+				cfg.SyslogDrain.TLSCertFile = "/path/to/cert.pem"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`syslog_drain.tls_cert_file` and `syslog_drain.tls_key_file` must be set together"))
+			})
+		})
+
+		Context("when the max loss is not a percentage", func() {
+			BeforeEach(func() {
+				cfg.SyslogDrain.MaxLossPercent = 150
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`syslog_drain.max_loss_percent` must be between 0 and 100, got 150"))
+			})
+		})
+	})
+
+	Context("when measuring aggregate syslog availability", func() {
+		BeforeEach(func() {
+			cfg.SyslogDrain = config.SyslogDrain{AggregateSinkURL: "https://sink.my-cf.com"}
+			cfg.OptionalTests = config.OptionalTests{RunAggregateSyslogAvailability: true}
+		})
+
+		It("succeeds", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when an aggregate sink url is not provided", func() {
+			BeforeEach(func() {
+				cfg.SyslogDrain.AggregateSinkURL = ""
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`syslog_drain.aggregate_sink_url` must be set in order to run Aggregate Syslog Availability tests"))
+			})
+		})
+	})
+
+	Context("when pushing a docker image", func() {
+		BeforeEach(func() {
+			cfg.DockerImage = config.DockerImage{Image: "registry.example.com/app", Username: "user", Password: "secret"}
+			cfg.OptionalTests = config.OptionalTests{RunDockerPushability: true}
+		})
+
+		It("succeeds", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when a password is provided without a username", func() {
+			BeforeEach(func() {
+				cfg.DockerImage.Username = ""
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`docker_image.username` must be set when `docker_image.password` is set"))
+			})
+		})
+	})
+
+	Context("when measuring a pushability matrix", func() {
+		BeforeEach(func() {
+			cfg.PushabilityMatrix = []config.PushabilityMatrixEntry{
+				{Name: "staticfile", Buildpack: "staticfile_buildpack"},
+				{Name: "java", Buildpack: "java_buildpack", App: "/path/to/app.jar"},
+			}
+		})
+
+		It("succeeds", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when an entry has no name", func() {
+			BeforeEach(func() {
+				cfg.PushabilityMatrix[1].Name = ""
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`pushability_matrix[1].name` must be set"))
+			})
+		})
+
+		Context("when two entries have the same name", func() {
+			BeforeEach(func() {
+				cfg.PushabilityMatrix[1].Name = "staticfile"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`pushability_matrix[1].name` \"staticfile\" is used more than once"))
+			})
+		})
+
+		Context("when an entry allows negative failures", func() {
+			BeforeEach(func() {
+				cfg.PushabilityMatrix[0].AllowedFailures = -1
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`pushability_matrix[0].allowed_failures` must not be negative, got -1"))
+			})
+		})
+	})
+
+	Context("when measuring multiple foundations", func() {
+		BeforeEach(func() {
+			cfg.CF = nil
+			cfg.Foundations = []*config.Foundation{
+				{Name: "east", CF: &config.Cf{API: "api.east.com", AppDomain: "east.com", AdminUser: "admin", AdminPassword: "password", TCPDomain: "tcp.east.com", TCPPort: 1025}},
+				{Name: "west", CF: &config.Cf{API: "api.west.com", AppDomain: "west.com", ClientID: "uptimer", ClientSecret: "secret", TCPDomain: "tcp.west.com", TCPPort: 1025}},
+			}
+			cfg.OptionalTests = config.OptionalTests{RunTcpAvailability: true}
+		})
+
+		It("succeeds", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the cf section is also set", func() {
+			BeforeEach(func() {
+				cfg.CF = &config.Cf{}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("only one of `cf` and `foundations` may be set"))
+			})
+		})
+
+		Context("when a foundation has no name", func() {
+			BeforeEach(func() {
+				cfg.Foundations[1].Name = ""
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`foundations[1].name` must be set"))
+			})
+		})
+
+		Context("when two foundations have the same name", func() {
+			BeforeEach(func() {
+				cfg.Foundations[1].Name = "east"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`foundations[1].name` \"east\" is used more than once"))
+			})
+		})
+
+		Context("when a foundation has no cf section", func() {
+			BeforeEach(func() {
+				cfg.Foundations[0].CF = nil
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`foundations[0].cf` must be set"))
+			})
+		})
+
+		Context("when a foundation lacks settings for an optional test", func() {
+			BeforeEach(func() {
+				cfg.Foundations[1].CF.TCPPort = 0
+			})
+
+			It("returns an error naming the foundation", func() {
+				Expect(err).To(MatchError("`foundations[1].cf.tcp_domain` and `foundations[1].cf.tcp_port` must be set in order to run TCP Availability tests"))
+			})
+		})
+	})
+
+	Context("when choosing a backend", func() {
+		BeforeEach(func() {
+			cfg.CF.Backend = config.BackendAPI
+		})
+
+		It("succeeds", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the backend is not supported", func() {
+			BeforeEach(func() {
+				cfg.CF.Backend = "grpc"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`cf.backend` must be one of \"cli\" or \"api\", got \"grpc\""))
+			})
+		})
+	})
+
+	Context("when configuring credentials", func() {
+		Context("when client credentials are used instead", func() {
+			BeforeEach(func() {
+				cfg.CF.AdminUser, cfg.CF.AdminPassword = "", ""
+				cfg.CF.ClientID, cfg.CF.ClientSecret = "uptimer", "secret"
+			})
+
+			It("succeeds", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when both kinds of credentials are provided", func() {
+			BeforeEach(func() {
+				cfg.CF.ClientID = "uptimer"
+				cfg.CF.ClientSecret = "secret"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("exactly one of `cf.admin_user` and `cf.client_id` must be set"))
+			})
+		})
+
+		Context("when no credentials are provided", func() {
+			BeforeEach(func() {
+				cfg.CF.AdminUser, cfg.CF.AdminPassword = "", ""
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("exactly one of `cf.admin_user` and `cf.client_id` must be set"))
+			})
+		})
+
+		Context("when an admin password is provided without a user", func() {
+			BeforeEach(func() {
+				cfg.CF.AdminUser = ""
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`cf.admin_user` and `cf.admin_password` must be set together"))
+			})
+		})
+
+		Context("when a client id is provided without a secret", func() {
+			BeforeEach(func() {
+				cfg.CF.AdminUser, cfg.CF.AdminPassword = "", ""
+				cfg.CF.ClientID = "uptimer"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`cf.client_id` and `cf.client_secret` must be set together"))
+			})
+		})
+	})
+
+	Context("when using an existing space", func() {
+		BeforeEach(func() {
+			cfg.CF.ExistingSpace = &config.ExistingSpace{Org: "some-org", Space: "some-space"}
+		})
+
+		It("succeeds", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the space is not named", func() {
+			BeforeEach(func() {
+				cfg.CF.ExistingSpace.Space = ""
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`cf.existing_space.org` and `cf.existing_space.space` must be set together"))
+			})
+		})
+
+		Context("when an isolation segment is configured", func() {
+			BeforeEach(func() {
+				cfg.CF.IsolationSegment = "some-segment"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError("`cf.isolation_segment` cannot be set with `cf.existing_space`"))
+			})
+		})
+	})
 })
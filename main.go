@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
@@ -10,8 +11,12 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"code.cloudfoundry.org/goshims/ioutilshim"
@@ -21,26 +26,45 @@ import (
 
 	"github.com/cloudfoundry/uptimer/app"
 	"github.com/cloudfoundry/uptimer/appLogValidator"
+	"github.com/cloudfoundry/uptimer/appSource"
+	"github.com/cloudfoundry/uptimer/cfApi"
 	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
 	"github.com/cloudfoundry/uptimer/cfWorkflow"
+	"github.com/cloudfoundry/uptimer/cleanup"
 	"github.com/cloudfoundry/uptimer/cmdRunner"
 	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
 	"github.com/cloudfoundry/uptimer/config"
 	"github.com/cloudfoundry/uptimer/measurement"
 	"github.com/cloudfoundry/uptimer/orchestrator"
+	"github.com/cloudfoundry/uptimer/redactor"
 	"github.com/cloudfoundry/uptimer/syslogSink"
 	"github.com/cloudfoundry/uptimer/tcpApp"
 	"github.com/cloudfoundry/uptimer/version"
 )
 
+// defaultDockerImage is pushed by the docker pushability measurement when
+// no image is configured; it serves HTTP 200 on the app route.
+const defaultDockerImage = "cloudfoundry/diego-docker-app:latest"
+
+const appContractTimeout = time.Minute
+
 func main() {
 	logger := log.New(os.Stdout, "\n[UPTIMER] ", log.Ldate|log.Ltime|log.LUTC)
 
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "cleanup" {
+		os.Exit(cleanUp(logger, os.Args[2:], os.Stdin))
+	}
+
 	useBuildpackDetection := flag.Bool("useBuildpackDetection", false, "Use buildpack detection (defaults to false)")
+	useBinaryBuildpack := flag.Bool("useBinaryBuildpack", false, "Cross-compile the included apps locally and push them with the binary buildpack (defaults to false)")
 	useQuotas := flag.Bool("useQuotas", true, "Create and set quotas for orgs (defaults to true)")
 	configPath := flag.String("configFile", "", "Path to the config file")
 	resultPath := flag.String("resultFile", "", "Path to the result file")
 	showVersion := flag.Bool("v", false, "Prints the version of uptimer and exits")
+	dryRun := flag.Bool("dryRun", false, "Print the commands uptimer would run against the foundation, without running them (defaults to false)")
 	flag.Parse()
 
 	if *showVersion {
@@ -61,128 +85,577 @@ func main() {
 
 	err = cfg.Validate()
 	if err != nil {
-		logger.Println(err)
+		logger.Printf("Invalid config:\n%s", err)
 		os.Exit(1)
 	}
 
+	// Everything uptimer logs, including the output of failed commands, has
+	// the configured secrets redacted.
+	logOutput := redactor.NewWriter(os.Stdout, redactor.New(cfg.Secrets()...))
+	logger.SetOutput(logOutput)
+
+	interrupt := handleInterrupts(logger)
+
+	runID := uuid.NewV4().String()
+	logger.Printf("Run ID: %s", runID)
+	metadata := cfWorkflow.RunMetadata(runID, version.Version, time.Now(), hostname())
+
 	performMeasurements := true
 
-	logger.Println("Preparing included app...")
-	appPath, err := prepareIncludedApp("app", app.Source)
+	// Buildpack detection is always used for user supplied apps, which are
+	// not necessarily written in Go, and for prebuilt binaries, whose
+	// manifest names the binary buildpack.
+	apps := preparedApps{
+		appBuildpackDetection:        *useBuildpackDetection || *useBinaryBuildpack || cfg.Apps.App != "",
+		tcpAppBuildpackDetection:     *useBuildpackDetection || *useBinaryBuildpack || cfg.Apps.TCPApp != "",
+		syslogSinkBuildpackDetection: *useBuildpackDetection || *useBinaryBuildpack || cfg.Apps.SyslogSink != "",
+		matrixAppPaths:               map[string]string{},
+	}
+
+	logger.Println("Preparing app...")
+	apps.appPath, err = prepareApp("app", app.Source, nil, cfg.Apps.App, *useBinaryBuildpack)
 	if err != nil {
-		logger.Println("Failed to prepare included app: ", err)
+		logger.Println("Failed to prepare app: ", err)
 		performMeasurements = false
 	}
-	logger.Println("Finished preparing included app")
-	defer os.RemoveAll(appPath) //nolint:errcheck
+	logger.Println("Finished preparing app")
+	defer os.RemoveAll(apps.appPath) //nolint:errcheck
 
-	var tcpPath string
 	if cfg.OptionalTests.RunTcpAvailability {
-		logger.Println("Preparing included tcp app...")
-		tcpPath, err = prepareIncludedApp("tcpApp", tcpApp.Source)
+		logger.Println("Preparing tcp app...")
+		apps.tcpPath, err = prepareApp("tcpApp", tcpApp.Source, nil, cfg.Apps.TCPApp, *useBinaryBuildpack)
 		if err != nil {
-			logger.Println("Failed to prepare included tcp app: ", err)
+			logger.Println("Failed to prepare tcp app: ", err)
 			performMeasurements = false
 		}
-		logger.Println("Finished preparing included tcp app")
-		defer os.RemoveAll(tcpPath) //nolint:errcheck
+		logger.Println("Finished preparing tcp app")
+		defer os.RemoveAll(apps.tcpPath) //nolint:errcheck
 	}
 
-	var sinkAppPath string
 	if cfg.OptionalTests.RunAppSyslogAvailability {
-		logger.Println("Preparing included syslog sink app...")
-		sinkAppPath, err = prepareIncludedApp("syslogSink", syslogSink.Source)
+		logger.Println("Preparing syslog sink app...")
+		var sinkEnv map[string]string
+		sinkEnv, err = syslogSinkTLSEnv(cfg.SyslogDrain)
+		if err == nil {
+			apps.sinkAppPath, err = prepareApp("syslogSink", syslogSink.Source, sinkEnv, cfg.Apps.SyslogSink, *useBinaryBuildpack)
+		}
 		if err != nil {
-			logger.Println("Failed to prepare included syslog sink app: ", err)
+			logger.Println("Failed to prepare syslog sink app: ", err)
+			performMeasurements = false
 		}
-		logger.Println("Finished preparing included syslog sink app")
-	}
-	orcTmpDir, recentLogsTmpDir, streamingLogsTmpDir, appStatsTmpDir, pushTmpDir, tcpTmpDir, sinkTmpDir, err := createTmpDirs()
-	if err != nil {
-		logger.Println("Failed to create temp dirs:", err)
-		performMeasurements = false
+		logger.Println("Finished preparing syslog sink app")
+		defer os.RemoveAll(apps.sinkAppPath) //nolint:errcheck
 	}
 
-	bufferedRunner, runnerOutBuf, runnerErrBuf := createBufferedRunner()
+	for _, entry := range cfg.PushabilityMatrix {
+		apps.matrixAppPaths[entry.Name] = apps.appPath
+		if entry.App != "" {
+			logger.Printf("Preparing app for pushability matrix entry %s...", entry.Name)
+			apps.matrixAppPaths[entry.Name], err = appSource.Prepare(entry.App)
+			if err != nil {
+				logger.Printf("Failed to prepare app for pushability matrix entry %s: %s", entry.Name, err)
+				performMeasurements = false
+			}
+			defer os.RemoveAll(apps.matrixAppPaths[entry.Name]) //nolint:errcheck
+		}
+	}
 
-	pushCmdGenerator := cfCmdGenerator.New(pushTmpDir, *useBuildpackDetection)
-	pushWorkflow := createWorkflow(cfg.CF, appPath, *useQuotas)
-	logger.Printf("Setting up push workflow with org %s ...", pushWorkflow.Org())
-	if err := bufferedRunner.RunInSequence(pushWorkflow.Setup(pushCmdGenerator)...); err != nil {
-		logBufferedRunnerFailure(logger, "push workflow setup", err, runnerOutBuf, runnerErrBuf)
-		performMeasurements = false
-	} else {
-		logger.Println("Finished setting up push workflow")
+	timeouts := cfg.TimeoutsOrDefault()
+	newRunner := func() (cmdRunner.CmdRunner, *bytes.Buffer, *bytes.Buffer) {
+		return createBufferedRunner(timeouts)
 	}
-	pushWorkflowGeneratorFunc := func() cfWorkflow.CfWorkflow {
-		return cfWorkflow.New(
-			cfg.CF,
-			pushWorkflow.Org(),
-			pushWorkflow.Space(),
-			pushWorkflow.Quota(),
-			fmt.Sprintf("uptimer-app-%s", uuid.NewV4().String()),
-			appPath,
+	var dryRunner cmdRunner.CmdRunner
+	if *dryRun {
+		workingDir, err := os.Getwd()
+		if err != nil {
+			logger.Println("Failed to get working directory:", err)
+			os.Exit(1)
+		}
+		logger.Println("Dry run: printing the commands uptimer would run instead of running them")
+		dryRunner = cmdRunner.NewDryRun(logOutput, workingDir)
+		newRunner = func() (cmdRunner.CmdRunner, *bytes.Buffer, *bytes.Buffer) {
+			return dryRunner, bytes.NewBuffer([]byte{}), bytes.NewBuffer([]byte{})
+		}
+	}
+	// The commands of a dry run are printed in the order they would run.
+	concurrently := !*dryRun
+	clock := clock.New()
+
+	var foundations []*foundation
+	var measurements []measurement.Measurement
+	var setupDurations []orchestrator.SetupDuration
+	for _, f := range cfg.AllFoundations() {
+		if interrupt.received() {
+			logger.Println("Run cancelled, not setting up the remaining foundations")
+			break
+		}
+
+		foundationLogger := logger
+		if f.Name != "" {
+			logger.Printf("Setting up foundation %s...", f.Name)
+			foundationLogger = log.New(logOutput, fmt.Sprintf("\n[UPTIMER] [%s] ", f.Name), log.Ldate|log.Ltime|log.LUTC)
+		}
+
+		fd, ok := setUpFoundation(
+			foundationLogger,
+			clock,
+			cfg,
+			f,
+			apps,
+			*useQuotas,
+			*dryRun,
+			metadata,
+			newRunner,
+			concurrently,
 		)
+		if !ok {
+			performMeasurements = false
+		}
+		foundations = append(foundations, fd)
+		measurements = append(measurements, fd.measurements...)
+		setupDurations = append(setupDurations, fd.setupDurations...)
 	}
 
-	var tcpWorkflow cfWorkflow.CfWorkflow
-	var tcpCmdGenerator cfCmdGenerator.CfCmdGenerator
-	if cfg.OptionalTests.RunTcpAvailability {
-		tcpCmdGenerator = cfCmdGenerator.New(tcpTmpDir, *useBuildpackDetection)
-		tcpWorkflow = createWorkflow(cfg.CF, tcpPath, *useQuotas)
-		logger.Printf("Setting up tcp app workflow with org %s ...", tcpWorkflow.Org())
-		err = bufferedRunner.RunInSequence(
-			append(append(
-				tcpWorkflow.Setup(tcpCmdGenerator),
-				tcpWorkflow.PushNoRoute(tcpCmdGenerator)...),
-				tcpWorkflow.MapTCPRoute(tcpCmdGenerator)...)...)
+	if !cfg.OptionalTests.RunAppSyslogAvailability {
+		logger.Println("*NOT* running measurement: App syslog availability")
+	}
+	if !cfg.OptionalTests.RunAggregateSyslogAvailability {
+		logger.Println("*NOT* running measurement: Aggregate syslog availability")
+	}
+	if !cfg.OptionalTests.RunLogCacheAvailability {
+		logger.Println("*NOT* running measurement: Log Cache availability")
+	}
+	if !cfg.OptionalTests.RunRollingDeploy {
+		logger.Println("*NOT* running measurement: Rolling deploy")
+	}
+	if !cfg.OptionalTests.RunDockerPushability {
+		logger.Println("*NOT* running measurement: Docker image pushability")
+	}
 
+	// A single run spans the measurements of all foundations; each
+	// foundation's own orchestrator sets up and tears down its workflow.
+	orc := orchestrator.New(cfg.While, logger, nil, cmdRunner.New(os.Stdout, os.Stderr, io.Copy), measurements, &ioutilshim.IoutilShim{}, runID, setupDurations)
+	var exitCode int
+	if *dryRun {
+		if err := orc.Plan(dryRunner); err != nil {
+			logger.Println("Failed to print the planned commands:", err)
+			exitCode = 1
+		}
+	} else {
+		interrupt.cancels(orc)
+		exitCode, err = orc.Run(performMeasurements, *resultPath)
 		if err != nil {
-			logBufferedRunnerFailure(logger, "tcp workflow setup", err, runnerOutBuf, runnerErrBuf)
-			performMeasurements = false
-		} else {
-			logger.Println("Finished setting up tcp workflow")
+			logger.Println("Failed run:", err)
+		}
+	}
+
+	logger.Println("Tearing down...")
+	runAll(concurrently, len(foundations), func(i int) {
+		tearDown(foundations[i], newRunner, concurrently)
+	})
+	logger.Println("Finished tearing down")
+
+	os.Exit(exitCode)
+}
+
+// interruption cancels the run on the first SIGINT or SIGTERM, so that
+// uptimer still stops the measurements, writes the results and tears down.
+// A second signal exits immediately.
+type interruption struct {
+	mu     sync.Mutex
+	signal os.Signal
+	orc    orchestrator.Orchestrator
+}
+
+func handleInterrupts(logger *log.Logger) *interruption {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	i := &interruption{}
+	go func() {
+		sig := <-signals
+		logger.Printf("Received %s, cancelling the run. Send it again to exit without tearing down.", sig)
+		i.mu.Lock()
+		i.signal = sig
+		if i.orc != nil {
+			i.orc.Cancel(sig)
+		}
+		i.mu.Unlock()
+
+		sig = <-signals
+		logger.Printf("Received %s again, exiting without tearing down", sig)
+		os.Exit(128 + int(sig.(syscall.Signal)))
+	}()
+
+	return i
+}
+
+func (i *interruption) received() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.signal != nil
+}
+
+// cancels makes a signal cancel the run of orc, including one that was
+// received before the run.
+func (i *interruption) cancels(orc orchestrator.Orchestrator) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.orc = orc
+	if i.signal != nil {
+		orc.Cancel(i.signal)
+	}
+}
+
+// preparedApps are the app directories shared by all foundations, and
+// whether each is pushed with buildpack detection.
+type preparedApps struct {
+	appPath        string
+	tcpPath        string
+	sinkAppPath    string
+	matrixAppPaths map[string]string
+
+	appBuildpackDetection        bool
+	tcpAppBuildpackDetection     bool
+	syslogSinkBuildpackDetection bool
+}
+
+// foundation holds what uptimer sets up on one CF foundation: its
+// measurements, and the workflows to tear down after the run.
+type foundation struct {
+	logger           *log.Logger
+	orc              orchestrator.Orchestrator
+	orcCmdGenerator  cfCmdGenerator.CfCmdGenerator
+	pushWorkflow     cfWorkflow.CfWorkflow
+	pushCmdGenerator cfCmdGenerator.CfCmdGenerator
+	tcpWorkflow      cfWorkflow.CfWorkflow
+	tcpCmdGenerator  cfCmdGenerator.CfCmdGenerator
+	sinkWorkflow     cfWorkflow.CfWorkflow
+	sinkCmdGenerator cfCmdGenerator.CfCmdGenerator
+	measurements     []measurement.Measurement
+	setupDurations   []orchestrator.SetupDuration
+
+	// tmpDirs are the CF_HOMEs of the foundation's generators, which are
+	// removed once it has been torn down.
+	tmpDirs []string
+}
+
+// createTmpDir creates a CF_HOME for one of fd's generators.
+func (fd *foundation) createTmpDir() (string, error) {
+	dir, err := os.MkdirTemp("", "uptimer")
+	if err != nil {
+		return "", err
+	}
+	fd.tmpDirs = append(fd.tmpDirs, dir)
+
+	return dir, nil
+}
+
+// validate implements the `uptimer validate` subcommand, which checks a
+// config file without running anything and reports every problem in it.
+func validate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	configPath := flags.String("configFile", "", "Path to the config file")
+	flags.Parse(args)
+
+	if *configPath == "" {
+		fmt.Println("Failed to load config: '-configFile' flag required")
+		return 1
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Println("Failed to load config:", err)
+		return 1
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("%s is invalid:\n", *configPath)
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Printf("  - %s\n", line)
+		}
+		return 1
+	}
+
+	fmt.Printf("%s is valid\n", *configPath)
+	return 0
+}
+
+// cleanUp implements the `uptimer cleanup` subcommand, which deletes the
+// orgs, quotas and syslog drain services that runs left behind on each
+// configured foundation.
+func cleanUp(logger *log.Logger, args []string, stdin io.Reader) int {
+	flags := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	configPath := flags.String("configFile", "", "Path to the config file")
+	olderThan := flags.Duration("olderThan", 24*time.Hour, "Only delete resources created longer ago than this, to spare runs in progress; 0 deletes them all (defaults to 24h)")
+	force := flags.Bool("force", false, "Delete without asking for confirmation (defaults to false)")
+	unlabelled := flags.Bool("unlabelled", false, "Also delete the orgs and services named like uptimer's that are not labelled, as older versions of uptimer left them; they may belong to other tools (defaults to false)")
+	flags.Parse(args)
+
+	if *configPath == "" {
+		logger.Println("Failed to load config: ", fmt.Errorf("'-configFile' flag required"))
+		return 1
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		logger.Println("Failed to load config: ", err)
+		return 1
+	}
+
+	if err := cfg.Validate(); err != nil {
+		logger.Printf("Invalid config:\n%s", err)
+		return 1
+	}
+
+	logOutput := redactor.NewWriter(os.Stdout, redactor.New(cfg.Secrets()...))
+	logger.SetOutput(logOutput)
+
+	if *olderThan == 0 {
+		logger.Println("WARN: -olderThan is 0, so the resources of runs still in progress are deleted too")
+	}
+
+	answers := bufio.NewReader(stdin)
+	exitCode := 0
+	for _, f := range cfg.AllFoundations() {
+		foundationLogger := logger
+		if f.Name != "" {
+			foundationLogger = log.New(logOutput, fmt.Sprintf("\n[UPTIMER] [%s] ", f.Name), log.Ldate|log.Ltime|log.LUTC)
+		}
+
+		if !cleanUpFoundation(foundationLogger, f.CF, cfg.TimeoutsOrDefault(), *olderThan, *force, *unlabelled, answers) {
+			exitCode = 1
 		}
 	}
 
-	var sinkWorkflow cfWorkflow.CfWorkflow
-	var sinkCmdGenerator cfCmdGenerator.CfCmdGenerator
+	return exitCode
+}
+
+// cleanUpFoundation reports false if anything failed.
+func cleanUpFoundation(logger *log.Logger, cfc *config.Cf, timeouts config.Timeouts, olderThan time.Duration, force, unlabelled bool, answers *bufio.Reader) bool {
+	cfHome, err := os.MkdirTemp("", "uptimer")
+	if err != nil {
+		logger.Println("Failed to create temp dir:", err)
+		return false
+	}
+	defer os.RemoveAll(cfHome) //nolint:errcheck
+
+	ccg := newCfCmdGenerator(cfc, cfHome, false)
+	runner, outBuf, errBuf := createBufferedRunner(timeouts)
+	if err := runner.RunInSequence(createWorkflow(cfc, "", false, cfWorkflow.Metadata{}).Login(ccg)...); err != nil {
+		logBufferedRunnerFailure(logger, "login", err, outBuf, errBuf)
+		return false
+	}
+	defer runner.Run(ccg.LogOut()) //nolint:errcheck
+	outBuf.Reset()
+	errBuf.Reset()
+
+	cleaner := cleanup.New(logger, clock.New(), ccg, runner, outBuf, errBuf, unlabelled)
+	resources, err := cleaner.Find(olderThan)
+	if err != nil {
+		logger.Println("Failed to find resources to clean up:", err)
+		return false
+	}
+	if len(resources) == 0 {
+		logger.Println("Found nothing to clean up")
+		return true
+	}
+
+	lines := []string{fmt.Sprintf("Found %d resources to clean up:", len(resources))}
+	for _, r := range resources {
+		lines = append(lines, "  - "+r.String())
+	}
+	logger.Println(strings.Join(lines, "\n"))
+
+	if !force {
+		fmt.Fprintf(logger.Writer(), "Delete them? [y/N] ") //nolint:errcheck
+		answer, _ := answers.ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			logger.Println("Not deleting anything")
+			return true
+		}
+	}
+
+	if err := cleaner.Delete(resources); err != nil {
+		logger.Printf("Failed to clean up:\n%s", err)
+		return false
+	}
+	logger.Printf("Deleted %d resources", len(resources))
+
+	return true
+}
+
+// setUpFoundation creates the workflows and measurements for one foundation,
+// whose workflows put metadata on what they create. The workflows are set
+// up concurrently if so told, each with a runner of its own from newRunner.
+// It reports false if anything failed that should stop measurements. In a dry
+// run, nothing is pushed, so user supplied apps are not validated.
+func setUpFoundation(
+	logger *log.Logger,
+	clock clock.Clock,
+	cfg *config.Config,
+	f *config.Foundation,
+	apps preparedApps,
+	useQuotas bool,
+	dryRun bool,
+	metadata cfWorkflow.Metadata,
+	newRunner func() (cmdRunner.CmdRunner, *bytes.Buffer, *bytes.Buffer),
+	concurrently bool,
+) (*foundation, bool) {
+	performMeasurements := true
+	fd := &foundation{logger: logger}
+
+	// Setup steps are idempotent, so that one failing on e.g. a 502 from the
+	// Cloud Controller can simply be run again.
+	retries := cfg.SetupRetriesOrDefault()
+	backoff := cmdRunner.Backoff{
+		Retries: retries.Retries,
+		Initial: time.Duration(retries.InitialBackoffSeconds) * time.Second,
+		Max:     time.Duration(retries.MaxBackoffSeconds) * time.Second,
+	}
+	timeouts := cfg.TimeoutsOrDefault()
+	newSetupRunner := func() (cmdRunner.CmdRunner, *bytes.Buffer, *bytes.Buffer) {
+		runner, outBuf, errBuf := newRunner()
+		return cmdRunner.NewRetrying(runner, logger, clock, backoff, outBuf, errBuf), outBuf, errBuf
+	}
+
+	orcTmpDir, recentLogsTmpDir, streamingLogsTmpDir, appStatsTmpDir, pushTmpDir, tcpTmpDir, sinkTmpDir, err := createTmpDirs()
+	if err != nil {
+		logger.Println("Failed to create temp dirs:", err)
+		performMeasurements = false
+	}
+	fd.tmpDirs = append(fd.tmpDirs, orcTmpDir, recentLogsTmpDir, streamingLogsTmpDir, appStatsTmpDir, pushTmpDir, tcpTmpDir, sinkTmpDir)
+
+	fd.pushCmdGenerator = newCfCmdGenerator(f.CF, pushTmpDir, apps.appBuildpackDetection)
+	fd.pushWorkflow = createWorkflow(f.CF, apps.appPath, useQuotas, metadata)
+	setups := []workflowSetup{{
+		name: "push",
+		org:  fd.pushWorkflow.Org(),
+		run: func(runner cmdRunner.CmdRunner) error {
+			return runner.RunInSequence(fd.pushWorkflow.Setup(fd.pushCmdGenerator)...)
+		},
+	}}
+
+	if cfg.OptionalTests.RunTcpAvailability {
+		fd.tcpCmdGenerator = newCfCmdGenerator(f.CF, tcpTmpDir, apps.tcpAppBuildpackDetection)
+		fd.tcpWorkflow = createWorkflow(f.CF, apps.tcpPath, useQuotas, metadata)
+		tcpSetup := workflowSetup{
+			name: "tcp",
+			org:  fd.tcpWorkflow.Org(),
+			run: func(runner cmdRunner.CmdRunner) error {
+				return runner.RunInSequence(
+					append(append(
+						fd.tcpWorkflow.Setup(fd.tcpCmdGenerator),
+						fd.tcpWorkflow.PushNoRoute(fd.tcpCmdGenerator)...),
+						fd.tcpWorkflow.MapTCPRoute(fd.tcpCmdGenerator)...)...)
+			},
+		}
+		if cfg.Apps.TCPApp != "" && !dryRun {
+			tcpSetup.app = "tcp app"
+			tcpSetup.validate = func() error {
+				return validateAppContract(measurement.NewTCPAvailability(fd.tcpWorkflow.TCPDomain(), fd.tcpWorkflow.TCPPort()))
+			}
+		}
+		setups = append(setups, tcpSetup)
+	}
+
 	if cfg.OptionalTests.RunAppSyslogAvailability {
-		sinkCmdGenerator = cfCmdGenerator.New(sinkTmpDir, *useBuildpackDetection)
-		sinkWorkflow = createWorkflow(cfg.CF, sinkAppPath, *useQuotas)
-		logger.Printf("Setting up sink workflow with org %s ...", sinkWorkflow.Org())
-		err = bufferedRunner.RunInSequence(
-			append(append(
-				sinkWorkflow.Setup(sinkCmdGenerator),
-				sinkWorkflow.Push(sinkCmdGenerator)...),
-				sinkWorkflow.MapSyslogRoute(sinkCmdGenerator)...)...)
-		if err != nil {
-			logBufferedRunnerFailure(logger, "sink workflow setup", err, runnerOutBuf, runnerErrBuf)
-			performMeasurements = false
-		} else {
-			logger.Println("Finished setting up sink workflow")
+		fd.sinkCmdGenerator = newCfCmdGenerator(f.CF, sinkTmpDir, apps.syslogSinkBuildpackDetection)
+		fd.sinkWorkflow = createWorkflow(f.CF, apps.sinkAppPath, useQuotas, metadata)
+		sinkSetup := workflowSetup{
+			name: "sink",
+			org:  fd.sinkWorkflow.Org(),
+			run: func(runner cmdRunner.CmdRunner) error {
+				return runner.RunInSequence(
+					append(append(
+						fd.sinkWorkflow.Setup(fd.sinkCmdGenerator),
+						fd.sinkWorkflow.Push(fd.sinkCmdGenerator)...),
+						fd.sinkWorkflow.MapSyslogRoute(fd.sinkCmdGenerator)...)...)
+			},
+		}
+		if cfg.Apps.SyslogSink != "" && !dryRun {
+			sinkSetup.app = "syslog sink"
+			sinkSetup.validate = func() error {
+				return validateAppContract(measurement.NewSyslogSinkCounts(
+					fmt.Sprintf("http://%s:%d", f.CF.TCPDomain, f.CF.AvailablePort),
+					&http.Client{
+						Timeout: 10 * time.Second,
+						Transport: &http.Transport{
+							DisableKeepAlives: true,
+						},
+					},
+					clock,
+				))
+			}
 		}
+		setups = append(setups, sinkSetup)
 	}
 
-	orcCmdGenerator := cfCmdGenerator.New(orcTmpDir, *useBuildpackDetection)
-	orcWorkflow := createWorkflow(cfg.CF, appPath, *useQuotas)
+	fd.orcCmdGenerator = newCfCmdGenerator(f.CF, orcTmpDir, apps.appBuildpackDetection)
+	orcWorkflow := createWorkflow(f.CF, apps.appPath, useQuotas, metadata)
+	fd.orc = orchestrator.New(cfg.While, logger, orcWorkflow, cmdRunner.New(os.Stdout, os.Stderr, io.Copy), nil, &ioutilshim.IoutilShim{}, "", nil)
+	mainSetup := workflowSetup{
+		name: "main",
+		org:  orcWorkflow.Org(),
+		run: func(runner cmdRunner.CmdRunner) error {
+			return fd.orc.Setup(runner, fd.orcCmdGenerator, cfg.OptionalTests, cfg.SyslogDrain)
+		},
+	}
+	if cfg.Apps.App != "" && !dryRun {
+		mainSetup.app = "app"
+		mainSetup.validate = func() error {
+			return validateAppContract(createAppContractChecks(orcWorkflow, fd.orcCmdGenerator, timeouts)...)
+		}
+	}
+	setups = append(setups, mainSetup)
 
-	authFailedRetryFunc := func(stdOut, stdErr string) bool {
-		authFailedMessage := "Authentication has expired.  Please log back in to re-authenticate."
-		return strings.Contains(stdOut, authFailedMessage) || strings.Contains(stdErr, authFailedMessage)
+	var ok bool
+	fd.setupDurations, ok = setUpWorkflows(logger, clock, f.Name, setups, newSetupRunner, concurrently)
+	if !ok {
+		performMeasurements = false
 	}
-	clock := clock.New()
+
+	// Every CF_HOME is logged in to once; the measurements' commands reuse
+	// the session and refresh its token before it expires.
+	loginRunner, loginOutBuf, loginErrBuf := newSetupRunner()
+	login := func(ccg cfCmdGenerator.CfCmdGenerator) cfCmdGenerator.CfCmdGenerator {
+		if err := loginRunner.RunInSequence(fd.pushWorkflow.Login(ccg)...); err != nil {
+			logBufferedRunnerFailure(logger, "login", err, loginOutBuf, loginErrBuf)
+			performMeasurements = false
+		}
+		return ccg
+	}
+	pushWorkflowGeneratorFuncFor := func(appPath string) func() cfWorkflow.CfWorkflow {
+		return func() cfWorkflow.CfWorkflow {
+			return cfWorkflow.New(
+				f.CF,
+				fd.pushWorkflow.Org(),
+				fd.pushWorkflow.Space(),
+				fd.pushWorkflow.Quota(),
+				fmt.Sprintf("uptimer-app-%s", uuid.NewV4().String()),
+				appPath,
+				metadata,
+			)
+		}
+	}
+	pushWorkflowGeneratorFunc := pushWorkflowGeneratorFuncFor(apps.appPath)
+
+	deployWindow := measurement.NewDeployWindow()
 	measurements := createMeasurements(
 		clock,
 		logger,
 		orcWorkflow,
 		pushWorkflowGeneratorFunc,
-		cfCmdGenerator.New(recentLogsTmpDir, *useBuildpackDetection),
-		cfCmdGenerator.New(streamingLogsTmpDir, *useBuildpackDetection),
-		cfCmdGenerator.New(appStatsTmpDir, *useBuildpackDetection),
-		pushCmdGenerator,
+		login(newCfCmdGenerator(f.CF, recentLogsTmpDir, apps.appBuildpackDetection)),
+		login(newCfCmdGenerator(f.CF, streamingLogsTmpDir, apps.appBuildpackDetection)),
+		login(newCfCmdGenerator(f.CF, appStatsTmpDir, apps.appBuildpackDetection)),
+		fd.pushCmdGenerator,
+		appInstances(f.CF),
+		deployWindow,
 		cfg.AllowedFailures,
-		authFailedRetryFunc,
+		timeouts,
 	)
 
 	if cfg.OptionalTests.RunTcpAvailability {
@@ -191,10 +664,10 @@ func main() {
 			createTcpAvailabilityMeasurement(
 				clock,
 				logger,
-				tcpWorkflow,
-				tcpCmdGenerator,
+				fd.tcpWorkflow,
+				fd.tcpCmdGenerator,
 				cfg.AllowedFailures,
-				authFailedRetryFunc,
+				timeouts,
 			),
 		)
 	}
@@ -205,50 +678,116 @@ func main() {
 			createAppSyslogAvailabilityMeasurement(
 				clock,
 				logger,
-				sinkWorkflow,
-				sinkCmdGenerator,
+				f.CF,
+				cfg.SyslogDrain,
 				cfg.AllowedFailures,
-				authFailedRetryFunc,
+				timeouts,
 			),
 		)
 	}
 
-	logger.Printf("Setting up main workflow with org %s ...", orcWorkflow.Org())
-	orc := orchestrator.New(cfg.While, logger, orcWorkflow, cmdRunner.New(os.Stdout, os.Stderr, io.Copy), measurements, &ioutilshim.IoutilShim{})
-	if err = orc.Setup(bufferedRunner, orcCmdGenerator, cfg.OptionalTests); err != nil {
-		logBufferedRunnerFailure(logger, "main workflow setup", err, runnerOutBuf, runnerErrBuf)
-		performMeasurements = false
-	} else {
-		logger.Println("Finished setting up main workflow")
+	if cfg.OptionalTests.RunAggregateSyslogAvailability {
+		measurements = append(
+			measurements,
+			createAggregateSyslogAvailabilityMeasurement(
+				clock,
+				logger,
+				orcWorkflow,
+				f.CF,
+				cfg.SyslogDrain,
+				cfg.AllowedFailures,
+				timeouts,
+			),
+		)
 	}
 
-	if !cfg.OptionalTests.RunAppSyslogAvailability {
-		logger.Println("*NOT* running measurement: App syslog availability")
+	if cfg.OptionalTests.RunLogCacheAvailability {
+		logCacheTmpDir, err := fd.createTmpDir()
+		if err != nil {
+			logger.Println("Failed to create temp dir:", err)
+			performMeasurements = false
+		}
+		measurements = append(
+			measurements,
+			createLogCacheAvailabilityMeasurement(
+				clock,
+				logger,
+				orcWorkflow,
+				login(newCfCmdGenerator(f.CF, logCacheTmpDir, apps.appBuildpackDetection)),
+				f.CF,
+				cfg.AllowedFailures,
+				timeouts,
+			),
+		)
 	}
 
-	exitCode, err := orc.Run(performMeasurements, *resultPath)
-	if err != nil {
-		logger.Println("Failed run:", err)
+	if cfg.OptionalTests.RunRollingDeploy {
+		rollingDeployTmpDir, err := fd.createTmpDir()
+		if err != nil {
+			logger.Println("Failed to create temp dir:", err)
+			performMeasurements = false
+		}
+		measurements = append(
+			measurements,
+			createRollingDeployMeasurement(
+				clock,
+				logger,
+				orcWorkflow,
+				login(newCfCmdGenerator(f.CF, rollingDeployTmpDir, apps.appBuildpackDetection)),
+				deployWindow,
+				cfg.AllowedFailures,
+				timeouts,
+			),
+		)
 	}
 
-	logger.Println("Tearing down...")
-	tearDown(
-		orc,
-		orcCmdGenerator,
-		logger,
-		pushWorkflow,
-		pushCmdGenerator,
-		tcpWorkflow,
-		tcpCmdGenerator,
-		sinkWorkflow,
-		sinkCmdGenerator,
-		bufferedRunner,
-		runnerOutBuf,
-		runnerErrBuf,
-	)
-	logger.Println("Finished tearing down")
+	if cfg.OptionalTests.RunDockerPushability {
+		dockerPushTmpDir, err := fd.createTmpDir()
+		if err != nil {
+			logger.Println("Failed to create temp dir:", err)
+			performMeasurements = false
+		}
+		measurements = append(
+			measurements,
+			createDockerPushabilityMeasurement(
+				clock,
+				logger,
+				pushWorkflowGeneratorFunc,
+				login(newCfCmdGenerator(f.CF, dockerPushTmpDir, apps.appBuildpackDetection)),
+				cfg.DockerImage,
+				cfg.AllowedFailures,
+				timeouts,
+			),
+		)
+	}
 
-	os.Exit(exitCode)
+	for _, entry := range cfg.PushabilityMatrix {
+		matrixTmpDir, err := fd.createTmpDir()
+		if err != nil {
+			logger.Println("Failed to create temp dir:", err)
+			performMeasurements = false
+		}
+		measurements = append(
+			measurements,
+			createBuildpackPushabilityMeasurement(
+				clock,
+				logger,
+				pushWorkflowGeneratorFuncFor(apps.matrixAppPaths[entry.Name]),
+				login(newCfCmdGenerator(f.CF, matrixTmpDir, true)),
+				entry,
+				timeouts,
+			),
+		)
+	}
+
+	if f.Name != "" {
+		for i, m := range measurements {
+			measurements[i] = measurement.ForFoundation(f.Name, m)
+		}
+	}
+	fd.measurements = measurements
+
+	return fd, performMeasurements
 }
 
 func createTmpDirs() (string, string, string, string, string, string, string, error) {
@@ -284,7 +823,20 @@ func createTmpDirs() (string, string, string, string, string, string, string, er
 	return orcTmpDir, recentLogsTmpDir, streamingLogsTmpDir, appsStatsTmpDir, pushTmpDir, tcpTmpDir, sinkTmpDir, nil
 }
 
-func prepareIncludedApp(name, source string) (string, error) {
+// prepareApp returns a directory holding the user supplied app at
+// customPath, or the included app when no custom path is configured.
+func prepareApp(name, source string, env map[string]string, customPath string, binary bool) (string, error) {
+	if customPath != "" {
+		return appSource.Prepare(customPath)
+	}
+
+	return prepareIncludedApp(name, source, env, binary)
+}
+
+// prepareIncludedApp writes the included app and its manifest to a new
+// directory. With binary set, the app is compiled locally and pushed with the
+// binary buildpack instead of being staged from source.
+func prepareIncludedApp(name, source string, env map[string]string, binary bool) (string, error) {
 	dir, err := os.MkdirTemp("", "uptimer-sample-*")
 	if err != nil {
 		return "", err
@@ -296,17 +848,45 @@ func prepareIncludedApp(name, source string) (string, error) {
 		}
 	}()
 
-	err = os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0644)
-	if err != nil {
-		return "", err
-	}
+	var manifest string
+	if binary {
+		err = appSource.CompileLinuxBinary(source, filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+
+		manifest = fmt.Sprintf(`applications:
+- name: %s
+  memory: 64M
+  disk: 32M
+  buildpacks:
+  - binary_buildpack
+  command: ./%s`, name, name)
+		if len(env) > 0 {
+			manifest += "\n  env:"
+		}
+	} else {
+		err = os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0644)
+		if err != nil {
+			return "", err
+		}
 
-	manifest := fmt.Sprintf(`applications:
+		manifest = fmt.Sprintf(`applications:
 - name: %s
   memory: 64M
   disk: 16M
   env:
     GOPACKAGENAME: github.com/cloudfoundry/uptimer/%s`, name, name)
+	}
+
+	envNames := make([]string, 0, len(env))
+	for envName := range env {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
+	for _, envName := range envNames {
+		manifest += fmt.Sprintf("\n    %s: |\n      %s", envName, strings.ReplaceAll(strings.TrimSpace(env[envName]), "\n", "\n      "))
+	}
 
 	err = os.WriteFile(filepath.Join(dir, "manifest.yml"), []byte(manifest), 0644)
 	if err != nil {
@@ -316,7 +896,91 @@ func prepareIncludedApp(name, source string) (string, error) {
 	return dir, nil
 }
 
-func createWorkflow(cfc *config.Cf, appPath string, useQuotas bool) cfWorkflow.CfWorkflow {
+// createAppContractChecks returns the measurements a user supplied app has to
+// pass before uptimer relies on it: it must answer HTTP requests with 200 and
+// log a unix timestamp every second.
+func createAppContractChecks(orcWorkflow cfWorkflow.CfWorkflow, ccg cfCmdGenerator.CfCmdGenerator, timeouts config.Timeouts) []measurement.BaseMeasurement {
+	recentLogsRunner, recentLogsRunnerOutBuf, recentLogsRunnerErrBuf := createBufferedRunner(timeouts)
+
+	return []measurement.BaseMeasurement{
+		measurement.NewHTTPAvailability(
+			orcWorkflow.AppUrl(),
+			&http.Client{
+				Timeout: 30 * time.Second,
+				Transport: &http.Transport{
+					TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+					DisableKeepAlives: true,
+				},
+			},
+			nil,
+		),
+		measurement.NewRecentLogs(
+			func() []cmdStartWaiter.CmdStartWaiter {
+				return orcWorkflow.RecentLogs(ccg)
+			},
+			recentLogsRunner,
+			recentLogsRunnerOutBuf,
+			recentLogsRunnerErrBuf,
+			appLogValidator.New(),
+		),
+	}
+}
+
+// validateAppContract performs each check until it passes, giving a freshly
+// pushed app appContractTimeout to start serving requests and logging.
+func validateAppContract(checks ...measurement.BaseMeasurement) error {
+	deadline := time.Now().Add(appContractTimeout)
+	for _, check := range checks {
+		for {
+			msg, _, _, ok := check.PerformMeasurement()
+			if ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("app does not meet the contract of the %s measurement: %s", check.Name(), msg)
+			}
+			time.Sleep(5 * time.Second)
+		}
+	}
+
+	return nil
+}
+
+// syslogSinkTLSEnv returns the environment that gives the syslog sink app the
+// configured TLS certificate. Without it the sink generates a self-signed one.
+func syslogSinkTLSEnv(syslogDrain config.SyslogDrain) (map[string]string, error) {
+	if syslogDrain.TLSCertFile == "" {
+		return nil, nil
+	}
+
+	cert, err := os.ReadFile(syslogDrain.TLSCertFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := os.ReadFile(syslogDrain.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		"SINK_TLS_CERT": string(cert),
+		"SINK_TLS_KEY":  string(key),
+	}, nil
+}
+
+func createWorkflow(cfc *config.Cf, appPath string, useQuotas bool, metadata cfWorkflow.Metadata) cfWorkflow.CfWorkflow {
+	if cfc.ExistingSpace != nil {
+		return cfWorkflow.New(
+			cfc,
+			cfc.ExistingSpace.Org,
+			cfc.ExistingSpace.Space,
+			"",
+			fmt.Sprintf("uptimer-app-%s", uuid.NewV4().String()),
+			appPath,
+			metadata,
+		)
+	}
+
 	var quota string
 	if useQuotas {
 		quota = fmt.Sprintf("uptimer-quota-%s", uuid.NewV4().String())
@@ -328,19 +992,34 @@ func createWorkflow(cfc *config.Cf, appPath string, useQuotas bool) cfWorkflow.C
 		quota,
 		fmt.Sprintf("uptimer-app-%s", uuid.NewV4().String()),
 		appPath,
+		metadata,
 	)
 }
 
+// hostname is the host uptimer runs on, for annotating the resources it
+// creates.
+func hostname() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+
+	return host
+}
+
 func createMeasurements(
 	clock clock.Clock,
 	logger *log.Logger,
 	orcWorkflow cfWorkflow.CfWorkflow,
 	pushWorkFlowGeneratorFunc func() cfWorkflow.CfWorkflow,
 	recentLogsCmdGenerator, streamingLogsCmdGenerator, appStatsCmdGenerator, pushCmdGenerator cfCmdGenerator.CfCmdGenerator,
+	appInstances int,
+	deployWindow *measurement.DeployWindow,
 	allowedFailures config.AllowedFailures,
-	authFailedRetryFunc func(stdOut, stdErr string) bool,
+	timeouts config.Timeouts,
 ) []measurement.Measurement {
-	recentLogsBufferRunner, recentLogsRunnerOutBuf, recentLogsRunnerErrBuf := createBufferedRunner()
+	attemptTimeout := time.Duration(timeouts.AttemptSeconds) * time.Second
+	recentLogsBufferRunner, recentLogsRunnerOutBuf, recentLogsRunnerErrBuf := createBufferedRunner(timeouts)
 	recentLogsMeasurement := measurement.NewRecentLogs(
 		func() []cmdStartWaiter.CmdStartWaiter {
 			return orcWorkflow.RecentLogs(recentLogsCmdGenerator)
@@ -351,7 +1030,7 @@ func createMeasurements(
 		appLogValidator.New(),
 	)
 
-	streamingLogsBufferRunner, streamingLogsRunnerOutBuf, streamingLogsRunnerErrBuf := createBufferedRunner()
+	streamingLogsBufferRunner, streamingLogsRunnerOutBuf, streamingLogsRunnerErrBuf := createBufferedRunner(timeouts)
 	streamingLogsMeasurement := measurement.NewStreamingLogs(
 		func() (context.Context, context.CancelFunc, []cmdStartWaiter.CmdStartWaiter) {
 			ctx, cancelFunc := context.WithTimeout(context.Background(), 15*time.Second)
@@ -363,7 +1042,7 @@ func createMeasurements(
 		appLogValidator.New(),
 	)
 
-	pushRunner, pushRunnerOutBuf, pushRunnerErrBuf := createBufferedRunner()
+	pushRunner, pushRunnerOutBuf, pushRunnerErrBuf := createBufferedRunner(timeouts)
 	appPushabilityMeasurement := measurement.NewAppPushability(
 		func() []cmdStartWaiter.CmdStartWaiter {
 			w := pushWorkFlowGeneratorFunc()
@@ -386,9 +1065,10 @@ func createMeasurements(
 				DisableKeepAlives: true,
 			},
 		},
+		deployWindow,
 	)
 
-	appStatsRunner, appStatsRunnerOutBuf, appStatsRunnerErrBuf := createBufferedRunner()
+	appStatsRunner, appStatsRunnerOutBuf, appStatsRunnerErrBuf := createBufferedRunner(timeouts)
 	appStatsMeasurement := measurement.NewStatsAvailability(
 		func() []cmdStartWaiter.CmdStartWaiter {
 			return orcWorkflow.AppStats(appStatsCmdGenerator)
@@ -396,6 +1076,7 @@ func createMeasurements(
 		appStatsRunner,
 		appStatsRunnerOutBuf,
 		appStatsRunnerErrBuf,
+		appInstances,
 	)
 
 	return []measurement.Measurement{
@@ -406,7 +1087,8 @@ func createMeasurements(
 			httpAvailabilityMeasurement,
 			measurement.NewResultSet(),
 			allowedFailures.HttpAvailability,
-			func(string, string) bool { return false },
+			nil,
+			attemptTimeout,
 		),
 		measurement.NewPeriodic(
 			logger,
@@ -415,7 +1097,8 @@ func createMeasurements(
 			appPushabilityMeasurement,
 			measurement.NewResultSet(),
 			allowedFailures.AppPushability,
-			authFailedRetryFunc,
+			createTokenRefresher(orcWorkflow, pushCmdGenerator, timeouts),
+			attemptTimeout,
 		),
 		measurement.NewPeriodic(
 			logger,
@@ -424,7 +1107,8 @@ func createMeasurements(
 			recentLogsMeasurement,
 			measurement.NewResultSet(),
 			allowedFailures.RecentLogs,
-			authFailedRetryFunc,
+			createTokenRefresher(orcWorkflow, recentLogsCmdGenerator, timeouts),
+			attemptTimeout,
 		),
 		measurement.NewPeriodic(
 			logger,
@@ -433,7 +1117,8 @@ func createMeasurements(
 			streamingLogsMeasurement,
 			measurement.NewResultSet(),
 			allowedFailures.StreamingLogs,
-			authFailedRetryFunc,
+			createTokenRefresher(orcWorkflow, streamingLogsCmdGenerator, timeouts),
+			attemptTimeout,
 		),
 		measurement.NewPeriodic(
 			logger,
@@ -442,7 +1127,8 @@ func createMeasurements(
 			appStatsMeasurement,
 			measurement.NewResultSet(),
 			allowedFailures.AppStats,
-			authFailedRetryFunc,
+			createTokenRefresher(orcWorkflow, appStatsCmdGenerator, timeouts),
+			attemptTimeout,
 		),
 	}
 }
@@ -453,8 +1139,9 @@ func createTcpAvailabilityMeasurement(
 	tcpWorkflow cfWorkflow.CfWorkflow,
 	tcpCmdGenerator cfCmdGenerator.CfCmdGenerator,
 	allowedFailures config.AllowedFailures,
-	authFailedRetryFunc func(stdOut, stdErr string) bool,
+	timeouts config.Timeouts,
 ) measurement.Measurement {
+	attemptTimeout := time.Duration(timeouts.AttemptSeconds) * time.Second
 	tcpAvailabilityMeasurement := measurement.NewTCPAvailability(
 		tcpWorkflow.TCPDomain(),
 		tcpWorkflow.TCPPort())
@@ -466,27 +1153,34 @@ func createTcpAvailabilityMeasurement(
 		tcpAvailabilityMeasurement,
 		measurement.NewResultSet(),
 		allowedFailures.TCPAvailability,
-		func(string, string) bool { return false },
+		nil,
+		attemptTimeout,
 	)
 }
 
 func createAppSyslogAvailabilityMeasurement(
 	clock clock.Clock,
 	logger *log.Logger,
-	sinkWorkflow cfWorkflow.CfWorkflow,
-	sinkCmdGenerator cfCmdGenerator.CfCmdGenerator,
+	cfc *config.Cf,
+	syslogDrain config.SyslogDrain,
 	allowedFailures config.AllowedFailures,
-	authFailedRetryFunc func(stdOut, stdErr string) bool,
+	timeouts config.Timeouts,
 ) measurement.Measurement {
-	syslogAvailabilityBufferRunner, syslogAvailabilityRunnerOutBuf, syslogAvailabilityRunnerErrBuf := createBufferedRunner()
+	attemptTimeout := time.Duration(timeouts.AttemptSeconds) * time.Second
+	// Measurements are created once setup, which binds the drain, is done.
 	syslogAvailabilityMeasurement := measurement.NewSyslogDrain(
-		func() []cmdStartWaiter.CmdStartWaiter {
-			return sinkWorkflow.RecentLogs(sinkCmdGenerator)
+		fmt.Sprintf("http://%s:%d", cfc.TCPDomain, cfc.AvailablePort),
+		"",
+		clock.Now(),
+		appInstances(cfc),
+		syslogDrain.MaxLossPercent,
+		&http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DisableKeepAlives: true,
+			},
 		},
-		syslogAvailabilityBufferRunner,
-		syslogAvailabilityRunnerOutBuf,
-		syslogAvailabilityRunnerErrBuf,
-		appLogValidator.New(),
+		clock,
 	)
 
 	return measurement.NewPeriodicWithoutMeasuringImmediately(
@@ -496,15 +1190,265 @@ func createAppSyslogAvailabilityMeasurement(
 		syslogAvailabilityMeasurement,
 		measurement.NewResultSet(),
 		allowedFailures.AppSyslogAvailability,
-		authFailedRetryFunc,
+		nil,
+		attemptTimeout,
+	)
+}
+
+func createAggregateSyslogAvailabilityMeasurement(
+	clock clock.Clock,
+	logger *log.Logger,
+	orcWorkflow cfWorkflow.CfWorkflow,
+	cfc *config.Cf,
+	syslogDrain config.SyslogDrain,
+	allowedFailures config.AllowedFailures,
+	timeouts config.Timeouts,
+) measurement.Measurement {
+	attemptTimeout := time.Duration(timeouts.AttemptSeconds) * time.Second
+	// Aggregate drains carry every app's logs; the syslog hostname of the
+	// measured app's logs is "<org>.<space>.<app>".
+	aggregateSyslogAvailabilityMeasurement := measurement.NewAggregateSyslogDrain(
+		strings.TrimSuffix(syslogDrain.AggregateSinkURL, "/"),
+		fmt.Sprintf("%s.%s.%s", orcWorkflow.Org(), orcWorkflow.Space(), orcWorkflow.AppName()),
+		clock.Now(),
+		appInstances(cfc),
+		syslogDrain.MaxLossPercent,
+		&http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+				DisableKeepAlives: true,
+			},
+		},
+		clock,
+	)
+
+	return measurement.NewPeriodicWithoutMeasuringImmediately(
+		logger,
+		clock,
+		30*time.Second,
+		aggregateSyslogAvailabilityMeasurement,
+		measurement.NewResultSet(),
+		allowedFailures.AggregateSyslogAvailability,
+		nil,
+		attemptTimeout,
 	)
 }
 
-func createBufferedRunner() (cmdRunner.CmdRunner, *bytes.Buffer, *bytes.Buffer) {
+func createLogCacheAvailabilityMeasurement(
+	clock clock.Clock,
+	logger *log.Logger,
+	orcWorkflow cfWorkflow.CfWorkflow,
+	logCacheCmdGenerator cfCmdGenerator.CfCmdGenerator,
+	cfc *config.Cf,
+	allowedFailures config.AllowedFailures,
+	timeouts config.Timeouts,
+) measurement.Measurement {
+	attemptTimeout := time.Duration(timeouts.AttemptSeconds) * time.Second
+	logCacheRunner, logCacheRunnerOutBuf, logCacheRunnerErrBuf := createBufferedRunner(timeouts)
+	logCacheMeasurement := measurement.NewLogCache(
+		logCacheUrl(cfc),
+		func() []cmdStartWaiter.CmdStartWaiter {
+			return orcWorkflow.AppGuid(logCacheCmdGenerator)
+		},
+		func() []cmdStartWaiter.CmdStartWaiter {
+			return orcWorkflow.OauthToken(logCacheCmdGenerator)
+		},
+		logCacheRunner,
+		logCacheRunnerOutBuf,
+		logCacheRunnerErrBuf,
+		&http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+				DisableKeepAlives: true,
+			},
+		},
+		appLogValidator.New(),
+	)
+
+	return measurement.NewPeriodic(
+		logger,
+		clock,
+		10*time.Second,
+		logCacheMeasurement,
+		measurement.NewResultSet(),
+		allowedFailures.LogCacheAvailability,
+		createTokenRefresher(orcWorkflow, logCacheCmdGenerator, timeouts),
+		attemptTimeout,
+	)
+}
+
+func createRollingDeployMeasurement(
+	clock clock.Clock,
+	logger *log.Logger,
+	orcWorkflow cfWorkflow.CfWorkflow,
+	rollingDeployCmdGenerator cfCmdGenerator.CfCmdGenerator,
+	deployWindow *measurement.DeployWindow,
+	allowedFailures config.AllowedFailures,
+	timeouts config.Timeouts,
+) measurement.Measurement {
+	attemptTimeout := time.Duration(timeouts.AttemptSeconds) * time.Second
+	rollingDeployRunner, rollingDeployRunnerOutBuf, rollingDeployRunnerErrBuf := createBufferedRunner(timeouts)
+	rollingDeployMeasurement := measurement.NewRollingDeploy(
+		func() []cmdStartWaiter.CmdStartWaiter {
+			return orcWorkflow.RollingDeploy(rollingDeployCmdGenerator)
+		},
+		rollingDeployRunner,
+		rollingDeployRunnerOutBuf,
+		rollingDeployRunnerErrBuf,
+		deployWindow,
+	)
+
+	return measurement.NewPeriodic(
+		logger,
+		clock,
+		5*time.Minute,
+		rollingDeployMeasurement,
+		measurement.NewResultSet(),
+		allowedFailures.RollingDeploy,
+		createTokenRefresher(orcWorkflow, rollingDeployCmdGenerator, timeouts),
+		attemptTimeout,
+	)
+}
+
+func createBuildpackPushabilityMeasurement(
+	clock clock.Clock,
+	logger *log.Logger,
+	pushWorkFlowGeneratorFunc func() cfWorkflow.CfWorkflow,
+	matrixCmdGenerator cfCmdGenerator.CfCmdGenerator,
+	entry config.PushabilityMatrixEntry,
+	timeouts config.Timeouts,
+) measurement.Measurement {
+	attemptTimeout := time.Duration(timeouts.AttemptSeconds) * time.Second
+	matrixRunner, matrixRunnerOutBuf, matrixRunnerErrBuf := createBufferedRunner(timeouts)
+	buildpackPushabilityMeasurement := measurement.NewBuildpackPushability(
+		entry.Name,
+		func() []cmdStartWaiter.CmdStartWaiter {
+			w := pushWorkFlowGeneratorFunc()
+			return append(
+				w.PushWithBuildpack(matrixCmdGenerator, entry.Buildpack, entry.Stack),
+				w.Delete(matrixCmdGenerator)...,
+			)
+		},
+		matrixRunner,
+		matrixRunnerOutBuf,
+		matrixRunnerErrBuf,
+	)
+
+	return measurement.NewPeriodic(
+		logger,
+		clock,
+		time.Minute,
+		buildpackPushabilityMeasurement,
+		measurement.NewResultSet(),
+		entry.AllowedFailures,
+		createTokenRefresher(pushWorkFlowGeneratorFunc(), matrixCmdGenerator, timeouts),
+		attemptTimeout,
+	)
+}
+
+func createDockerPushabilityMeasurement(
+	clock clock.Clock,
+	logger *log.Logger,
+	pushWorkFlowGeneratorFunc func() cfWorkflow.CfWorkflow,
+	dockerPushCmdGenerator cfCmdGenerator.CfCmdGenerator,
+	dockerImage config.DockerImage,
+	allowedFailures config.AllowedFailures,
+	timeouts config.Timeouts,
+) measurement.Measurement {
+	attemptTimeout := time.Duration(timeouts.AttemptSeconds) * time.Second
+	image := dockerImage.Image
+	if image == "" {
+		image = defaultDockerImage
+	}
+
+	dockerPushRunner, dockerPushRunnerOutBuf, dockerPushRunnerErrBuf := createBufferedRunner(timeouts)
+	dockerPushabilityMeasurement := measurement.NewDockerPushability(
+		func() []cmdStartWaiter.CmdStartWaiter {
+			w := pushWorkFlowGeneratorFunc()
+			return append(
+				w.PushDockerImage(dockerPushCmdGenerator, image, dockerImage.Username, dockerImage.Password),
+				w.Delete(dockerPushCmdGenerator)...,
+			)
+		},
+		dockerPushRunner,
+		dockerPushRunnerOutBuf,
+		dockerPushRunnerErrBuf,
+	)
+
+	return measurement.NewPeriodic(
+		logger,
+		clock,
+		time.Minute,
+		dockerPushabilityMeasurement,
+		measurement.NewResultSet(),
+		allowedFailures.DockerPushability,
+		createTokenRefresher(pushWorkFlowGeneratorFunc(), dockerPushCmdGenerator, timeouts),
+		attemptTimeout,
+	)
+}
+
+// logCacheUrl returns the configured Log Cache URL, or derives it from the
+// API URL by the usual "log-cache.<system domain>" convention.
+func logCacheUrl(cfc *config.Cf) string {
+	if cfc.LogCacheURL != "" {
+		return strings.TrimSuffix(cfc.LogCacheURL, "/")
+	}
+
+	host := strings.TrimPrefix(strings.TrimPrefix(cfc.API, "https://"), "http://")
+	return fmt.Sprintf("https://log-cache.%s", strings.TrimPrefix(strings.TrimSuffix(host, "/"), "api."))
+}
+
+func appInstances(cfc *config.Cf) int {
+	if cfc.UseSingleAppInstance {
+		return 1
+	}
+
+	return 2
+}
+
+// newCfCmdGenerator returns a generator for the backend configured for the
+// foundation. The API backend keeps its session in memory, so it has no use
+// for cfHome.
+func newCfCmdGenerator(cfc *config.Cf, cfHome string, useBuildpackDetection bool) cfCmdGenerator.CfCmdGenerator {
+	if cfc.Backend == config.BackendAPI {
+		return cfApi.New(
+			&http.Client{
+				Timeout: time.Minute,
+				Transport: &http.Transport{
+					Proxy:           http.ProxyFromEnvironment,
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				},
+			},
+			useBuildpackDetection,
+		)
+	}
+
+	return cfCmdGenerator.New(cfHome, useBuildpackDetection)
+}
+
+// createBufferedRunner returns a runner that writes the output of commands
+// to the buffers it returns, and kills commands that exceed timeouts.
+func createBufferedRunner(timeouts config.Timeouts) (cmdRunner.CmdRunner, *bytes.Buffer, *bytes.Buffer) {
 	outBuf := bytes.NewBuffer([]byte{})
 	errBuf := bytes.NewBuffer([]byte{})
 
-	return cmdRunner.New(outBuf, errBuf, io.Copy), outBuf, errBuf
+	commandTimeouts := cmdRunner.Timeouts{
+		Default:  time.Duration(timeouts.CommandSeconds) * time.Second,
+		Commands: map[string]time.Duration{},
+	}
+	for kind, seconds := range timeouts.Commands {
+		commandTimeouts.Commands[kind] = time.Duration(seconds) * time.Second
+	}
+
+	return cmdRunner.NewWithTimeouts(outBuf, errBuf, io.Copy, commandTimeouts), outBuf, errBuf
+}
+
+func createTokenRefresher(workflow cfWorkflow.CfWorkflow, ccg cfCmdGenerator.CfCmdGenerator, timeouts config.Timeouts) measurement.TokenRefresher {
+	runner, outBuf, errBuf := createBufferedRunner(timeouts)
+
+	return cfWorkflow.NewTokenRefresher(workflow, ccg, clock.New(), runner, outBuf, errBuf)
 }
 
 func logBufferedRunnerFailure(
@@ -524,36 +1468,132 @@ func logBufferedRunnerFailure(
 	errBuf.Reset()
 }
 
+// tearDown tears down the workflows of fd, concurrently if so told, each
+// with a runner of its own from newRunner, and then removes its CF_HOMEs.
 func tearDown(
-	orc orchestrator.Orchestrator,
-	orcCmdGenerator cfCmdGenerator.CfCmdGenerator,
-	logger *log.Logger,
-	pushWorkflow cfWorkflow.CfWorkflow,
-	pushCmdGenerator cfCmdGenerator.CfCmdGenerator,
-	tcpWorkflow cfWorkflow.CfWorkflow,
-	tcpCmdGenerator cfCmdGenerator.CfCmdGenerator,
-	sinkWorkflow cfWorkflow.CfWorkflow,
-	sinkCmdGenerator cfCmdGenerator.CfCmdGenerator,
-	runner cmdRunner.CmdRunner,
-	runnerOutBuf *bytes.Buffer,
-	runnerErrBuf *bytes.Buffer,
+	fd *foundation,
+	newRunner func() (cmdRunner.CmdRunner, *bytes.Buffer, *bytes.Buffer),
+	concurrently bool,
 ) {
-	if err := orc.TearDown(runner, orcCmdGenerator); err != nil {
-		logBufferedRunnerFailure(logger, "main teardown", err, runnerOutBuf, runnerErrBuf)
+	teardowns := []workflowTeardown{
+		{"main", func(runner cmdRunner.CmdRunner) error {
+			return fd.orc.TearDown(runner, fd.orcCmdGenerator)
+		}},
+		{"push workflow", func(runner cmdRunner.CmdRunner) error {
+			return runner.RunInSequence(fd.pushWorkflow.TearDown(fd.pushCmdGenerator)...)
+		}},
+	}
+	if fd.tcpWorkflow != nil {
+		teardowns = append(teardowns, workflowTeardown{"tcp workflow", func(runner cmdRunner.CmdRunner) error {
+			return runner.RunInSequence(fd.tcpWorkflow.TearDown(fd.tcpCmdGenerator)...)
+		}})
+	}
+	if fd.sinkWorkflow != nil {
+		teardowns = append(teardowns, workflowTeardown{"sink workflow", func(runner cmdRunner.CmdRunner) error {
+			return runner.RunInSequence(fd.sinkWorkflow.TearDown(fd.sinkCmdGenerator)...)
+		}})
 	}
 
-	if err := runner.RunInSequence(pushWorkflow.TearDown(pushCmdGenerator)...); err != nil {
-		logBufferedRunnerFailure(logger, "push workflow teardown", err, runnerOutBuf, runnerErrBuf)
+	runAll(concurrently, len(teardowns), func(i int) {
+		runner, outBuf, errBuf := newRunner()
+		if err := teardowns[i].run(runner); err != nil {
+			logBufferedRunnerFailure(fd.logger, teardowns[i].name+" teardown", err, outBuf, errBuf)
+		}
+	})
+
+	for _, dir := range fd.tmpDirs {
+		if dir != "" {
+			os.RemoveAll(dir) //nolint:errcheck
+		}
 	}
+}
+
+type workflowTeardown struct {
+	name string
+	run  func(runner cmdRunner.CmdRunner) error
+}
+
+// workflowSetup sets up one of the workflows of a foundation, and validates
+// its app afterwards if validate is set.
+type workflowSetup struct {
+	name     string
+	org      string
+	run      func(runner cmdRunner.CmdRunner) error
+	app      string
+	validate func() error
+}
+
+// setUpWorkflows runs setups concurrently if so told, each with a runner and
+// buffers of its own from newRunner, so that their output is not mixed up.
+// It returns how long each setup took, and reports false if any failed.
+func setUpWorkflows(
+	logger *log.Logger,
+	clock clock.Clock,
+	foundationName string,
+	setups []workflowSetup,
+	newRunner func() (cmdRunner.CmdRunner, *bytes.Buffer, *bytes.Buffer),
+	concurrently bool,
+) ([]orchestrator.SetupDuration, bool) {
+	durations := make([]orchestrator.SetupDuration, len(setups))
+	succeeded := make([]bool, len(setups))
+
+	runAll(concurrently, len(setups), func(i int) {
+		setup := setups[i]
+		runner, outBuf, errBuf := newRunner()
+
+		logger.Printf("Setting up %s workflow with org %s ...", setup.name, setup.org)
+		start := clock.Now()
+		err := setup.run(runner)
+		took := clock.Since(start)
+		durations[i] = orchestrator.SetupDuration{
+			Foundation:      foundationName,
+			Workflow:        setup.name,
+			DurationSeconds: took.Seconds(),
+			Failed:          err != nil,
+		}
+		if err != nil {
+			logBufferedRunnerFailure(logger, setup.name+" workflow setup", err, outBuf, errBuf)
+			return
+		}
+		logger.Printf("Finished setting up %s workflow in %s", setup.name, took.Round(time.Millisecond))
 
-	if tcpWorkflow != nil {
-		if err := runner.RunInSequence(tcpWorkflow.TearDown(tcpCmdGenerator)...); err != nil {
-			logBufferedRunnerFailure(logger, "tcp workflow teardown", err, runnerOutBuf, runnerErrBuf)
+		if setup.validate != nil {
+			logger.Printf("Validating %s...", setup.app)
+			if err := setup.validate(); err != nil {
+				logger.Printf("Failed to validate %s: %s", setup.app, err)
+				return
+			}
+			logger.Printf("Finished validating %s", setup.app)
+		}
+		succeeded[i] = true
+	})
+
+	for _, ok := range succeeded {
+		if !ok {
+			return durations, false
 		}
 	}
-	if sinkWorkflow != nil {
-		if err := runner.RunInSequence(sinkWorkflow.TearDown(sinkCmdGenerator)...); err != nil {
-			logBufferedRunnerFailure(logger, "sink workflow teardown", err, runnerOutBuf, runnerErrBuf)
+
+	return durations, true
+}
+
+// runAll calls run with 0 through n-1, concurrently if so told, and returns
+// once all calls have.
+func runAll(concurrently bool, n int, run func(i int)) {
+	if !concurrently {
+		for i := 0; i < n; i++ {
+			run(i)
 		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			run(i)
+		}(i)
 	}
+	wg.Wait()
 }
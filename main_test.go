@@ -2,8 +2,15 @@ package main_test
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/cloudfoundry/uptimer/config"
 
@@ -15,8 +22,10 @@ import (
 
 var _ = Describe("uptimer", func() {
 	var (
-		cfg     *config.Config
-		session *Session
+		cfg       *config.Config
+		extraArgs []string
+		extraEnv  []string
+		session   *Session
 	)
 
 	BeforeEach(func() {
@@ -40,6 +49,8 @@ var _ = Describe("uptimer", func() {
 				StreamingLogs:    2,
 			},
 		}
+		extraArgs = nil
+		extraEnv = nil
 	})
 
 	JustBeforeEach(func() {
@@ -51,7 +62,11 @@ var _ = Describe("uptimer", func() {
 		Expect(err).NotTo(HaveOccurred())
 		_, err = f.Write(b)
 		Expect(err).NotTo(HaveOccurred())
-		cmd := exec.Command(uptimerPath, "-configFile", f.Name())
+		args := append(extraArgs, "-configFile", f.Name())
+		cmd := exec.Command(uptimerPath, args...)
+		if extraEnv != nil {
+			cmd.Env = append(os.Environ(), extraEnv...)
+		}
 		session, err = Start(cmd, GinkgoWriter, GinkgoWriter)
 		Expect(err).NotTo(HaveOccurred())
 		Eventually(session, "10s").Should(Exit())
@@ -100,4 +115,278 @@ var _ = Describe("uptimer", func() {
 			})
 		})
 	})
+
+	Context("when doing a dry run", func() {
+		BeforeEach(func() {
+			extraArgs = []string{"-dryRun"}
+		})
+
+		It("exits with a code of 0", func() {
+			Expect(session.ExitCode()).To(Equal(0))
+		})
+
+		It("prints the setup, measurement, while and teardown commands with their CF_HOME", func() {
+			Expect(session.Out).To(Say(`CF_HOME=\S+ \(in \S+\) cf create-org uptimer-org-`))
+			Expect(session.Out).To(Say(`Measurement App pushability runs:`))
+			Expect(session.Out).To(Say(`CF_HOME=\S+ \(in \S+\) cf push uptimer-app-`))
+			Expect(session.Out).To(Say(`While commands:`))
+			Expect(session.Out).To(Say(`\(in \S+\) sleep 5`))
+			Expect(session.Out).To(Say(`Tearing down...`))
+			Expect(session.Out).To(Say(`CF_HOME=\S+ \(in \S+\) cf delete-org uptimer-org-`))
+		})
+
+		It("labels the org it creates with the run ID and annotates it", func() {
+			Expect(session.Out).To(Say(`Run ID: \S+`))
+			Expect(session.Out).To(Say(`cf set-label org uptimer-org-\S+ uptimer.cloudfoundry.org/run-id=\S+\n`))
+			Expect(session.Out).To(Say(`set-annotation org uptimer-org-\S+ organizations {"metadata":{"annotations":{"uptimer.cloudfoundry.org/host":"[^"]+","uptimer.cloudfoundry.org/started-at":"\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ","uptimer.cloudfoundry.org/version":"[^"]*"}}}`))
+		})
+
+		Context("with the optional measurements that have a CF_HOME of their own", func() {
+			var tmpDir string
+
+			BeforeEach(func() {
+				tmpDir = GinkgoT().TempDir()
+				extraEnv = []string{"TMPDIR=" + tmpDir}
+				cfg.OptionalTests.RunRollingDeploy = true
+				cfg.PushabilityMatrix = []config.PushabilityMatrixEntry{{Name: "staticfile", Buildpack: "staticfile_buildpack"}}
+			})
+
+			It("removes every CF_HOME after tearing down", func() {
+				Expect(session.ExitCode()).To(Equal(0))
+				Expect(session.Out).To(Say(`CF_HOME=` + regexp.QuoteMeta(tmpDir)))
+
+				cfHomes, err := filepath.Glob(filepath.Join(tmpDir, "uptimer[0-9]*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfHomes).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("when validating the config", func() {
+		BeforeEach(func() {
+			extraArgs = []string{"validate"}
+		})
+
+		It("exits with a code of 0", func() {
+			Expect(session.ExitCode()).To(Equal(0))
+		})
+
+		It("reports that the config is valid", func() {
+			Expect(session.Out).To(Say("config.json is valid"))
+		})
+
+		Context("when the config has several problems", func() {
+			BeforeEach(func() {
+				cfg.While = nil
+				cfg.CF.AppDomain = ""
+				cfg.AllowedFailures.RecentLogs = -1
+			})
+
+			It("exits with a error code of 1", func() {
+				Expect(session.ExitCode()).To(Equal(1))
+			})
+
+			It("prints every error", func() {
+				Expect(session.Out).To(Say("config.json is invalid:"))
+				Expect(session.Out).To(Say("  - `while` must list at least one command"))
+				Expect(session.Out).To(Say("  - `cf.app_domain` must be set"))
+				Expect(session.Out).To(Say("  - `allowed_failures.recent_logs` must not be negative, got -1"))
+			})
+		})
+	})
+})
+
+var _ = Describe("uptimer when interrupted", func() {
+	var (
+		whileCommand *config.Command
+		session      *Session
+	)
+
+	BeforeEach(func() {
+		whileCommand = &config.Command{
+			Command:     "sh",
+			CommandArgs: []string{"-c", "echo while command ready; exec sleep 30"},
+		}
+	})
+
+	JustBeforeEach(func() {
+		cfg := &config.Config{
+			While: []*config.Command{whileCommand},
+			CF: &config.Cf{
+				API:           "api.my-cf.com",
+				AppDomain:     "my-cf.com",
+				AdminUser:     "admin",
+				AdminPassword: "pass",
+			},
+		}
+		configPath := GinkgoT().TempDir() + "/config.json"
+		b, err := json.Marshal(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(configPath, b, 0600)).To(Succeed())
+
+		session, err = Start(exec.Command(uptimerPath, "-configFile", configPath), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		// Signals are only sent once the while command is ready for them.
+		Eventually(session.Out, "10s").Should(Say("while command ready"))
+
+		session.Terminate()
+	})
+
+	It("stops the while command, tears down and exits with the code of the signal", func() {
+		Eventually(session, "10s").Should(Exit(143))
+		Expect(session.Out).To(Say("Run cancelled by terminated"))
+		Expect(session.Out).To(Say("Tearing down..."))
+		Expect(session.Out).To(Say("Finished tearing down"))
+	})
+
+	Context("when the while command ignores the signal", func() {
+		BeforeEach(func() {
+			whileCommand = &config.Command{
+				Command:     "sh",
+				CommandArgs: []string{"-c", "trap '' TERM; echo while command ready; sleep 10"},
+			}
+		})
+
+		It("exits without tearing down on a second signal", func() {
+			Eventually(session.Out).Should(Say("Forwarded terminated to the running command"))
+			Consistently(session, "500ms").ShouldNot(Exit())
+
+			session.Terminate()
+
+			Eventually(session, "10s").Should(Exit(143))
+			Expect(session.Out).To(Say("exiting without tearing down"))
+			Expect(session.Out).NotTo(Say("Tearing down..."))
+		})
+	})
+})
+
+var _ = Describe("uptimer cleanup", func() {
+	var (
+		cc          *httptest.Server
+		deletedOrgs []string
+		extraArgs   []string
+		stdin       string
+		session     *Session
+	)
+
+	BeforeEach(func() {
+		deletedOrgs = nil
+		extraArgs = nil
+		stdin = ""
+
+		var mu sync.Mutex
+		cc = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.URL.Path {
+			case "GET /":
+				fmt.Fprintf(w, `{"links": {"uaa": {"href": "http://%s"}}}`, r.Host) //nolint:errcheck
+			case "POST /oauth/token":
+				fmt.Fprint(w, `{"access_token": "some-token"}`) //nolint:errcheck
+			case "GET /v3/organizations":
+				// Only the org of uptimer is labelled.
+				query := r.URL.Query()
+				if query.Get("label_selector") != "uptimer.cloudfoundry.org/run-id" && query.Get("names") != "uptimer-org-old" {
+					fmt.Fprint(w, `{"resources": [{"guid": "system-guid", "name": "system", "created_at": "2020-01-01T00:00:00Z"}]}`) //nolint:errcheck
+					return
+				}
+				fmt.Fprint(w, `{"resources": [
+					{"guid": "old-org-guid", "name": "uptimer-org-old", "created_at": "2020-01-01T00:00:00Z", "metadata": {"labels": {"uptimer.cloudfoundry.org/run-id": "old-run"}}}
+				]}`) //nolint:errcheck
+			case "GET /v3/service_instances", "GET /v3/organization_quotas":
+				fmt.Fprint(w, `{"resources": []}`) //nolint:errcheck
+			case "DELETE /v3/organizations/old-org-guid":
+				mu.Lock()
+				deletedOrgs = append(deletedOrgs, "uptimer-org-old")
+				mu.Unlock()
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		cc.Close()
+	})
+
+	JustBeforeEach(func() {
+		cfg := &config.Config{
+			While: []*config.Command{{Command: "sleep", CommandArgs: []string{"5"}}},
+			CF: &config.Cf{
+				API:           cc.URL,
+				AppDomain:     "my-cf.com",
+				AdminUser:     "admin",
+				AdminPassword: "pass",
+				Backend:       config.BackendAPI,
+			},
+		}
+		configPath := GinkgoT().TempDir() + "/config.json"
+		b, err := json.Marshal(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(configPath, b, 0600)).To(Succeed())
+
+		cmd := exec.Command(uptimerPath, append([]string{"cleanup", "-configFile", configPath}, extraArgs...)...)
+		cmd.Stdin = strings.NewReader(stdin)
+		session, err = Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session, "10s").Should(Exit())
+	})
+
+	It("lists what it found and deletes nothing without confirmation", func() {
+		Expect(session.ExitCode()).To(Equal(0))
+		Expect(session.Out).To(Say("Found 1 resources to clean up:"))
+		Expect(session.Out).To(Say(`  - org uptimer-org-old \(run old-run, created 2020-01-01T00:00:00Z\)`))
+		Expect(session.Out).To(Say(`Delete them\? \[y/N\]`))
+		Expect(session.Out).To(Say("Not deleting anything"))
+		Expect(deletedOrgs).To(BeEmpty())
+	})
+
+	Context("when the deletion is confirmed", func() {
+		BeforeEach(func() {
+			stdin = "y\n"
+		})
+
+		It("deletes what it found and reports it", func() {
+			Expect(session.ExitCode()).To(Equal(0))
+			Expect(session.Out).To(Say("Deleted org uptimer-org-old"))
+			Expect(session.Out).To(Say("Deleted 1 resources"))
+			Expect(deletedOrgs).To(Equal([]string{"uptimer-org-old"}))
+		})
+	})
+
+	Context("with -force", func() {
+		BeforeEach(func() {
+			extraArgs = []string{"-force"}
+		})
+
+		It("deletes without asking", func() {
+			Expect(session.ExitCode()).To(Equal(0))
+			Expect(session.Out).NotTo(Say("WARN"))
+			Expect(session.Out).NotTo(Say(`Delete them\?`))
+			Expect(deletedOrgs).To(Equal([]string{"uptimer-org-old"}))
+		})
+	})
+
+	Context("with -olderThan", func() {
+		BeforeEach(func() {
+			extraArgs = []string{"-olderThan", "87600h", "-force"}
+		})
+
+		It("spares what is newer", func() {
+			Expect(session.ExitCode()).To(Equal(0))
+			Expect(session.Out).To(Say("Found nothing to clean up"))
+			Expect(deletedOrgs).To(BeEmpty())
+		})
+	})
+
+	Context("with -olderThan 0", func() {
+		BeforeEach(func() {
+			extraArgs = []string{"-olderThan", "0", "-force"}
+		})
+
+		It("warns that runs in progress lose their resources too", func() {
+			Expect(session.ExitCode()).To(Equal(0))
+			Expect(session.Out).To(Say("WARN: -olderThan is 0, so the resources of runs still in progress are deleted too"))
+			Expect(deletedOrgs).To(Equal([]string{"uptimer-org-old"}))
+		})
+	})
 })
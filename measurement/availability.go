@@ -11,6 +11,7 @@ type availability struct {
 	summaryPhrase string
 	url           string
 	client        *http.Client
+	deployWindow  *DeployWindow
 }
 
 func (a *availability) Name() string {
@@ -22,9 +23,18 @@ func (a *availability) SummaryPhrase() string {
 }
 
 func (a *availability) PerformMeasurement() (string, string, string, bool) {
+	msg, ok := a.get()
+	if !ok && a.deployWindow != nil && a.deployWindow.RecordFailure() {
+		msg = fmt.Sprintf("%s (during rolling deploy)", msg)
+	}
+
+	return msg, "", "", ok
+}
+
+func (a *availability) get() (string, bool) {
 	res, err := a.client.Get(a.url)
 	if err != nil {
-		return err.Error(), "", "", false
+		return err.Error(), false
 	}
 	defer res.Body.Close() //nolint:errcheck
 
@@ -32,10 +42,10 @@ func (a *availability) PerformMeasurement() (string, string, string, bool) {
 		buf := new(bytes.Buffer)
 		_, err := buf.ReadFrom(res.Body)
 		if err != nil {
-			return err.Error(), "", "", false
+			return err.Error(), false
 		}
-		return fmt.Sprintf("response had status %d; %s; %s", res.StatusCode, res.Status, buf.String()), "", "", false
+		return fmt.Sprintf("response had status %d; %s; %s", res.StatusCode, res.Status, buf.String()), false
 	}
 
-	return "", "", "", true
+	return "", true
 }
@@ -45,7 +45,7 @@ var _ = Describe("Availability", func() {
 			Transport: fakeRoundTripper,
 		}
 
-		am = NewHTTPAvailability(url, client)
+		am = NewHTTPAvailability(url, client, nil)
 	})
 
 	Describe("Name", func() {
@@ -139,6 +139,43 @@ var _ = Describe("Availability", func() {
 
 			Expect(func() { am.PerformMeasurement() }).NotTo(Panic())
 		})
+
+		Context("when a rolling deploy of the app is in progress", func() {
+			var deployWindow *DeployWindow
+
+			BeforeEach(func() {
+				deployWindow = NewDeployWindow()
+				deployWindow.Open()
+				am = NewHTTPAvailability(url, client, deployWindow)
+			})
+
+			It("attributes failures to the deploy", func() {
+				fakeRoundTripper.RoundTripReturns(failResponse, nil)
+
+				msg, _, _, res := am.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("response had status 400; Bad Request; Body of the error here (during rolling deploy)"))
+				Expect(deployWindow.Close()).To(Equal(1))
+			})
+
+			It("does not count successes against the deploy", func() {
+				am.PerformMeasurement()
+
+				Expect(deployWindow.Close()).To(Equal(0))
+			})
+		})
+
+		Context("when no rolling deploy is in progress", func() {
+			It("does not mention a deploy", func() {
+				am = NewHTTPAvailability(url, client, NewDeployWindow())
+				fakeRoundTripper.RoundTripReturns(failResponse, nil)
+
+				msg, _, _, _ := am.PerformMeasurement()
+
+				Expect(msg).To(Equal("response had status 400; Bad Request; Body of the error here"))
+			})
+		})
 	})
 })
 
@@ -0,0 +1,46 @@
+package measurement
+
+import "sync"
+
+// DeployWindow tracks whether the measured app is being redeployed, so that
+// failures seen by other measurements in the meantime can be attributed to
+// the deploy.
+type DeployWindow struct {
+	mu         sync.Mutex
+	inProgress bool
+	failures   int
+}
+
+func NewDeployWindow() *DeployWindow {
+	return &DeployWindow{}
+}
+
+func (d *DeployWindow) Open() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.inProgress = true
+	d.failures = 0
+}
+
+// Close ends the deploy and returns the number of failures recorded while it
+// was in progress.
+func (d *DeployWindow) Close() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.inProgress = false
+	return d.failures
+}
+
+// RecordFailure counts a failure against the current deploy, and reports
+// whether a deploy was in progress.
+func (d *DeployWindow) RecordFailure() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.inProgress {
+		d.failures++
+	}
+	return d.inProgress
+}
@@ -0,0 +1,44 @@
+package measurement
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+type foundationMeasurement struct {
+	Measurement
+	foundation string
+}
+
+// ForFoundation labels a measurement with the foundation it measures, for
+// runs that measure several foundations.
+func ForFoundation(foundation string, m Measurement) Measurement {
+	return &foundationMeasurement{
+		Measurement: m,
+		foundation:  foundation,
+	}
+}
+
+func (f *foundationMeasurement) Name() string {
+	return fmt.Sprintf("[%s] %s", f.foundation, f.Measurement.Name())
+}
+
+func (f *foundationMeasurement) Summary() string {
+	return fmt.Sprintf("[%s] %s", f.foundation, f.Measurement.Summary())
+}
+
+func (f *foundationMeasurement) SummaryData() Summary {
+	summary := f.Measurement.SummaryData()
+	summary.Foundation = f.foundation
+
+	return summary
+}
+
+func (f *foundationMeasurement) Commands() []cmdStartWaiter.CmdStartWaiter {
+	if cm, ok := f.Measurement.(CommandMeasurement); ok {
+		return cm.Commands()
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package measurement_test
+
+import (
+	"os/exec"
+
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+	. "github.com/cloudfoundry/uptimer/measurement"
+	"github.com/cloudfoundry/uptimer/measurement/measurementfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ForFoundation", func() {
+	var (
+		fakeMeasurement *measurementfakes.FakeMeasurement
+
+		m Measurement
+	)
+
+	BeforeEach(func() {
+		fakeMeasurement = &measurementfakes.FakeMeasurement{}
+		fakeMeasurement.NameReturns("HTTP availability")
+		fakeMeasurement.SummaryReturns("SUCCESS (HTTP availability): ...")
+		fakeMeasurement.SummaryDataReturns(Summary{Name: "HTTP availability", Total: 3})
+		fakeMeasurement.FailedReturns(true)
+
+		m = ForFoundation("east", fakeMeasurement)
+	})
+
+	It("prefixes the name with the foundation", func() {
+		Expect(m.Name()).To(Equal("[east] HTTP availability"))
+	})
+
+	It("prefixes the summary with the foundation", func() {
+		Expect(m.Summary()).To(Equal("[east] SUCCESS (HTTP availability): ..."))
+	})
+
+	It("records the foundation in the summary data", func() {
+		Expect(m.SummaryData()).To(Equal(Summary{Name: "HTTP availability", Total: 3, Foundation: "east"}))
+	})
+
+	It("delegates everything else to the measurement", func() {
+		m.Start()
+		m.Stop()
+
+		Expect(fakeMeasurement.StartCallCount()).To(Equal(1))
+		Expect(fakeMeasurement.StopCallCount()).To(Equal(1))
+		Expect(m.Failed()).To(BeTrue())
+	})
+
+	It("returns the commands of a measurement that runs commands", func() {
+		commands := []cmdStartWaiter.CmdStartWaiter{exec.Command("cf", "app", "my-app")}
+		m = ForFoundation("east", &measurementWithCommands{fakeMeasurement, commands})
+
+		Expect(m.(CommandMeasurement).Commands()).To(Equal(commands))
+	})
+})
+
+type measurementWithCommands struct {
+	*measurementfakes.FakeMeasurement
+	commands []cmdStartWaiter.CmdStartWaiter
+}
+
+func (m *measurementWithCommands) Commands() []cmdStartWaiter.CmdStartWaiter {
+	return m.commands
+}
@@ -0,0 +1,198 @@
+package measurement
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/uptimer/appLogValidator"
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+type logCache struct {
+	name                           string
+	summaryPhrase                  string
+	logCacheUrl                    string
+	appGuidCommandGeneratorFunc    func() []cmdStartWaiter.CmdStartWaiter
+	oauthTokenCommandGeneratorFunc func() []cmdStartWaiter.CmdStartWaiter
+	runner                         cmdRunner.CmdRunner
+	runnerOutBuf                   *bytes.Buffer
+	runnerErrBuf                   *bytes.Buffer
+	client                         *http.Client
+	appLogValidator                appLogValidator.AppLogValidator
+
+	appGuid string
+	token   string
+}
+
+type logCacheReadResponse struct {
+	Envelopes struct {
+		Batch []logCacheEnvelope `json:"batch"`
+	} `json:"envelopes"`
+}
+
+type logCacheEnvelope struct {
+	Timestamp  string            `json:"timestamp"`
+	InstanceId string            `json:"instance_id"`
+	Tags       map[string]string `json:"tags"`
+	Log        *struct {
+		Payload []byte `json:"payload"`
+		Type    string `json:"type"`
+	} `json:"log"`
+}
+
+func (l *logCache) Name() string {
+	return l.name
+}
+
+func (l *logCache) SummaryPhrase() string {
+	return l.summaryPhrase
+}
+
+// Commands returns the commands that fetch the app guid and the token, which
+// an attempt runs only when they are missing.
+func (l *logCache) Commands() []cmdStartWaiter.CmdStartWaiter {
+	return append(l.appGuidCommandGeneratorFunc(), l.oauthTokenCommandGeneratorFunc()...)
+}
+
+func (l *logCache) PerformMeasurement() (string, string, string, bool) {
+	return l.PerformMeasurementWithContext(context.TODO())
+}
+
+func (l *logCache) PerformMeasurementWithContext(ctx context.Context) (string, string, string, bool) {
+	defer l.runnerOutBuf.Reset()
+	defer l.runnerErrBuf.Reset()
+
+	// The app guid and token are fetched with the cf CLI only when missing,
+	// so that most attempts depend on Log Cache alone.
+	if l.appGuid == "" {
+		guid, err := l.runForLastLine(ctx, l.appGuidCommandGeneratorFunc())
+		if err != nil {
+			return fmt.Sprintf("Failed to get app guid: %s", err.Error()), l.runnerOutBuf.String(), l.runnerErrBuf.String(), false
+		}
+		l.appGuid = guid
+	}
+
+	res, err := l.read(ctx)
+	if err == nil && res.StatusCode == http.StatusUnauthorized {
+		res.Body.Close() //nolint:errcheck
+		l.token = ""
+		res, err = l.read(ctx)
+	}
+	if err != nil {
+		return err.Error(), l.runnerOutBuf.String(), l.runnerErrBuf.String(), false
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err.Error(), "", "", false
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Sprintf("Log Cache responded with status %d; %s; %s", res.StatusCode, res.Status, string(body)), "", "", false
+	}
+
+	logs, err := formatLogCacheEnvelopes(body)
+	if err != nil {
+		return fmt.Sprintf("Failed to decode Log Cache response: %s", err.Error()), string(body), "", false
+	}
+
+	logIsNewer, err := l.appLogValidator.IsNewer(logs)
+	if err != nil {
+		return fmt.Sprintf("App log validation failed with: %s", err.Error()), logs, "", false
+	} else if !logIsNewer {
+		return "App log fetched was not newer than previous app log fetched", logs, "", false
+	}
+
+	return "", "", "", true
+}
+
+func (l *logCache) read(ctx context.Context) (*http.Response, error) {
+	if l.token == "" {
+		token, err := l.runForLastLine(ctx, l.oauthTokenCommandGeneratorFunc())
+		// The token command prints the token, which must never show up in the
+		// output of a failure.
+		l.runnerOutBuf.Reset()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get oauth token: %s", err.Error())
+		}
+		l.token = token
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/api/v1/read/%s?envelope_types=LOG&descending=true&limit=100", l.logCacheUrl, l.appGuid),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", l.token)
+
+	return l.client.Do(req)
+}
+
+func (l *logCache) runForLastLine(ctx context.Context, cmds []cmdStartWaiter.CmdStartWaiter) (string, error) {
+	l.runnerOutBuf.Reset()
+	if err := l.runner.RunInSequenceWithContext(ctx, cmds...); err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimSpace(l.runnerOutBuf.String()), "\n")
+	line := strings.TrimSpace(lines[len(lines)-1])
+	if line == "" {
+		return "", fmt.Errorf("no output")
+	}
+
+	return line, nil
+}
+
+// formatLogCacheEnvelopes renders log envelopes, oldest first, the way
+// `cf logs --recent` prints them.
+func formatLogCacheEnvelopes(body []byte) (string, error) {
+	res := &logCacheReadResponse{}
+	if err := json.Unmarshal(body, res); err != nil {
+		return "", err
+	}
+
+	envelopes := res.Envelopes.Batch
+	sort.SliceStable(envelopes, func(i, j int) bool {
+		ti, _ := strconv.ParseInt(envelopes[i].Timestamp, 10, 64)
+		tj, _ := strconv.ParseInt(envelopes[j].Timestamp, 10, 64)
+		return ti < tj
+	})
+
+	var lines []string
+	for _, e := range envelopes {
+		if e.Log == nil {
+			continue
+		}
+
+		ts, _ := strconv.ParseInt(e.Timestamp, 10, 64)
+		logType := e.Log.Type
+		if logType == "" {
+			logType = "OUT"
+		}
+
+		lines = append(lines, fmt.Sprintf(
+			"%s [%s/%s] %s %s",
+			time.Unix(0, ts).UTC().Format(time.RFC3339Nano),
+			e.Tags["source_type"],
+			e.InstanceId,
+			logType,
+			strings.TrimSpace(string(e.Log.Payload)),
+		))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,235 @@
+package measurement_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os/exec"
+
+	"github.com/cloudfoundry/uptimer/appLogValidator/appLogValidatorfakes"
+	"github.com/cloudfoundry/uptimer/cmdRunner/cmdRunnerfakes"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+	. "github.com/cloudfoundry/uptimer/measurement"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LogCache", func() {
+	var (
+		fakeAppLogValidator *appLogValidatorfakes.FakeAppLogValidator
+		fakeCommandRunner   *cmdRunnerfakes.FakeCmdRunner
+		fakeRoundTripper    *FakeRoundTripper
+		outBuf              *bytes.Buffer
+		errBuf              *bytes.Buffer
+		responses           []*http.Response
+
+		lcm BaseMeasurement
+	)
+
+	response := func(status int, body string) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Body:       io.NopCloser(bytes.NewBufferString(body)),
+		}
+	}
+
+	BeforeEach(func() {
+		outBuf = bytes.NewBuffer([]byte{})
+		errBuf = bytes.NewBuffer([]byte{})
+
+		fakeAppLogValidator = &appLogValidatorfakes.FakeAppLogValidator{}
+		fakeAppLogValidator.IsNewerReturns(true, nil)
+
+		fakeCommandRunner = &cmdRunnerfakes.FakeCmdRunner{}
+		fakeCommandRunner.RunInSequenceWithContextStub = func(_ context.Context, cmds ...cmdStartWaiter.CmdStartWaiter) error {
+			switch cmds[0].(*exec.Cmd).Args[0] {
+			case "guid":
+				outBuf.WriteString("Getting app info...\napp-guid\n")
+			case "token":
+				outBuf.WriteString("bearer some-token\n")
+			}
+			return nil
+		}
+
+		responses = []*http.Response{response(200, `{"envelopes": {"batch": [
+			{"timestamp": "2000000000", "instance_id": "1", "tags": {"source_type": "APP/PROC/WEB"}, "log": {"payload": "MTAwMQ==", "type": "OUT"}},
+			{"timestamp": "1000000000", "instance_id": "0", "tags": {"source_type": "APP/PROC/WEB"}, "log": {"payload": "MTAwMA=="}},
+			{"timestamp": "1500000000", "instance_id": "0", "tags": {"source_type": "RTR"}}
+		]}}`)}
+		fakeRoundTripper = &FakeRoundTripper{}
+		fakeRoundTripper.RoundTripStub = func(*http.Request) (*http.Response, error) {
+			res := responses[0]
+			if len(responses) > 1 {
+				responses = responses[1:]
+			}
+			return res, nil
+		}
+
+		lcm = NewLogCache(
+			"https://log-cache.example.com",
+			func() []cmdStartWaiter.CmdStartWaiter { return []cmdStartWaiter.CmdStartWaiter{exec.Command("guid")} },
+			func() []cmdStartWaiter.CmdStartWaiter { return []cmdStartWaiter.CmdStartWaiter{exec.Command("token")} },
+			fakeCommandRunner,
+			outBuf,
+			errBuf,
+			&http.Client{Transport: fakeRoundTripper},
+			fakeAppLogValidator,
+		)
+	})
+
+	Describe("Name", func() {
+		It("returns the name", func() {
+			Expect(lcm.Name()).To(Equal("Log Cache availability"))
+		})
+	})
+
+	Describe("SummaryPhrase", func() {
+		It("returns the summary phrase", func() {
+			Expect(lcm.SummaryPhrase()).To(Equal("read logs from the Log Cache API"))
+		})
+	})
+
+	Describe("PerformMeasurement", func() {
+		It("reads the app's logs from Log Cache with the oauth token", func() {
+			lcm.PerformMeasurement()
+
+			Expect(fakeRoundTripper.RoundTripCallCount()).To(Equal(1))
+			req := fakeRoundTripper.RoundTripArgsForCall(0)
+			Expect(req.URL.String()).To(Equal("https://log-cache.example.com/api/v1/read/app-guid?envelope_types=LOG&descending=true&limit=100"))
+			Expect(req.Header.Get("Authorization")).To(Equal("bearer some-token"))
+		})
+
+		It("validates the app logs oldest first, formatted like cf logs", func() {
+			lcm.PerformMeasurement()
+
+			Expect(fakeAppLogValidator.IsNewerCallCount()).To(Equal(1))
+			Expect(fakeAppLogValidator.IsNewerArgsForCall(0)).To(Equal(
+				"1970-01-01T00:00:01Z [APP/PROC/WEB/0] OUT 1000\n" +
+					"1970-01-01T00:00:02Z [APP/PROC/WEB/1] OUT 1001",
+			))
+		})
+
+		It("records fresh logs as success", func() {
+			_, _, _, res := lcm.PerformMeasurement()
+
+			Expect(res).To(BeTrue())
+		})
+
+		It("only fetches the app guid and token once", func() {
+			lcm.PerformMeasurement()
+			lcm.PerformMeasurement()
+
+			Expect(fakeCommandRunner.RunInSequenceWithContextCallCount()).To(Equal(2))
+			Expect(fakeRoundTripper.RoundTripCallCount()).To(Equal(2))
+		})
+
+		It("does not accumulate buffers indefinitely", func() {
+			lcm.PerformMeasurement()
+
+			Expect(outBuf.Len()).To(Equal(0))
+			Expect(errBuf.Len()).To(Equal(0))
+		})
+
+		Context("when the token has expired", func() {
+			BeforeEach(func() {
+				responses = append([]*http.Response{response(401, "")}, responses...)
+			})
+
+			It("fetches a new token and reads again", func() {
+				_, _, _, res := lcm.PerformMeasurement()
+
+				Expect(res).To(BeTrue())
+				Expect(fakeCommandRunner.RunInSequenceWithContextCallCount()).To(Equal(3))
+				Expect(fakeRoundTripper.RoundTripCallCount()).To(Equal(2))
+			})
+		})
+
+		Context("when Log Cache responds with an error", func() {
+			BeforeEach(func() {
+				responses = []*http.Response{response(503, "down for maintenance")}
+			})
+
+			It("records the measurement as having failed", func() {
+				msg, _, _, res := lcm.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("Log Cache responded with status 503; Service Unavailable; down for maintenance"))
+			})
+		})
+
+		Context("when Log Cache fails after the token was fetched", func() {
+			BeforeEach(func() {
+				fakeRoundTripper.RoundTripStub = nil
+				fakeRoundTripper.RoundTripReturns(nil, errors.New("no such host"))
+			})
+
+			It("does not return the token in the output", func() {
+				_, stdOut, stdErr, res := lcm.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(stdOut).NotTo(ContainSubstring("some-token"))
+				Expect(stdErr).NotTo(ContainSubstring("some-token"))
+			})
+		})
+
+		Context("when Log Cache cannot be reached", func() {
+			BeforeEach(func() {
+				fakeRoundTripper.RoundTripStub = nil
+				fakeRoundTripper.RoundTripReturns(nil, errors.New("no such host"))
+			})
+
+			It("records the measurement as having failed", func() {
+				msg, _, _, res := lcm.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(ContainSubstring("no such host"))
+			})
+		})
+
+		Context("when the app guid cannot be fetched", func() {
+			BeforeEach(func() {
+				fakeCommandRunner.RunInSequenceWithContextStub = nil
+				fakeCommandRunner.RunInSequenceWithContextReturns(errors.New("app not found"))
+			})
+
+			It("records the measurement as having failed without reading", func() {
+				msg, _, _, res := lcm.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("Failed to get app guid: app not found"))
+				Expect(fakeRoundTripper.RoundTripCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the app logs are not newer", func() {
+			BeforeEach(func() {
+				fakeAppLogValidator.IsNewerReturns(false, nil)
+			})
+
+			It("records the measurement as having failed", func() {
+				msg, stdOut, _, res := lcm.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("App log fetched was not newer than previous app log fetched"))
+				Expect(stdOut).To(ContainSubstring("[APP/PROC/WEB/1] OUT 1001"))
+			})
+		})
+
+		Context("when the app logs cannot be validated", func() {
+			BeforeEach(func() {
+				fakeAppLogValidator.IsNewerReturns(false, errors.New("cannot find any app logs"))
+			})
+
+			It("records the measurement as having failed", func() {
+				msg, _, _, res := lcm.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("App log validation failed with: cannot find any app logs"))
+			})
+		})
+	})
+})
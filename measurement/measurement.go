@@ -3,6 +3,7 @@ package measurement
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"time"
@@ -25,7 +26,13 @@ type Measurement interface {
 	SummaryData() Summary
 }
 
-type ShouldRetryFunc func(stdOut, stdErr string) bool
+//go:generate counterfeiter . TokenRefresher
+
+// TokenRefresher renews the authentication of the CF session a measurement
+// runs its commands in, before its access token expires.
+type TokenRefresher interface {
+	RefreshIfExpiring(ctx context.Context) error
+}
 
 func NewPeriodicWithoutMeasuringImmediately(
 	logger *log.Logger,
@@ -34,15 +41,17 @@ func NewPeriodicWithoutMeasuringImmediately(
 	baseMeasurement BaseMeasurement,
 	resultSet ResultSet,
 	allowedFailures int,
-	shouldRetryFunc ShouldRetryFunc,
+	tokenRefresher TokenRefresher,
+	attemptTimeout time.Duration,
 ) Measurement {
 	return &periodic{
 		logger:             logger,
 		clock:              clock,
 		freq:               freq,
 		baseMeasurement:    baseMeasurement,
-		shouldRetryFunc:    shouldRetryFunc,
+		tokenRefresher:     tokenRefresher,
 		allowedFailures:    allowedFailures,
+		attemptTimeout:     attemptTimeout,
 		measureImmediately: false,
 
 		stopChan:  make(chan int, 1),
@@ -57,15 +66,17 @@ func NewPeriodic(
 	baseMeasurement BaseMeasurement,
 	resultSet ResultSet,
 	allowedFailures int,
-	shouldRetryFunc ShouldRetryFunc,
+	tokenRefresher TokenRefresher,
+	attemptTimeout time.Duration,
 ) Measurement {
 	return &periodic{
 		logger:             logger,
 		clock:              clock,
 		freq:               freq,
 		baseMeasurement:    baseMeasurement,
-		shouldRetryFunc:    shouldRetryFunc,
+		tokenRefresher:     tokenRefresher,
 		allowedFailures:    allowedFailures,
+		attemptTimeout:     attemptTimeout,
 		measureImmediately: true,
 
 		stopChan:  make(chan int, 1),
@@ -80,12 +91,35 @@ type BaseMeasurement interface {
 	SummaryPhrase() string
 }
 
-func NewHTTPAvailability(url string, client *http.Client) BaseMeasurement {
+// DetailedMeasurement is implemented by base measurements that report
+// figures beyond their pass rate, such as log loss or delay.
+type DetailedMeasurement interface {
+	Details() map[string]float64
+}
+
+// CommandMeasurement is implemented by measurements that run cf commands. It
+// returns the commands of a single attempt without running them, so that a
+// dry run can show them.
+type CommandMeasurement interface {
+	Commands() []cmdStartWaiter.CmdStartWaiter
+}
+
+// ContextMeasurement is implemented by base measurements whose attempts can
+// be cancelled. An attempt is cancelled once it runs past the attempt
+// timeout, which kills the command it is running.
+type ContextMeasurement interface {
+	PerformMeasurementWithContext(ctx context.Context) (string, string, string, bool)
+}
+
+// NewHTTPAvailability measures requests to url. deployWindow is optional;
+// when set, failures during a rolling deploy of the app are marked as such.
+func NewHTTPAvailability(url string, client *http.Client, deployWindow *DeployWindow) BaseMeasurement {
 	return &availability{
 		name:          "HTTP availability",
 		summaryPhrase: "perform get requests",
 		url:           url,
 		client:        client,
+		deployWindow:  deployWindow,
 	}
 }
 
@@ -107,6 +141,30 @@ func NewRecentLogs(
 	}
 }
 
+func NewLogCache(
+	logCacheUrl string,
+	appGuidCommandGeneratorFunc func() []cmdStartWaiter.CmdStartWaiter,
+	oauthTokenCommandGeneratorFunc func() []cmdStartWaiter.CmdStartWaiter,
+	runner cmdRunner.CmdRunner,
+	runnerOutBuf *bytes.Buffer,
+	runnerErrBuf *bytes.Buffer,
+	client *http.Client,
+	appLogValidator appLogValidator.AppLogValidator,
+) BaseMeasurement {
+	return &logCache{
+		name:                           "Log Cache availability",
+		summaryPhrase:                  "read logs from the Log Cache API",
+		logCacheUrl:                    logCacheUrl,
+		appGuidCommandGeneratorFunc:    appGuidCommandGeneratorFunc,
+		oauthTokenCommandGeneratorFunc: oauthTokenCommandGeneratorFunc,
+		runner:                         runner,
+		runnerOutBuf:                   runnerOutBuf,
+		runnerErrBuf:                   runnerErrBuf,
+		client:                         client,
+		appLogValidator:                appLogValidator,
+	}
+}
+
 func NewTCPAvailability(url string, port int) BaseMeasurement {
 	return &tcpAvailability{
 		name:          "TCP availability",
@@ -115,21 +173,62 @@ func NewTCPAvailability(url string, port int) BaseMeasurement {
 		port:          port,
 	}
 }
+
+// NewSyslogDrain measures how many app logs reach the syslog sink at
+// sinkUrl. Logs sent before drainBoundAt are not expected to reach it.
 func NewSyslogDrain(
-	recentLogsCommandGeneratorFunc func() []cmdStartWaiter.CmdStartWaiter,
-	runner cmdRunner.CmdRunner,
-	runnerOutBuf *bytes.Buffer,
-	runnerErrBuf *bytes.Buffer,
-	appLogValidator appLogValidator.AppLogValidator,
+	sinkUrl string,
+	host string,
+	drainBoundAt time.Time,
+	instances int,
+	maxLossPercent float64,
+	client *http.Client,
+	clock clock.Clock,
 ) BaseMeasurement {
-	return &recentLogs{
-		name:                           "App syslog availability",
-		summaryPhrase:                  "check application syslogs",
-		recentLogsCommandGeneratorFunc: recentLogsCommandGeneratorFunc,
-		runner:                         runner,
-		runnerOutBuf:                   runnerOutBuf,
-		runnerErrBuf:                   runnerErrBuf,
-		appLogValidator:                appLogValidator,
+	return &syslogDrain{
+		name:           "App syslog availability",
+		summaryPhrase:  "check application syslogs",
+		sinkUrl:        sinkUrl,
+		host:           host,
+		boundAt:        drainBoundAt.Unix(),
+		instances:      instances,
+		window:         30 * time.Second,
+		maxLossPercent: maxLossPercent,
+		client:         client,
+		clock:          clock,
+	}
+}
+
+func NewAggregateSyslogDrain(
+	sinkUrl string,
+	host string,
+	drainBoundAt time.Time,
+	instances int,
+	maxLossPercent float64,
+	client *http.Client,
+	clock clock.Clock,
+) BaseMeasurement {
+	return &syslogDrain{
+		name:           "Aggregate syslog availability",
+		summaryPhrase:  "check aggregate syslogs",
+		sinkUrl:        sinkUrl,
+		host:           host,
+		boundAt:        drainBoundAt.Unix(),
+		instances:      instances,
+		window:         30 * time.Second,
+		maxLossPercent: maxLossPercent,
+		client:         client,
+		clock:          clock,
+	}
+}
+
+// NewSyslogSinkCounts checks that the syslog sink at sinkUrl serves its
+// counts the way NewSyslogDrain reads them.
+func NewSyslogSinkCounts(sinkUrl string, client *http.Client, clock clock.Clock) BaseMeasurement {
+	return &syslogSinkCounts{
+		sinkUrl: sinkUrl,
+		client:  client,
+		clock:   clock,
 	}
 }
 
@@ -167,11 +266,65 @@ func NewAppPushability(
 	}
 }
 
+// NewBuildpackPushability measures pushing with one entry of the pushability
+// matrix, identified by name.
+func NewBuildpackPushability(
+	name string,
+	pushAndDeleteAppCommandGeneratorFunc func() []cmdStartWaiter.CmdStartWaiter,
+	runner cmdRunner.CmdRunner,
+	runnerOutBuf *bytes.Buffer,
+	runnerErrBuf *bytes.Buffer,
+) BaseMeasurement {
+	return &pushability{
+		name:                                 fmt.Sprintf("App pushability (%s)", name),
+		summaryPhrase:                        fmt.Sprintf("push and delete an app with %s", name),
+		pushAndDeleteAppCommandGeneratorFunc: pushAndDeleteAppCommandGeneratorFunc,
+		runner:                               runner,
+		runnerOutBuf:                         runnerOutBuf,
+		runnerErrBuf:                         runnerErrBuf,
+	}
+}
+
+func NewDockerPushability(
+	pushAndDeleteAppCommandGeneratorFunc func() []cmdStartWaiter.CmdStartWaiter,
+	runner cmdRunner.CmdRunner,
+	runnerOutBuf *bytes.Buffer,
+	runnerErrBuf *bytes.Buffer,
+) BaseMeasurement {
+	return &pushability{
+		name:                                 "Docker image pushability",
+		summaryPhrase:                        "push and delete a docker image app",
+		pushAndDeleteAppCommandGeneratorFunc: pushAndDeleteAppCommandGeneratorFunc,
+		runner:                               runner,
+		runnerOutBuf:                         runnerOutBuf,
+		runnerErrBuf:                         runnerErrBuf,
+	}
+}
+
+func NewRollingDeploy(
+	rollingDeployCommandGeneratorFunc func() []cmdStartWaiter.CmdStartWaiter,
+	runner cmdRunner.CmdRunner,
+	runnerOutBuf *bytes.Buffer,
+	runnerErrBuf *bytes.Buffer,
+	deployWindow *DeployWindow,
+) BaseMeasurement {
+	return &rollingDeploy{
+		name:                              "Rolling deploy",
+		summaryPhrase:                     "deploy the app with a rolling strategy",
+		rollingDeployCommandGeneratorFunc: rollingDeployCommandGeneratorFunc,
+		runner:                            runner,
+		runnerOutBuf:                      runnerOutBuf,
+		runnerErrBuf:                      runnerErrBuf,
+		deployWindow:                      deployWindow,
+	}
+}
+
 func NewStatsAvailability(
 	statsAvailabilityCommandGeneratorFunc func() []cmdStartWaiter.CmdStartWaiter,
 	runner cmdRunner.CmdRunner,
 	runnerOutBuf *bytes.Buffer,
 	runnerErrBuf *bytes.Buffer,
+	instances int,
 ) BaseMeasurement {
 	return &statsAvailability{
 		name:                                  "Stats availability",
@@ -180,5 +333,6 @@ func NewStatsAvailability(
 		runner:                                runner,
 		runnerOutBuf:                          runnerOutBuf,
 		runnerErrBuf:                          runnerErrBuf,
+		instances:                             instances,
 	}
 }
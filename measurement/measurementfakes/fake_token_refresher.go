@@ -0,0 +1,112 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package measurementfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudfoundry/uptimer/measurement"
+)
+
+type FakeTokenRefresher struct {
+	RefreshIfExpiringStub        func(context.Context) error
+	refreshIfExpiringMutex       sync.RWMutex
+	refreshIfExpiringArgsForCall []struct {
+		arg1 context.Context
+	}
+	refreshIfExpiringReturns struct {
+		result1 error
+	}
+	refreshIfExpiringReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeTokenRefresher) RefreshIfExpiring(arg1 context.Context) error {
+	fake.refreshIfExpiringMutex.Lock()
+	ret, specificReturn := fake.refreshIfExpiringReturnsOnCall[len(fake.refreshIfExpiringArgsForCall)]
+	fake.refreshIfExpiringArgsForCall = append(fake.refreshIfExpiringArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.RefreshIfExpiringStub
+	fakeReturns := fake.refreshIfExpiringReturns
+	fake.recordInvocation("RefreshIfExpiring", []interface{}{arg1})
+	fake.refreshIfExpiringMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTokenRefresher) RefreshIfExpiringCallCount() int {
+	fake.refreshIfExpiringMutex.RLock()
+	defer fake.refreshIfExpiringMutex.RUnlock()
+	return len(fake.refreshIfExpiringArgsForCall)
+}
+
+func (fake *FakeTokenRefresher) RefreshIfExpiringCalls(stub func(context.Context) error) {
+	fake.refreshIfExpiringMutex.Lock()
+	defer fake.refreshIfExpiringMutex.Unlock()
+	fake.RefreshIfExpiringStub = stub
+}
+
+func (fake *FakeTokenRefresher) RefreshIfExpiringArgsForCall(i int) context.Context {
+	fake.refreshIfExpiringMutex.RLock()
+	defer fake.refreshIfExpiringMutex.RUnlock()
+	argsForCall := fake.refreshIfExpiringArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeTokenRefresher) RefreshIfExpiringReturns(result1 error) {
+	fake.refreshIfExpiringMutex.Lock()
+	defer fake.refreshIfExpiringMutex.Unlock()
+	fake.RefreshIfExpiringStub = nil
+	fake.refreshIfExpiringReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTokenRefresher) RefreshIfExpiringReturnsOnCall(i int, result1 error) {
+	fake.refreshIfExpiringMutex.Lock()
+	defer fake.refreshIfExpiringMutex.Unlock()
+	fake.RefreshIfExpiringStub = nil
+	if fake.refreshIfExpiringReturnsOnCall == nil {
+		fake.refreshIfExpiringReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.refreshIfExpiringReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTokenRefresher) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.refreshIfExpiringMutex.RLock()
+	defer fake.refreshIfExpiringMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeTokenRefresher) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ measurement.TokenRefresher = new(FakeTokenRefresher)
@@ -1,11 +1,16 @@
 package measurement
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/benbjohnson/clock"
+
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
 )
 
 type periodic struct {
@@ -13,10 +18,15 @@ type periodic struct {
 	clock              clock.Clock
 	freq               time.Duration
 	baseMeasurement    BaseMeasurement
-	shouldRetryFunc    ShouldRetryFunc
+	tokenRefresher     TokenRefresher
 	allowedFailures    int
+	attemptTimeout     time.Duration
 	measureImmediately bool
 
+	// abandoned yields the outcome of the attempt that last timed out, once
+	// it has finished.
+	abandoned <-chan outcome
+
 	resultSet ResultSet
 	stopChan  chan int
 }
@@ -27,6 +37,9 @@ type Summary struct {
 	SummaryPhrase   string `json:"summaryPhrase"`
 	AllowedFailures int    `json:"allowedFailures"`
 	Total           int    `json:"total"`
+
+	Details    map[string]float64 `json:"details,omitempty"`
+	Foundation string             `json:"foundation,omitempty"`
 }
 
 func (p *periodic) Name() string {
@@ -52,8 +65,56 @@ func (p *periodic) Start() {
 	}()
 }
 
+type outcome struct {
+	msg, stdOut, stdErr string
+	ok                  bool
+}
+
 func (p *periodic) performMeasurement() {
-	if msg, stdOut, stdErr, ok := p.performWithSingleRetry(); !ok {
+	// Attempts never overlap, since they share their runner's buffers. An
+	// attempt that timed out was cancelled, so it is usually done by now; one
+	// that ignores its cancellation fails each tick until it returns, rather
+	// than stalling the measurement.
+	if p.abandoned != nil {
+		select {
+		case <-p.abandoned:
+			p.abandoned = nil
+		default:
+			p.record("the attempt that timed out is still running", "", "", false)
+			return
+		}
+	}
+
+	if p.attemptTimeout <= 0 {
+		p.record(p.performWithFreshToken(context.TODO()))
+		return
+	}
+
+	// The attempt is cancelled when p's clock reaches its deadline, rather
+	// than the wall clock.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan outcome, 1)
+	go func() {
+		msg, stdOut, stdErr, ok := p.performWithFreshToken(ctx)
+		done <- outcome{msg, stdOut, stdErr, ok}
+	}()
+
+	timer := p.clock.Timer(p.attemptTimeout)
+	defer timer.Stop()
+
+	select {
+	case o := <-done:
+		p.record(o.msg, o.stdOut, o.stdErr, o.ok)
+	case <-timer.C:
+		p.record(fmt.Sprintf("timed out after %gs", p.attemptTimeout.Seconds()), "", "", false)
+		p.abandoned = done
+	}
+}
+
+func (p *periodic) record(msg, stdOut, stdErr string, ok bool) {
+	if !ok {
 		p.resultSet.RecordFailure()
 		p.logFailure(msg, stdOut, stdErr)
 		return
@@ -62,13 +123,24 @@ func (p *periodic) performMeasurement() {
 	p.resultSet.RecordSuccess()
 }
 
-func (p *periodic) performWithSingleRetry() (string, string, string, bool) {
-	msg, stdOut, stdErr, ok := p.baseMeasurement.PerformMeasurement()
-	if !ok && p.shouldRetryFunc(stdOut, stdErr) {
-		return p.baseMeasurement.PerformMeasurement()
+// performWithFreshToken refreshes the access token of the measurement's CF
+// session ahead of its expiry before performing the measurement.
+func (p *periodic) performWithFreshToken(ctx context.Context) (string, string, string, bool) {
+	if p.tokenRefresher != nil {
+		if err := p.tokenRefresher.RefreshIfExpiring(ctx); err != nil {
+			return fmt.Sprintf("failed to refresh token: %s", err), "", "", false
+		}
 	}
 
-	return msg, stdOut, stdErr, ok
+	return p.perform(ctx)
+}
+
+func (p *periodic) perform(ctx context.Context) (string, string, string, bool) {
+	if cm, ok := p.baseMeasurement.(ContextMeasurement); ok {
+		return cm.PerformMeasurementWithContext(ctx)
+	}
+
+	return p.baseMeasurement.PerformMeasurement()
 }
 
 func (p *periodic) logFailure(msg, stdOut, stdErr string) {
@@ -119,7 +191,7 @@ func (p *periodic) Summary() string {
 		msg = "FAILED (%s): %d failed attempts to %s exceeded the threshold of %d allowed failures (Total attempts: %d, pass rate %.2f%%)"
 	}
 
-	return fmt.Sprintf(
+	summary := fmt.Sprintf(
 		msg,
 		p.baseMeasurement.Name(),
 		p.resultSet.Failed(),
@@ -128,14 +200,47 @@ func (p *periodic) Summary() string {
 		p.resultSet.Total(),
 		float32(100*p.resultSet.Successful())/float32(p.resultSet.Total()),
 	)
+
+	if details := p.details(); len(details) > 0 {
+		names := make([]string, 0, len(details))
+		for name := range details {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		formatted := make([]string, 0, len(names))
+		for _, name := range names {
+			formatted = append(formatted, fmt.Sprintf("%s: %.2f", name, details[name]))
+		}
+		summary = fmt.Sprintf("%s [%s]", summary, strings.Join(formatted, ", "))
+	}
+
+	return summary
 }
 
 func (p *periodic) SummaryData() Summary {
 	return Summary{
-		p.baseMeasurement.Name(),
-		p.resultSet.Failed(),
-		p.baseMeasurement.SummaryPhrase(),
-		p.allowedFailures,
-		p.resultSet.Total(),
+		Name:            p.baseMeasurement.Name(),
+		Failed:          p.resultSet.Failed(),
+		SummaryPhrase:   p.baseMeasurement.SummaryPhrase(),
+		AllowedFailures: p.allowedFailures,
+		Total:           p.resultSet.Total(),
+		Details:         p.details(),
+	}
+}
+
+func (p *periodic) details() map[string]float64 {
+	if dm, ok := p.baseMeasurement.(DetailedMeasurement); ok {
+		return dm.Details()
 	}
+
+	return nil
+}
+
+func (p *periodic) Commands() []cmdStartWaiter.CmdStartWaiter {
+	if cm, ok := p.baseMeasurement.(CommandMeasurement); ok {
+		return cm.Commands()
+	}
+
+	return nil
 }
@@ -1,8 +1,10 @@
 package measurement_test
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os/exec"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -10,6 +12,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
 	"github.com/cloudfoundry/uptimer/measurement"
 	"github.com/cloudfoundry/uptimer/measurement/measurementfakes"
 )
@@ -23,7 +26,7 @@ var _ = Describe("Periodic", func() {
 		fakeBaseMeasurement *measurementfakes.FakeBaseMeasurement
 		fakeResultSet       *measurementfakes.FakeResultSet
 		allowedFailures     int
-		shouldRetry         bool
+		fakeTokenRefresher  *measurementfakes.FakeTokenRefresher
 
 		p measurement.Measurement
 	)
@@ -38,7 +41,7 @@ var _ = Describe("Periodic", func() {
 		fakeBaseMeasurement.SummaryPhraseReturns("wingdang the foobrizzle")
 		fakeResultSet = &measurementfakes.FakeResultSet{}
 		allowedFailures = 0
-		shouldRetry = false
+		fakeTokenRefresher = &measurementfakes.FakeTokenRefresher{}
 
 		p = measurement.NewPeriodic(
 			logger,
@@ -47,7 +50,8 @@ var _ = Describe("Periodic", func() {
 			fakeBaseMeasurement,
 			fakeResultSet,
 			allowedFailures,
-			func(string, string) bool { return shouldRetry },
+			fakeTokenRefresher,
+			0,
 		)
 	})
 
@@ -86,7 +90,8 @@ var _ = Describe("Periodic", func() {
 					fakeBaseMeasurement,
 					fakeResultSet,
 					allowedFailures,
-					func(string, string) bool { return shouldRetry },
+					fakeTokenRefresher,
+					0,
 				)
 			})
 
@@ -105,7 +110,7 @@ var _ = Describe("Periodic", func() {
 			})
 		})
 
-		Context("when shouldRetry is false", func() {
+		Context("when the authentication has not expired", func() {
 			BeforeEach(func() {
 				fakeResultSet.FailedReturns(2)
 				allowedFailures = 4
@@ -117,7 +122,8 @@ var _ = Describe("Periodic", func() {
 					fakeBaseMeasurement,
 					fakeResultSet,
 					allowedFailures,
-					func(string, string) bool { return shouldRetry },
+					fakeTokenRefresher,
+					0,
 				)
 			})
 
@@ -199,107 +205,194 @@ var _ = Describe("Periodic", func() {
 			})
 		})
 
-		Context("when should retry is true", func() {
-			BeforeEach(func() {
-				shouldRetry = true
-				fakeResultSet.FailedReturns(1)
-				allowedFailures = 2
+		Context("with a token refresher", func() {
+			It("refreshes an expiring token before each attempt", func() {
+				refresher, base := fakeTokenRefresher, fakeBaseMeasurement
+				base.PerformMeasurementStub = func() (string, string, string, bool) {
+					Expect(refresher.RefreshIfExpiringCallCount()).To(Equal(base.PerformMeasurementCallCount()))
+					return "", "", "", true
+				}
 
-				p = measurement.NewPeriodic(
-					logger,
-					mockClock,
-					freq,
-					fakeBaseMeasurement,
-					fakeResultSet,
-					allowedFailures,
-					func(string, string) bool { return shouldRetry },
-				)
+				p.Start()
+				mockClock.Add(freq)
+
+				Expect(fakeBaseMeasurement.PerformMeasurementCallCount()).To(Equal(2))
+				Expect(fakeTokenRefresher.RefreshIfExpiringCallCount()).To(Equal(2))
 			})
 
-			It("runs the base measurement only once if perform measurement returns ok the first time", func() {
-				fakeBaseMeasurement.PerformMeasurementReturns("", "", "", true)
+			It("does not measure again after a failure", func() {
+				fakeBaseMeasurement.PerformMeasurementReturns("measurement failed!", "", "Authentication has expired.", false)
 
 				p.Start()
 				mockClock.Add(freq - time.Nanosecond)
 
 				Expect(fakeBaseMeasurement.PerformMeasurementCallCount()).To(Equal(1))
+				Expect(fakeTokenRefresher.RefreshIfExpiringCallCount()).To(Equal(1))
 			})
 
-			It("runs the base measurement twice if perform measurement returns not ok and then ok", func() {
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(0, "", "", "", false)
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(1, "", "", "", true)
+			Context("when the token cannot be refreshed", func() {
+				BeforeEach(func() {
+					fakeTokenRefresher.RefreshIfExpiringReturns(fmt.Errorf("uaa is down"))
+				})
 
-				p.Start()
-				mockClock.Add(freq - time.Nanosecond)
+				It("records a failure without measuring", func() {
+					p.Start()
+					mockClock.Add(freq - time.Nanosecond)
 
-				Expect(fakeBaseMeasurement.PerformMeasurementCallCount()).To(Equal(2))
+					Expect(fakeBaseMeasurement.PerformMeasurementCallCount()).To(Equal(0))
+					Expect(fakeResultSet.RecordFailureCallCount()).To(Equal(1))
+					Expect(logBuf.string()).To(ContainSubstring("failed to refresh token: uaa is down"))
+				})
 			})
+		})
 
-			It("records success if perform measurement returns not ok and then ok", func() {
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(0, "", "", "", false)
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(1, "", "", "", true)
+		Context("without a token refresher", func() {
+			BeforeEach(func() {
+				p = measurement.NewPeriodic(
+					logger,
+					mockClock,
+					freq,
+					fakeBaseMeasurement,
+					fakeResultSet,
+					allowedFailures,
+					nil,
+					0,
+				)
+			})
+
+			It("measures once per tick", func() {
+				fakeBaseMeasurement.PerformMeasurementReturns("", "", "", false)
 
 				p.Start()
 				mockClock.Add(freq - time.Nanosecond)
 
-				Expect(fakeResultSet.RecordSuccessCallCount()).To(Equal(1))
-				Expect(fakeResultSet.RecordFailureCallCount()).To(Equal(0))
+				Expect(fakeBaseMeasurement.PerformMeasurementCallCount()).To(Equal(1))
 			})
+		})
+	})
 
-			It("does not log if perform measurement returns not ok and then ok", func() {
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(0, "", "", "", false)
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(1, "", "", "", true)
+	Describe("with an attempt timeout", func() {
+		var release chan struct{}
 
-				p.Start()
-				mockClock.Add(freq - time.Nanosecond)
+		BeforeEach(func() {
+			release = make(chan struct{})
+			released := release
+			fakeBaseMeasurement.PerformMeasurementStub = func() (string, string, string, bool) {
+				<-released
+				return "", "", "", true
+			}
+
+			p = measurement.NewPeriodic(
+				logger,
+				mockClock,
+				freq,
+				fakeBaseMeasurement,
+				fakeResultSet,
+				allowedFailures,
+				nil,
+				500*time.Millisecond,
+			)
+		})
 
-				Expect(logBuf.string()).To(BeEmpty())
-			})
+		AfterEach(func() {
+			p.Stop()
+		})
 
-			It("records failure if perform measurement returns not ok twice", func() {
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(0, "", "", "", false)
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(1, "", "", "", false)
+		It("records an attempt that takes too long as failed", func() {
+			defer close(release)
+			p.Start()
 
-				p.Start()
-				mockClock.Add(freq - time.Nanosecond)
+			Eventually(func() int {
+				mockClock.Add(100 * time.Millisecond)
+				return fakeResultSet.RecordFailureCallCount()
+			}).Should(Equal(1))
+			Expect(logBuf.string()).To(ContainSubstring("FAILURE (foo measurement, 0/0): timed out after 0.5s"))
+		})
 
-				Expect(fakeResultSet.RecordSuccessCallCount()).To(Equal(0))
-				Expect(fakeResultSet.RecordFailureCallCount()).To(Equal(1))
-			})
+		It("fails each tick without starting another attempt while one that never returns is still running", func() {
+			p.Start()
+			Eventually(func() int {
+				mockClock.Add(100 * time.Millisecond)
+				return fakeResultSet.RecordFailureCallCount()
+			}).Should(Equal(1))
+
+			for attempt := 2; attempt <= 3; attempt++ {
+				mockClock.Add(freq)
+				Eventually(fakeResultSet.RecordFailureCallCount).Should(Equal(attempt))
+			}
+			Expect(fakeBaseMeasurement.PerformMeasurementCallCount()).To(Equal(1))
+			Expect(logBuf.string()).To(ContainSubstring("FAILURE (foo measurement, 0/0): the attempt that timed out is still running"))
+
+			close(release)
+			Eventually(func() int {
+				mockClock.Add(freq)
+				return fakeBaseMeasurement.PerformMeasurementCallCount()
+			}).Should(Equal(2))
+		})
 
-			It("logs failure if perform measurement returns not ok twice", func() {
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(0, "", "", "", false)
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(1, "", "", "", false)
+		Context("when the base measurement can be cancelled", func() {
+			var cancelled chan struct{}
 
-				p.Start()
-				mockClock.Add(freq - time.Nanosecond)
+			BeforeEach(func() {
+				cancelled = make(chan struct{}, 10)
+				hung := cancelled
+				base := &contextMeasurement{
+					FakeBaseMeasurement: fakeBaseMeasurement,
+					perform: func(ctx context.Context) (string, string, string, bool) {
+						<-ctx.Done()
+						hung <- struct{}{}
+						return "", "", "", true
+					},
+				}
 
-				Expect(logBuf.string()).To(ContainSubstring("FAILURE (foo measurement, 1/2)"))
+				p = measurement.NewPeriodic(
+					logger,
+					mockClock,
+					freq,
+					base,
+					fakeResultSet,
+					allowedFailures,
+					nil,
+					500*time.Millisecond,
+				)
 			})
 
-			It("logs how many successes since the last failure", func() {
-				lastFailure := mockClock.Now().UTC()
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(0, "", "", "", false)
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(1, "measurement failed!", "", "", false)
-				fakeResultSet.SuccessesSinceLastFailureReturns(3, lastFailure)
-
+			It("cancels an attempt that takes too long", func() {
 				p.Start()
-				mockClock.Add(freq - time.Nanosecond)
 
-				Expect(logBuf.string()).To(Equal(fmt.Sprintf("\x1b[31mFAILURE (foo measurement, 1/2): measurement failed! (3 successes since last failure at %s)\x1b[0m\n\n", lastFailure.Format("2006/01/02 15:04:05"))))
+				Eventually(func() int {
+					mockClock.Add(100 * time.Millisecond)
+					return fakeResultSet.RecordFailureCallCount()
+				}).Should(Equal(1))
+				Eventually(cancelled).Should(Receive())
+				Expect(fakeResultSet.RecordSuccessCallCount()).To(BeZero())
 			})
 
-			It("does not logs how many successes since the last failure if there have been none", func() {
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(0, "", "", "", false)
-				fakeBaseMeasurement.PerformMeasurementReturnsOnCall(1, "measurement failed!", "", "", false)
-				fakeResultSet.SuccessesSinceLastFailureReturns(0, time.Time{})
-
+			It("does not delay the next tick with an attempt that hangs", func() {
 				p.Start()
-				mockClock.Add(freq - time.Nanosecond)
-
-				Expect(logBuf.string()).To(Equal("\x1b[31mFAILURE (foo measurement, 1/2): measurement failed!\x1b[0m\n\n"))
+				Eventually(func() int {
+					mockClock.Add(100 * time.Millisecond)
+					return fakeResultSet.RecordFailureCallCount()
+				}).Should(Equal(1))
+
+				for attempt := 2; attempt <= 3; attempt++ {
+					mockClock.Add(freq)
+					Eventually(func() int {
+						mockClock.Add(100 * time.Millisecond)
+						return fakeResultSet.RecordFailureCallCount()
+					}).Should(Equal(attempt))
+				}
+				Expect(fakeBaseMeasurement.PerformMeasurementCallCount()).To(BeZero())
 			})
 		})
+
+		It("records attempts that finish in time as usual", func() {
+			close(release)
+			p.Start()
+
+			Eventually(fakeResultSet.RecordSuccessCallCount).Should(Equal(1))
+			Expect(fakeResultSet.RecordFailureCallCount()).To(BeZero())
+		})
 	})
 
 	Describe("Stop", func() {
@@ -321,7 +414,7 @@ var _ = Describe("Periodic", func() {
 
 	Describe("Failed", func() {
 		BeforeEach(func() {
-			p = measurement.NewPeriodic(logger, mockClock, freq, fakeBaseMeasurement, fakeResultSet, 5, func(string, string) bool { return shouldRetry })
+			p = measurement.NewPeriodic(logger, mockClock, freq, fakeBaseMeasurement, fakeResultSet, 5, fakeTokenRefresher, 0)
 		})
 
 		It("Returns true if failure count > allowed number of failures", func() {
@@ -349,7 +442,7 @@ var _ = Describe("Periodic", func() {
 			succeeded := 3
 			allowedFailures := 3
 
-			p = measurement.NewPeriodic(logger, mockClock, freq, fakeBaseMeasurement, fakeResultSet, allowedFailures, func(string, string) bool { return shouldRetry })
+			p = measurement.NewPeriodic(logger, mockClock, freq, fakeBaseMeasurement, fakeResultSet, allowedFailures, fakeTokenRefresher, 0)
 			fakeResultSet.FailedReturns(failed)
 			fakeResultSet.SuccessfulReturns(succeeded)
 			fakeResultSet.TotalReturns(failed + succeeded)
@@ -369,7 +462,7 @@ var _ = Describe("Periodic", func() {
 			succeeded := 4
 			allowedFailures := 2
 
-			p = measurement.NewPeriodic(logger, mockClock, freq, fakeBaseMeasurement, fakeResultSet, allowedFailures, func(string, string) bool { return shouldRetry })
+			p = measurement.NewPeriodic(logger, mockClock, freq, fakeBaseMeasurement, fakeResultSet, allowedFailures, fakeTokenRefresher, 0)
 			fakeResultSet.FailedReturns(failed)
 			fakeResultSet.SuccessfulReturns(succeeded)
 			fakeResultSet.TotalReturns(failed + succeeded)
@@ -389,7 +482,7 @@ var _ = Describe("Periodic", func() {
 			succeeded := 1
 			allowedFailures := 2
 
-			p = measurement.NewPeriodic(logger, mockClock, freq, fakeBaseMeasurement, fakeResultSet, allowedFailures, func(string, string) bool { return shouldRetry })
+			p = measurement.NewPeriodic(logger, mockClock, freq, fakeBaseMeasurement, fakeResultSet, allowedFailures, fakeTokenRefresher, 0)
 			fakeResultSet.FailedReturns(failed)
 			fakeResultSet.SuccessfulReturns(succeeded)
 			fakeResultSet.TotalReturns(failed + succeeded)
@@ -405,13 +498,59 @@ var _ = Describe("Periodic", func() {
 		})
 	})
 
+	Context("when the base measurement reports details", func() {
+		var detailedBaseMeasurement *detailedMeasurement
+
+		BeforeEach(func() {
+			detailedBaseMeasurement = &detailedMeasurement{
+				FakeBaseMeasurement: fakeBaseMeasurement,
+				details: map[string]float64{
+					"loss_percent":      1.5,
+					"avg_delay_seconds": 0.25,
+				},
+			}
+			fakeResultSet.FailedReturns(0)
+			fakeResultSet.SuccessfulReturns(2)
+			fakeResultSet.TotalReturns(2)
+
+			p = measurement.NewPeriodic(logger, mockClock, freq, detailedBaseMeasurement, fakeResultSet, 0, fakeTokenRefresher, 0)
+		})
+
+		It("appends the details to the summary", func() {
+			Expect(p.Summary()).To(Equal(
+				"SUCCESS (foo measurement): 0 failed attempts to wingdang the foobrizzle did not exceed the threshold of 0 allowed failures (Total attempts: 2, pass rate 100.00%) [avg_delay_seconds: 0.25, loss_percent: 1.50]",
+			))
+		})
+
+		It("includes the details in the summary data", func() {
+			Expect(p.SummaryData().Details).To(Equal(map[string]float64{
+				"loss_percent":      1.5,
+				"avg_delay_seconds": 0.25,
+			}))
+		})
+	})
+
+	Describe("Commands", func() {
+		It("returns no commands when the base measurement runs none", func() {
+			Expect(p.(measurement.CommandMeasurement).Commands()).To(BeEmpty())
+		})
+
+		It("returns the commands of the base measurement", func() {
+			commands := []cmdStartWaiter.CmdStartWaiter{exec.Command("cf", "app", "my-app")}
+			p = measurement.NewPeriodic(logger, mockClock, freq, &commandMeasurement{fakeBaseMeasurement, commands}, fakeResultSet, 0, fakeTokenRefresher, 0)
+
+			Expect(p.(measurement.CommandMeasurement).Commands()).To(Equal(commands))
+			Expect(fakeBaseMeasurement.PerformMeasurementCallCount()).To(Equal(0))
+		})
+	})
+
 	Describe("JsonSummary", func() {
 		It("returns a json summary", func() {
 			failed := 2
 			succeeded := 3
 			allowedFailures := 3
 
-			p = measurement.NewPeriodic(logger, mockClock, freq, fakeBaseMeasurement, fakeResultSet, allowedFailures, func(string, string) bool { return shouldRetry })
+			p = measurement.NewPeriodic(logger, mockClock, freq, fakeBaseMeasurement, fakeResultSet, allowedFailures, fakeTokenRefresher, 0)
 			fakeResultSet.FailedReturns(failed)
 			fakeResultSet.SuccessfulReturns(succeeded)
 			fakeResultSet.TotalReturns(failed + succeeded)
@@ -426,3 +565,30 @@ var _ = Describe("Periodic", func() {
 		})
 	})
 })
+
+type detailedMeasurement struct {
+	*measurementfakes.FakeBaseMeasurement
+	details map[string]float64
+}
+
+func (d *detailedMeasurement) Details() map[string]float64 {
+	return d.details
+}
+
+type commandMeasurement struct {
+	*measurementfakes.FakeBaseMeasurement
+	commands []cmdStartWaiter.CmdStartWaiter
+}
+
+func (c *commandMeasurement) Commands() []cmdStartWaiter.CmdStartWaiter {
+	return c.commands
+}
+
+type contextMeasurement struct {
+	*measurementfakes.FakeBaseMeasurement
+	perform func(ctx context.Context) (string, string, string, bool)
+}
+
+func (c *contextMeasurement) PerformMeasurementWithContext(ctx context.Context) (string, string, string, bool) {
+	return c.perform(ctx)
+}
@@ -2,6 +2,7 @@ package measurement
 
 import (
 	"bytes"
+	"context"
 
 	"github.com/cloudfoundry/uptimer/cmdRunner"
 	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
@@ -24,11 +25,19 @@ func (p *pushability) SummaryPhrase() string {
 	return p.summaryPhrase
 }
 
+func (p *pushability) Commands() []cmdStartWaiter.CmdStartWaiter {
+	return p.pushAndDeleteAppCommandGeneratorFunc()
+}
+
 func (p *pushability) PerformMeasurement() (string, string, string, bool) {
+	return p.PerformMeasurementWithContext(context.TODO())
+}
+
+func (p *pushability) PerformMeasurementWithContext(ctx context.Context) (string, string, string, bool) {
 	defer p.runnerOutBuf.Reset()
 	defer p.runnerErrBuf.Reset()
 
-	if err := p.runner.RunInSequence(p.pushAndDeleteAppCommandGeneratorFunc()...); err != nil {
+	if err := p.runner.RunInSequenceWithContext(ctx, p.pushAndDeleteAppCommandGeneratorFunc()...); err != nil {
 		return err.Error(), p.runnerOutBuf.String(), p.runnerErrBuf.String(), false
 	}
 
@@ -41,6 +41,18 @@ var _ = Describe("Pushability", func() {
 		})
 	})
 
+	Describe("Commands", func() {
+		It("returns the generated app push and delete without running them", func() {
+			commands = []cmdStartWaiter.CmdStartWaiter{
+				exec.Command("foo"),
+				exec.Command("bar"),
+			}
+
+			Expect(pm.(CommandMeasurement).Commands()).To(Equal(commands))
+			Expect(fakeCommandRunner.RunInSequenceWithContextCallCount()).To(Equal(0))
+		})
+	})
+
 	Describe("PerformMeasurement", func() {
 		It("runs the generated app push and delete", func() {
 			commands = []cmdStartWaiter.CmdStartWaiter{
@@ -50,8 +62,9 @@ var _ = Describe("Pushability", func() {
 
 			pm.PerformMeasurement()
 
-			Expect(fakeCommandRunner.RunInSequenceCallCount()).To(Equal(1))
-			Expect(fakeCommandRunner.RunInSequenceArgsForCall(0)).To(Equal(
+			Expect(fakeCommandRunner.RunInSequenceWithContextCallCount()).To(Equal(1))
+			_, cmds := fakeCommandRunner.RunInSequenceWithContextArgsForCall(0)
+			Expect(cmds).To(Equal(
 				[]cmdStartWaiter.CmdStartWaiter{
 					exec.Command("foo"),
 					exec.Command("bar"),
@@ -66,7 +79,7 @@ var _ = Describe("Pushability", func() {
 		})
 
 		It("records the commands that run with error as failed", func() {
-			fakeCommandRunner.RunInSequenceReturns(fmt.Errorf("errrrrrooooorrrr"))
+			fakeCommandRunner.RunInSequenceWithContextReturns(fmt.Errorf("errrrrrooooorrrr"))
 
 			_, _, _, res := pm.PerformMeasurement()
 
@@ -76,7 +89,7 @@ var _ = Describe("Pushability", func() {
 		It("returns both stdout and stderr when there is an error", func() {
 			outBuf.WriteString("heyyy guys")
 			errBuf.WriteString("whaaats happening?")
-			fakeCommandRunner.RunInSequenceReturns(fmt.Errorf("errrrrrooooorrrr"))
+			fakeCommandRunner.RunInSequenceWithContextReturns(fmt.Errorf("errrrrrooooorrrr"))
 
 			msg, stdOut, stdErr, _ := pm.PerformMeasurement()
 
@@ -96,3 +109,54 @@ var _ = Describe("Pushability", func() {
 		})
 	})
 })
+
+var _ = Describe("DockerPushability", func() {
+	var pm BaseMeasurement
+
+	BeforeEach(func() {
+		pm = NewDockerPushability(
+			func() []cmdStartWaiter.CmdStartWaiter { return nil },
+			&cmdRunnerfakes.FakeCmdRunner{},
+			bytes.NewBuffer([]byte{}),
+			bytes.NewBuffer([]byte{}),
+		)
+	})
+
+	Describe("Name", func() {
+		It("returns the name", func() {
+			Expect(pm.Name()).To(Equal("Docker image pushability"))
+		})
+	})
+
+	Describe("SummaryPhrase", func() {
+		It("returns the summary phrase", func() {
+			Expect(pm.SummaryPhrase()).To(Equal("push and delete a docker image app"))
+		})
+	})
+})
+
+var _ = Describe("BuildpackPushability", func() {
+	var pm BaseMeasurement
+
+	BeforeEach(func() {
+		pm = NewBuildpackPushability(
+			"staticfile",
+			func() []cmdStartWaiter.CmdStartWaiter { return nil },
+			&cmdRunnerfakes.FakeCmdRunner{},
+			bytes.NewBuffer([]byte{}),
+			bytes.NewBuffer([]byte{}),
+		)
+	})
+
+	Describe("Name", func() {
+		It("includes the matrix entry name", func() {
+			Expect(pm.Name()).To(Equal("App pushability (staticfile)"))
+		})
+	})
+
+	Describe("SummaryPhrase", func() {
+		It("includes the matrix entry name", func() {
+			Expect(pm.SummaryPhrase()).To(Equal("push and delete an app with staticfile"))
+		})
+	})
+})
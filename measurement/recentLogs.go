@@ -2,6 +2,7 @@ package measurement
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 
 	"github.com/cloudfoundry/uptimer/appLogValidator"
@@ -27,11 +28,19 @@ func (r *recentLogs) SummaryPhrase() string {
 	return r.summaryPhrase
 }
 
+func (r *recentLogs) Commands() []cmdStartWaiter.CmdStartWaiter {
+	return r.recentLogsCommandGeneratorFunc()
+}
+
 func (r *recentLogs) PerformMeasurement() (string, string, string, bool) {
+	return r.PerformMeasurementWithContext(context.TODO())
+}
+
+func (r *recentLogs) PerformMeasurementWithContext(ctx context.Context) (string, string, string, bool) {
 	defer r.runnerOutBuf.Reset()
 	defer r.runnerErrBuf.Reset()
 
-	if err := r.runner.RunInSequence(r.recentLogsCommandGeneratorFunc()...); err != nil {
+	if err := r.runner.RunInSequenceWithContext(ctx, r.recentLogsCommandGeneratorFunc()...); err != nil {
 		return err.Error(), r.runnerOutBuf.String(), r.runnerErrBuf.String(), false
 	}
 
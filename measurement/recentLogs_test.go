@@ -55,8 +55,9 @@ var _ = Describe("RecentLogs", func() {
 			}
 			rlm.PerformMeasurement()
 
-			Expect(fakeCommandRunner.RunInSequenceCallCount()).To(Equal(1))
-			Expect(fakeCommandRunner.RunInSequenceArgsForCall(0)).To(Equal(
+			Expect(fakeCommandRunner.RunInSequenceWithContextCallCount()).To(Equal(1))
+			_, cmds := fakeCommandRunner.RunInSequenceWithContextArgsForCall(0)
+			Expect(cmds).To(Equal(
 				[]cmdStartWaiter.CmdStartWaiter{
 					exec.Command("foo"),
 					exec.Command("bar"),
@@ -87,7 +88,7 @@ var _ = Describe("RecentLogs", func() {
 		})
 
 		It("records the commands that run with error as failed", func() {
-			fakeCommandRunner.RunInSequenceReturns(fmt.Errorf("errrrrrooooorrrr"))
+			fakeCommandRunner.RunInSequenceWithContextReturns(fmt.Errorf("errrrrrooooorrrr"))
 
 			_, _, _, res := rlm.PerformMeasurement()
 
@@ -97,7 +98,7 @@ var _ = Describe("RecentLogs", func() {
 		It("returns both stdout and stderr when there is an error running the command", func() {
 			outBuf.WriteString("heyyy guys")
 			errBuf.WriteString("whaaats happening?")
-			fakeCommandRunner.RunInSequenceReturns(fmt.Errorf("errrrrrooooorrrr"))
+			fakeCommandRunner.RunInSequenceWithContextReturns(fmt.Errorf("errrrrrooooorrrr"))
 
 			msg, stdOut, stdErr, _ := rlm.PerformMeasurement()
 
@@ -0,0 +1,78 @@
+package measurement
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+type rollingDeploy struct {
+	name                              string
+	summaryPhrase                     string
+	rollingDeployCommandGeneratorFunc func() []cmdStartWaiter.CmdStartWaiter
+	runner                            cmdRunner.CmdRunner
+	runnerOutBuf                      *bytes.Buffer
+	runnerErrBuf                      *bytes.Buffer
+	deployWindow                      *DeployWindow
+
+	mu           sync.Mutex
+	deploys      int
+	httpFailures int
+}
+
+func (r *rollingDeploy) Name() string {
+	return r.name
+}
+
+func (r *rollingDeploy) SummaryPhrase() string {
+	return r.summaryPhrase
+}
+
+func (r *rollingDeploy) Commands() []cmdStartWaiter.CmdStartWaiter {
+	return r.rollingDeployCommandGeneratorFunc()
+}
+
+func (r *rollingDeploy) PerformMeasurement() (string, string, string, bool) {
+	return r.PerformMeasurementWithContext(context.TODO())
+}
+
+func (r *rollingDeploy) PerformMeasurementWithContext(ctx context.Context) (string, string, string, bool) {
+	defer r.runnerOutBuf.Reset()
+	defer r.runnerErrBuf.Reset()
+
+	r.deployWindow.Open()
+	err := r.runner.RunInSequenceWithContext(ctx, r.rollingDeployCommandGeneratorFunc()...)
+	httpFailures := r.deployWindow.Close()
+
+	r.mu.Lock()
+	r.deploys++
+	r.httpFailures += httpFailures
+	r.mu.Unlock()
+
+	if err != nil {
+		return err.Error(), r.runnerOutBuf.String(), r.runnerErrBuf.String(), false
+	}
+
+	if httpFailures > 0 {
+		return fmt.Sprintf("%d HTTP requests to the app failed during its rolling deploy", httpFailures),
+			r.runnerOutBuf.String(),
+			r.runnerErrBuf.String(),
+			false
+	}
+
+	return "", "", "", true
+}
+
+func (r *rollingDeploy) Details() map[string]float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return map[string]float64{
+		"deploys":                      float64(r.deploys),
+		"http_failures_during_deploys": float64(r.httpFailures),
+	}
+}
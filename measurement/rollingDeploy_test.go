@@ -0,0 +1,156 @@
+package measurement_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+
+	"github.com/cloudfoundry/uptimer/cmdRunner/cmdRunnerfakes"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+	. "github.com/cloudfoundry/uptimer/measurement"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RollingDeploy", func() {
+	var (
+		commands          []cmdStartWaiter.CmdStartWaiter
+		fakeCommandRunner *cmdRunnerfakes.FakeCmdRunner
+		outBuf            *bytes.Buffer
+		errBuf            *bytes.Buffer
+		deployWindow      *DeployWindow
+
+		rd BaseMeasurement
+	)
+
+	BeforeEach(func() {
+		commands = []cmdStartWaiter.CmdStartWaiter{
+			exec.Command("foo"),
+			exec.Command("bar"),
+		}
+		fakeCommandRunner = &cmdRunnerfakes.FakeCmdRunner{}
+		outBuf = bytes.NewBuffer([]byte{})
+		errBuf = bytes.NewBuffer([]byte{})
+		deployWindow = NewDeployWindow()
+
+		rd = NewRollingDeploy(
+			func() []cmdStartWaiter.CmdStartWaiter { return commands },
+			fakeCommandRunner,
+			outBuf,
+			errBuf,
+			deployWindow,
+		)
+	})
+
+	Describe("Name", func() {
+		It("returns the name", func() {
+			Expect(rd.Name()).To(Equal("Rolling deploy"))
+		})
+	})
+
+	Describe("SummaryPhrase", func() {
+		It("returns the summary phrase", func() {
+			Expect(rd.SummaryPhrase()).To(Equal("deploy the app with a rolling strategy"))
+		})
+	})
+
+	Describe("PerformMeasurement", func() {
+		It("runs the rolling deploy commands", func() {
+			rd.PerformMeasurement()
+
+			Expect(fakeCommandRunner.RunInSequenceWithContextCallCount()).To(Equal(1))
+			_, cmds := fakeCommandRunner.RunInSequenceWithContextArgsForCall(0)
+			Expect(cmds).To(Equal(commands))
+		})
+
+		It("opens the deploy window while the commands run", func() {
+			fakeCommandRunner.RunInSequenceWithContextStub = func(context.Context, ...cmdStartWaiter.CmdStartWaiter) error {
+				Expect(deployWindow.RecordFailure()).To(BeTrue())
+				return nil
+			}
+
+			rd.PerformMeasurement()
+
+			Expect(deployWindow.RecordFailure()).To(BeFalse())
+		})
+
+		It("records a deploy without failed requests as success", func() {
+			_, _, _, res := rd.PerformMeasurement()
+
+			Expect(res).To(BeTrue())
+		})
+
+		Context("when HTTP requests fail during the deploy", func() {
+			BeforeEach(func() {
+				fakeCommandRunner.RunInSequenceWithContextStub = func(context.Context, ...cmdStartWaiter.CmdStartWaiter) error {
+					deployWindow.RecordFailure()
+					deployWindow.RecordFailure()
+					return nil
+				}
+			})
+
+			It("records the measurement as having failed", func() {
+				msg, _, _, res := rd.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("2 HTTP requests to the app failed during its rolling deploy"))
+			})
+
+			It("does not carry the failures over to the next deploy", func() {
+				rd.PerformMeasurement()
+				fakeCommandRunner.RunInSequenceWithContextStub = nil
+
+				_, _, _, res := rd.PerformMeasurement()
+
+				Expect(res).To(BeTrue())
+			})
+
+			It("reports the deploys and the failures during them", func() {
+				rd.PerformMeasurement()
+				rd.PerformMeasurement()
+
+				Expect(rd.(DetailedMeasurement).Details()).To(Equal(map[string]float64{
+					"deploys":                      2,
+					"http_failures_during_deploys": 4,
+				}))
+			})
+		})
+
+		Context("when the commands error", func() {
+			BeforeEach(func() {
+				fakeCommandRunner.RunInSequenceWithContextStub = func(context.Context, ...cmdStartWaiter.CmdStartWaiter) error {
+					outBuf.WriteString("some stdout output")
+					errBuf.WriteString("some stderr output")
+					return errors.New("some error")
+				}
+			})
+
+			It("records the measurement as having failed with the output", func() {
+				msg, stdOut, stdErr, res := rd.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("some error"))
+				Expect(stdOut).To(Equal("some stdout output"))
+				Expect(stdErr).To(Equal("some stderr output"))
+			})
+
+			It("closes the deploy window", func() {
+				rd.PerformMeasurement()
+
+				Expect(deployWindow.RecordFailure()).To(BeFalse())
+			})
+		})
+
+		It("does not accumulate buffers indefinitely", func() {
+			outBuf.WriteString("some stdout output")
+			errBuf.WriteString("some stderr output")
+
+			rd.PerformMeasurement()
+
+			Expect(outBuf.Len()).To(Equal(0))
+			Expect(errBuf.Len()).To(Equal(0))
+		})
+	})
+})
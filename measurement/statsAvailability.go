@@ -2,6 +2,11 @@ package measurement
 
 import (
 	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/cloudfoundry/uptimer/cmdRunner"
@@ -15,8 +20,20 @@ type statsAvailability struct {
 	runner                                cmdRunner.CmdRunner
 	runnerOutBuf                          *bytes.Buffer
 	runnerErrBuf                          *bytes.Buffer
+	instances                             int
 }
 
+// instanceStats is one row of the instance table printed by `cf app`.
+type instanceStats struct {
+	state  string
+	cpu    string
+	memory string
+}
+
+// instanceRowRegexp matches rows such as
+// "#0   running   2024-01-01T00:00:00Z   0.3%   20.5M of 256M   ...".
+var instanceRowRegexp = regexp.MustCompile(`^#(\d+)\s+(\S+)\s+\S+\s+(\S+)\s+(\S+ of \S+)`)
+
 func (s *statsAvailability) Name() string {
 	return s.name
 }
@@ -25,11 +42,19 @@ func (s *statsAvailability) SummaryPhrase() string {
 	return s.summaryPhrase
 }
 
+func (s *statsAvailability) Commands() []cmdStartWaiter.CmdStartWaiter {
+	return s.statsAvailabilityCommandGeneratorFunc()
+}
+
 func (s *statsAvailability) PerformMeasurement() (string, string, string, bool) {
+	return s.PerformMeasurementWithContext(context.TODO())
+}
+
+func (s *statsAvailability) PerformMeasurementWithContext(ctx context.Context) (string, string, string, bool) {
 	defer s.runnerOutBuf.Reset()
 	defer s.runnerErrBuf.Reset()
 
-	if err := s.runner.RunInSequence(s.statsAvailabilityCommandGeneratorFunc()...); err != nil {
+	if err := s.runner.RunInSequenceWithContext(ctx, s.statsAvailabilityCommandGeneratorFunc()...); err != nil {
 		return err.Error(), s.runnerOutBuf.String(), s.runnerErrBuf.String(), false
 	}
 
@@ -40,5 +65,107 @@ func (s *statsAvailability) PerformMeasurement() (string, string, string, bool)
 			false
 	}
 
+	if reasons := s.unhealthyReasons(parseWebInstanceStats(s.runnerOutBuf.String())); len(reasons) > 0 {
+		return strings.Join(reasons, "; "),
+			s.runnerOutBuf.String(),
+			s.runnerErrBuf.String(),
+			false
+	}
+
 	return "", "", "", true
 }
+
+// unhealthyReasons groups the expected instances by what is wrong with them,
+// so that crashes, slow starts and stats outages can be told apart.
+func (s *statsAvailability) unhealthyReasons(stats map[int]instanceStats) []string {
+	var crashed, starting, down, missingMetrics, missing []int
+	for i := 0; i < s.instances; i++ {
+		st, ok := stats[i]
+		switch {
+		case !ok:
+			missing = append(missing, i)
+		case st.state == "crashed":
+			crashed = append(crashed, i)
+		case st.state == "starting":
+			starting = append(starting, i)
+		case st.state != "running":
+			down = append(down, i)
+		case !hasCpuData(st.cpu) || !hasMemoryData(st.memory):
+			missingMetrics = append(missingMetrics, i)
+		}
+	}
+
+	var reasons []string
+	for _, r := range []struct {
+		description string
+		indices     []int
+	}{
+		{"crashed", crashed},
+		{"starting", starting},
+		{"down", down},
+		{"missing metrics", missingMetrics},
+		{"missing", missing},
+	} {
+		if len(r.indices) > 0 {
+			reasons = append(reasons, fmt.Sprintf("Instances %s: %s", r.description, formatInstanceIndices(r.indices)))
+		}
+	}
+
+	return reasons
+}
+
+// parseWebInstanceStats reads the instance table of the web process from
+// `cf app` output. Output without process types is treated as a single
+// web process.
+func parseWebInstanceStats(output string) map[int]instanceStats {
+	stats := map[int]instanceStats{}
+	processType := "web"
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "type:") {
+			processType = strings.TrimSpace(strings.TrimPrefix(line, "type:"))
+			continue
+		}
+		if processType != "web" {
+			continue
+		}
+
+		matches := instanceRowRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		index, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+		stats[index] = instanceStats{
+			state:  matches[2],
+			cpu:    matches[3],
+			memory: matches[4],
+		}
+	}
+
+	return stats
+}
+
+func hasCpuData(cpu string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSuffix(cpu, "%"), 64)
+	return strings.HasSuffix(cpu, "%") && err == nil
+}
+
+func hasMemoryData(memory string) bool {
+	used := strings.TrimSpace(strings.SplitN(memory, " of ", 2)[0])
+	value, err := strconv.ParseFloat(strings.TrimRight(used, "BKMGT"), 64)
+	return err == nil && value > 0
+}
+
+func formatInstanceIndices(indices []int) string {
+	sort.Ints(indices)
+	formatted := make([]string, len(indices))
+	for i, index := range indices {
+		formatted[i] = fmt.Sprintf("#%d", index)
+	}
+
+	return strings.Join(formatted, ", ")
+}
@@ -2,8 +2,10 @@ package measurement_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"os/exec"
+	"strings"
 
 	"github.com/cloudfoundry/uptimer/cmdRunner/cmdRunnerfakes"
 	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
@@ -20,6 +22,7 @@ var _ = Describe("Stats Availability", func() {
 		fakeCmdGeneratorFunc func() []cmdStartWaiter.CmdStartWaiter
 		outBuf               *bytes.Buffer
 		errBuf               *bytes.Buffer
+		appOutput            string
 
 		sm BaseMeasurement
 	)
@@ -32,7 +35,30 @@ var _ = Describe("Stats Availability", func() {
 		outBuf = bytes.NewBuffer([]byte{})
 		errBuf = bytes.NewBuffer([]byte{})
 
-		sm = NewStatsAvailability(fakeCmdGeneratorFunc, fakeCommandRunner, outBuf, errBuf)
+		appOutput = `Showing health and status for app some-app in org some-org / space some-space as admin...
+
+name:              some-app
+requested state:   started
+
+type:           web
+instances:      2/2
+memory usage:   256M
+     state     since                  cpu    memory         disk          logging          details
+#0   running   2024-01-01T00:00:00Z   0.3%   20.5M of 256M   100M of 1G   0/s of 16K/s
+#1   running   2024-01-01T00:00:00Z   0.0%   19M of 256M     100M of 1G   0/s of 16K/s
+
+type:           worker
+instances:      0/1
+memory usage:   256M
+     state     since                  cpu    memory   disk     logging   details
+#0   crashed   2024-01-01T00:00:00Z   0.0%   0 of 0   0 of 0   0/s of 0/s
+`
+		fakeCommandRunner.RunInSequenceWithContextStub = func(context.Context, ...cmdStartWaiter.CmdStartWaiter) error {
+			outBuf.WriteString(appOutput)
+			return nil
+		}
+
+		sm = NewStatsAvailability(fakeCmdGeneratorFunc, fakeCommandRunner, outBuf, errBuf, 2)
 	})
 
 	Describe("Name", func() {
@@ -58,8 +84,9 @@ var _ = Describe("Stats Availability", func() {
 		It("runs the commands to retrieve the stats for the app", func() {
 			sm.PerformMeasurement()
 
-			Expect(fakeCommandRunner.RunInSequenceCallCount()).To(Equal(1))
-			Expect(fakeCommandRunner.RunInSequenceArgsForCall(0)).To(Equal(
+			Expect(fakeCommandRunner.RunInSequenceWithContextCallCount()).To(Equal(1))
+			_, cmds := fakeCommandRunner.RunInSequenceWithContextArgsForCall(0)
+			Expect(cmds).To(Equal(
 				[]cmdStartWaiter.CmdStartWaiter{
 					exec.Command("foo"),
 					exec.Command("bar"),
@@ -72,6 +99,97 @@ var _ = Describe("Stats Availability", func() {
 			Expect(res).To(BeTrue())
 		})
 
+		It("only checks instances of the web process", func() {
+			_, _, _, res := sm.PerformMeasurement()
+			Expect(res).To(BeTrue())
+		})
+
+		Context("when an instance has crashed", func() {
+			BeforeEach(func() {
+				appOutput = strings.Replace(appOutput, "#1   running", "#1   crashed", 1)
+			})
+
+			It("records the measurement as having failed with the crashed instance", func() {
+				msg, stdOut, _, res := sm.PerformMeasurement()
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("Instances crashed: #1"))
+				Expect(stdOut).To(Equal(appOutput))
+			})
+		})
+
+		Context("when an instance is starting", func() {
+			BeforeEach(func() {
+				appOutput = strings.Replace(appOutput, "#0   running", "#0   starting", 1)
+			})
+
+			It("records the measurement as having failed with the starting instance", func() {
+				msg, _, _, res := sm.PerformMeasurement()
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("Instances starting: #0"))
+			})
+		})
+
+		Context("when an instance is down", func() {
+			BeforeEach(func() {
+				appOutput = strings.Replace(appOutput, "#0   running", "#0   down", 1)
+			})
+
+			It("records the measurement as having failed with the down instance", func() {
+				msg, _, _, res := sm.PerformMeasurement()
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("Instances down: #0"))
+			})
+		})
+
+		Context("when an instance reports no memory usage", func() {
+			BeforeEach(func() {
+				appOutput = strings.Replace(appOutput, "19M of 256M", "0 of 256M", 1)
+			})
+
+			It("records the measurement as having failed with missing metrics", func() {
+				msg, _, _, res := sm.PerformMeasurement()
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("Instances missing metrics: #1"))
+			})
+		})
+
+		Context("when an instance reports no cpu data", func() {
+			BeforeEach(func() {
+				appOutput = strings.Replace(appOutput, "0.3%", "-", 1)
+			})
+
+			It("records the measurement as having failed with missing metrics", func() {
+				msg, _, _, res := sm.PerformMeasurement()
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("Instances missing metrics: #0"))
+			})
+		})
+
+		Context("when an instance is missing from the output", func() {
+			BeforeEach(func() {
+				appOutput = strings.Replace(appOutput, "#1   running", "", 1)
+			})
+
+			It("records the measurement as having failed with the missing instance", func() {
+				msg, _, _, res := sm.PerformMeasurement()
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("Instances missing: #1"))
+			})
+		})
+
+		Context("when instances are unhealthy in different ways", func() {
+			BeforeEach(func() {
+				appOutput = strings.Replace(appOutput, "#0   running", "#0   crashed", 1)
+				appOutput = strings.Replace(appOutput, "19M of 256M", "0B of 256M", 1)
+			})
+
+			It("reports each reason separately", func() {
+				msg, _, _, res := sm.PerformMeasurement()
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("Instances crashed: #0; Instances missing metrics: #1"))
+			})
+		})
+
 		Context("when the CLI reports that stats server is unavailable", func() {
 			BeforeEach(func() {
 				errBuf.WriteString("Stats server temporarily unavailable.")
@@ -84,7 +202,7 @@ var _ = Describe("Stats Availability", func() {
 
 		Context("when the commands error", func() {
 			BeforeEach(func() {
-				fakeCommandRunner.RunInSequenceReturns(errors.New("some error"))
+				fakeCommandRunner.RunInSequenceWithContextReturns(errors.New("some error"))
 			})
 
 			It("records the measurement as having failed", func() {
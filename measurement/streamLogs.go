@@ -28,6 +28,13 @@ func (s *streamLogs) SummaryPhrase() string {
 	return s.summaryPhrase
 }
 
+func (s *streamLogs) Commands() []cmdStartWaiter.CmdStartWaiter {
+	_, cancelFunc, cmds := s.streamLogsCommandGeneratorFunc()
+	cancelFunc()
+
+	return cmds
+}
+
 func (s *streamLogs) PerformMeasurement() (string, string, string, bool) {
 	defer s.runnerOutBuf.Reset()
 	defer s.runnerErrBuf.Reset()
@@ -0,0 +1,202 @@
+package measurement
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+// drainSettleTime is how long a log line is given to reach the syslog sink
+// before it is counted as lost.
+const drainSettleTime = 10 * time.Second
+
+type syslogDrain struct {
+	name           string
+	summaryPhrase  string
+	sinkUrl        string
+	host           string
+	boundAt        int64
+	instances      int
+	window         time.Duration
+	maxLossPercent float64
+	client         *http.Client
+	clock          clock.Clock
+
+	mu         sync.Mutex
+	lastTo     int64
+	expected   int
+	received   int
+	delayTotal float64
+	delayMax   float64
+}
+
+type sinkCounts struct {
+	From      int64                    `json:"from"`
+	To        int64                    `json:"to"`
+	Instances map[string]sinkInstCount `json:"instances"`
+}
+
+type sinkInstCount struct {
+	Received          int     `json:"received"`
+	DelaySecondsTotal float64 `json:"delay_seconds_total"`
+	DelaySecondsMax   float64 `json:"delay_seconds_max"`
+}
+
+func (s *syslogDrain) Name() string {
+	return s.name
+}
+
+func (s *syslogDrain) SummaryPhrase() string {
+	return s.summaryPhrase
+}
+
+func (s *syslogDrain) PerformMeasurement() (string, string, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	to := s.clock.Now().Add(-drainSettleTime).Unix()
+	from := s.lastTo + 1
+	if s.lastTo == 0 {
+		from = to - int64(s.window/time.Second) + 1
+	}
+	// Logs sent before the drain was bound are not expected to arrive. What
+	// the sink has seen cannot tell when that was, since it forgets it all
+	// when it restarts.
+	from = max(from, s.boundAt)
+	if from > to {
+		return "", "", "", true
+	}
+
+	counts, err := s.fetchCounts(from, to)
+	if err != nil {
+		return err.Error(), "", "", false
+	}
+
+	instanceExpected := int(to - from + 1)
+	if len(counts.Instances) == 0 {
+		s.lastTo = to
+		s.expected += s.instances * instanceExpected
+		return "No app logs have reached the syslog sink", "", "", false
+	}
+
+	expected := 0
+	received := 0
+	for _, c := range counts.Instances {
+		instanceReceived := min(c.Received, instanceExpected)
+		expected += instanceExpected
+		received += instanceReceived
+		// Logs received beyond those expected, e.g. twice, are not counted,
+		// and neither are their delays.
+		if c.Received > 0 {
+			s.delayTotal += c.DelaySecondsTotal * float64(instanceReceived) / float64(c.Received)
+		}
+		s.delayMax = max(s.delayMax, c.DelaySecondsMax)
+	}
+	if missing := s.instances - len(counts.Instances); missing > 0 {
+		expected += missing * instanceExpected
+	}
+
+	s.lastTo = to
+	s.expected += expected
+	s.received += received
+
+	lossPercent := 100 * float64(expected-received) / float64(expected)
+	if lossPercent > s.maxLossPercent {
+		msg := fmt.Sprintf(
+			"%.2f%% of app logs between %s and %s were not drained (%d of %d received)",
+			lossPercent,
+			time.Unix(from, 0).UTC().Format(time.RFC3339),
+			time.Unix(to, 0).UTC().Format(time.RFC3339),
+			received,
+			expected,
+		)
+		return msg, "", "", false
+	}
+
+	return "", "", "", true
+}
+
+func (s *syslogDrain) fetchCounts(from, to int64) (*sinkCounts, error) {
+	return fetchSinkCounts(s.client, s.sinkUrl, s.host, from, to)
+}
+
+// fetchSinkCounts gets the counts between from and to, of host if set, from
+// the syslog sink at sinkUrl.
+func fetchSinkCounts(client *http.Client, sinkUrl, host string, from, to int64) (*sinkCounts, error) {
+	query := url.Values{}
+	query.Set("from", fmt.Sprintf("%d", from))
+	query.Set("to", fmt.Sprintf("%d", to))
+	if host != "" {
+		query.Set("host", host)
+	}
+
+	res, err := client.Get(fmt.Sprintf("%s/counts?%s", sinkUrl, query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("syslog sink responded with status %d; %s", res.StatusCode, res.Status)
+	}
+
+	counts := &sinkCounts{}
+	if err := json.NewDecoder(res.Body).Decode(counts); err != nil {
+		return nil, fmt.Errorf("failed to decode syslog sink counts: %s", err)
+	}
+
+	return counts, nil
+}
+
+func (s *syslogDrain) Details() map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	details := map[string]float64{
+		"loss_percent":      0,
+		"avg_delay_seconds": 0,
+		"max_delay_seconds": s.delayMax,
+	}
+	if s.expected > 0 {
+		details["loss_percent"] = 100 * float64(s.expected-s.received) / float64(s.expected)
+	}
+	if s.received > 0 {
+		details["avg_delay_seconds"] = s.delayTotal / float64(s.received)
+	}
+
+	return details
+}
+
+// syslogSinkCounts checks that a syslog sink serves the counts the syslog
+// drain measurements read, whether or not any logs have reached it yet.
+type syslogSinkCounts struct {
+	sinkUrl string
+	client  *http.Client
+	clock   clock.Clock
+}
+
+func (s *syslogSinkCounts) Name() string {
+	return "Syslog sink counts"
+}
+
+func (s *syslogSinkCounts) SummaryPhrase() string {
+	return "get the syslog sink counts"
+}
+
+func (s *syslogSinkCounts) PerformMeasurement() (string, string, string, bool) {
+	now := s.clock.Now().Unix()
+	counts, err := fetchSinkCounts(s.client, s.sinkUrl, "", now-int64(time.Minute/time.Second), now)
+	if err != nil {
+		return err.Error(), "", "", false
+	}
+	if counts.Instances == nil {
+		return "syslog sink counts have no instances", "", "", false
+	}
+
+	return "", "", "", true
+}
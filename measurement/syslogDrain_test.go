@@ -0,0 +1,359 @@
+package measurement_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	. "github.com/cloudfoundry/uptimer/measurement"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SyslogDrain", func() {
+	var (
+		fakeRoundTripper *FakeRoundTripper
+		mockClock        *clock.Mock
+		countsBody       string
+		maxLossPercent   float64
+		drainBoundAt     time.Time
+
+		sd BaseMeasurement
+	)
+
+	BeforeEach(func() {
+		fakeRoundTripper = &FakeRoundTripper{}
+		fakeRoundTripper.RoundTripStub = func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewBufferString(countsBody)),
+			}, nil
+		}
+		mockClock = clock.NewMock()
+		mockClock.Set(time.Unix(1000, 0))
+		countsBody = `{"instances": {
+			"host [APP/PROC/WEB/0]": {"received": 30, "delay_seconds_total": 15, "delay_seconds_max": 2},
+			"host [APP/PROC/WEB/1]": {"received": 30, "delay_seconds_total": 15, "delay_seconds_max": 1}
+		}}`
+		maxLossPercent = 0
+		drainBoundAt = time.Unix(500, 0)
+	})
+
+	JustBeforeEach(func() {
+		sd = NewSyslogDrain(
+			"http://tcp.example.com:1025",
+			"some-host",
+			drainBoundAt,
+			2,
+			maxLossPercent,
+			&http.Client{Transport: fakeRoundTripper},
+			mockClock,
+		)
+	})
+
+	Describe("Name", func() {
+		It("returns the name", func() {
+			Expect(sd.Name()).To(Equal("App syslog availability"))
+		})
+	})
+
+	Describe("SummaryPhrase", func() {
+		It("returns the summary phrase", func() {
+			Expect(sd.SummaryPhrase()).To(Equal("check application syslogs"))
+		})
+	})
+
+	Describe("PerformMeasurement", func() {
+		It("requests the counts for the settled window from the sink", func() {
+			sd.PerformMeasurement()
+
+			Expect(fakeRoundTripper.RoundTripCallCount()).To(Equal(1))
+			req := fakeRoundTripper.RoundTripArgsForCall(0)
+			Expect(req.Method).To(Equal(http.MethodGet))
+			Expect(req.URL.String()).To(Equal("http://tcp.example.com:1025/counts?from=961&host=some-host&to=990"))
+		})
+
+		It("continues from the end of the previous window", func() {
+			sd.PerformMeasurement()
+			mockClock.Add(30 * time.Second)
+			sd.PerformMeasurement()
+
+			req := fakeRoundTripper.RoundTripArgsForCall(1)
+			Expect(req.URL.Query().Get("from")).To(Equal("991"))
+			Expect(req.URL.Query().Get("to")).To(Equal("1020"))
+		})
+
+		It("records a complete window as success", func() {
+			_, _, _, res := sd.PerformMeasurement()
+
+			Expect(res).To(BeTrue())
+		})
+
+		Context("when app logs are missing", func() {
+			BeforeEach(func() {
+				countsBody = `{"instances": {
+					"host [APP/PROC/WEB/0]": {"received": 30},
+					"host [APP/PROC/WEB/1]": {"received": 27}
+				}}`
+			})
+
+			It("records the measurement as having failed with the loss", func() {
+				msg, _, _, res := sd.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("5.00% of app logs between 1970-01-01T00:16:01Z and 1970-01-01T00:16:30Z were not drained (57 of 60 received)"))
+			})
+
+			Context("when the loss is within the allowed loss", func() {
+				BeforeEach(func() {
+					maxLossPercent = 5
+				})
+
+				It("records the measurement as success", func() {
+					_, _, _, res := sd.PerformMeasurement()
+
+					Expect(res).To(BeTrue())
+				})
+			})
+		})
+
+		Context("when an instance is missing entirely", func() {
+			BeforeEach(func() {
+				countsBody = `{"instances": {
+					"host [APP/PROC/WEB/0]": {"received": 30}
+				}}`
+			})
+
+			It("counts its logs as lost", func() {
+				msg, _, _, res := sd.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(ContainSubstring("50.00% of app logs"))
+			})
+		})
+
+		Context("when the drain was bound partway through the window", func() {
+			BeforeEach(func() {
+				drainBoundAt = time.Unix(981, 0)
+				countsBody = `{"instances": {
+					"host [APP/PROC/WEB/0]": {"received": 10},
+					"host [APP/PROC/WEB/1]": {"received": 10}
+				}}`
+			})
+
+			It("only asks for and expects logs from when the drain was bound", func() {
+				_, _, _, res := sd.PerformMeasurement()
+
+				Expect(res).To(BeTrue())
+				req := fakeRoundTripper.RoundTripArgsForCall(0)
+				Expect(req.URL.Query().Get("from")).To(Equal("981"))
+			})
+		})
+
+		Context("when the sink restarted partway through the window", func() {
+			BeforeEach(func() {
+				countsBody = `{"instances": {
+					"host [APP/PROC/WEB/0]": {"received": 6},
+					"host [APP/PROC/WEB/1]": {"received": 6}
+				}}`
+			})
+
+			It("counts the logs sent before the restart as lost", func() {
+				msg, _, _, res := sd.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(ContainSubstring("(12 of 60 received)"))
+			})
+		})
+
+		Context("when no app logs have reached the sink", func() {
+			BeforeEach(func() {
+				countsBody = `{"instances": {}}`
+			})
+
+			It("records the measurement as having failed", func() {
+				msg, _, _, res := sd.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("No app logs have reached the syslog sink"))
+			})
+
+			It("counts the logs of the window as lost", func() {
+				sd.PerformMeasurement()
+
+				Expect(sd.(DetailedMeasurement).Details()).To(HaveKeyWithValue("loss_percent", 100.0))
+			})
+
+			It("moves on to the next window", func() {
+				sd.PerformMeasurement()
+				mockClock.Add(30 * time.Second)
+				sd.PerformMeasurement()
+
+				req := fakeRoundTripper.RoundTripArgsForCall(1)
+				Expect(req.URL.Query().Get("from")).To(Equal("991"))
+			})
+		})
+
+		Context("when the sink cannot be reached", func() {
+			BeforeEach(func() {
+				fakeRoundTripper.RoundTripStub = nil
+				fakeRoundTripper.RoundTripReturns(nil, errors.New("connection refused"))
+			})
+
+			It("records the measurement as having failed", func() {
+				msg, _, _, res := sd.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(ContainSubstring("connection refused"))
+			})
+
+			It("retries the same window on the next attempt", func() {
+				sd.PerformMeasurement()
+				mockClock.Add(30 * time.Second)
+				sd.PerformMeasurement()
+
+				req := fakeRoundTripper.RoundTripArgsForCall(1)
+				Expect(req.URL.Query().Get("from")).To(Equal("991"))
+			})
+		})
+
+		Context("when the sink responds with an error", func() {
+			BeforeEach(func() {
+				fakeRoundTripper.RoundTripStub = func(*http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: 502,
+						Status:     "502 Bad Gateway",
+						Body:       io.NopCloser(bytes.NewBufferString("")),
+					}, nil
+				}
+			})
+
+			It("records the measurement as having failed", func() {
+				msg, _, _, res := sd.PerformMeasurement()
+
+				Expect(res).To(BeFalse())
+				Expect(msg).To(Equal("syslog sink responded with status 502; 502 Bad Gateway"))
+			})
+		})
+	})
+
+	Describe("Details", func() {
+		It("reports the loss and delay across all windows", func() {
+			sd.PerformMeasurement()
+			countsBody = `{"instances": {
+				"host [APP/PROC/WEB/0]": {"received": 15, "delay_seconds_total": 30, "delay_seconds_max": 4},
+				"host [APP/PROC/WEB/1]": {"received": 15, "delay_seconds_total": 30, "delay_seconds_max": 3}
+			}}`
+			mockClock.Add(30 * time.Second)
+			sd.PerformMeasurement()
+
+			Expect(sd.(DetailedMeasurement).Details()).To(Equal(map[string]float64{
+				"loss_percent":      25,
+				"avg_delay_seconds": 1,
+				"max_delay_seconds": 4,
+			}))
+		})
+
+		It("leaves out the delays of logs received beyond those expected", func() {
+			countsBody = `{"instances": {
+				"host [APP/PROC/WEB/0]": {"received": 60, "delay_seconds_total": 60, "delay_seconds_max": 2},
+				"host [APP/PROC/WEB/1]": {"received": 30, "delay_seconds_total": 15, "delay_seconds_max": 1}
+			}}`
+			sd.PerformMeasurement()
+
+			Expect(sd.(DetailedMeasurement).Details()).To(HaveKeyWithValue("avg_delay_seconds", 0.75))
+		})
+	})
+})
+
+var _ = Describe("AggregateSyslogDrain", func() {
+	var sd BaseMeasurement
+
+	BeforeEach(func() {
+		sd = NewAggregateSyslogDrain("https://sink.example.com", "org.space.app", time.Unix(0, 0), 2, 0, &http.Client{}, clock.NewMock())
+	})
+
+	Describe("Name", func() {
+		It("returns the name", func() {
+			Expect(sd.Name()).To(Equal("Aggregate syslog availability"))
+		})
+	})
+
+	Describe("SummaryPhrase", func() {
+		It("returns the summary phrase", func() {
+			Expect(sd.SummaryPhrase()).To(Equal("check aggregate syslogs"))
+		})
+	})
+})
+
+var _ = Describe("SyslogSinkCounts", func() {
+	var (
+		fakeRoundTripper *FakeRoundTripper
+		statusCode       int
+		countsBody       string
+
+		sc BaseMeasurement
+	)
+
+	BeforeEach(func() {
+		statusCode = 200
+		countsBody = `{"from": 940, "to": 1000, "instances": {}}`
+		fakeRoundTripper = &FakeRoundTripper{}
+		fakeRoundTripper.RoundTripStub = func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: statusCode,
+				Status:     http.StatusText(statusCode),
+				Body:       io.NopCloser(bytes.NewBufferString(countsBody)),
+			}, nil
+		}
+		mockClock := clock.NewMock()
+		mockClock.Set(time.Unix(1000, 0))
+
+		sc = NewSyslogSinkCounts("http://tcp.example.com:1025", &http.Client{Transport: fakeRoundTripper}, mockClock)
+	})
+
+	It("returns the name", func() {
+		Expect(sc.Name()).To(Equal("Syslog sink counts"))
+	})
+
+	It("succeeds when the sink serves counts, even without any logs", func() {
+		msg, _, _, ok := sc.PerformMeasurement()
+
+		Expect(ok).To(BeTrue(), msg)
+		req := fakeRoundTripper.RoundTripArgsForCall(0)
+		Expect(req.URL.String()).To(Equal("http://tcp.example.com:1025/counts?from=940&to=1000"))
+	})
+
+	It("fails when the sink does not serve counts", func() {
+		statusCode = 404
+		countsBody = "404 page not found"
+
+		msg, _, _, ok := sc.PerformMeasurement()
+
+		Expect(ok).To(BeFalse())
+		Expect(msg).To(Equal("syslog sink responded with status 404; Not Found"))
+	})
+
+	It("fails when the counts cannot be decoded", func() {
+		countsBody = "Hello"
+
+		msg, _, _, ok := sc.PerformMeasurement()
+
+		Expect(ok).To(BeFalse())
+		Expect(msg).To(HavePrefix("failed to decode syslog sink counts"))
+	})
+
+	It("fails when the counts have no instances", func() {
+		countsBody = `{"status": "ok"}`
+
+		msg, _, _, ok := sc.PerformMeasurement()
+
+		Expect(ok).To(BeFalse())
+		Expect(msg).To(Equal("syslog sink counts have no instances"))
+	})
+})
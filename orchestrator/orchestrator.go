@@ -25,9 +25,11 @@ import (
 
 //go:generate counterfeiter . Orchestrator
 type Orchestrator interface {
-	Setup(cmdRunner.CmdRunner, cfCmdGenerator.CfCmdGenerator, config.OptionalTests) error
+	Setup(cmdRunner.CmdRunner, cfCmdGenerator.CfCmdGenerator, config.OptionalTests, config.SyslogDrain) error
 	Run(bool, string) (int, error)
 	TearDown(cmdRunner.CmdRunner, cfCmdGenerator.CfCmdGenerator) error
+	Plan(cmdRunner.CmdRunner) error
+	Cancel(os.Signal)
 }
 
 type orchestrator struct {
@@ -37,14 +39,34 @@ type orchestrator struct {
 	whileCommandsRunner cmdRunner.CmdRunner
 	measurements        []measurement.Measurement
 	ioutilshim          ioutilshim.Ioutil
+	runID               string
+	setupDurations      []SetupDuration
+
+	// syslogDrainService is the service Setup created for the app syslog
+	// availability measurement, if any.
+	syslogDrainService string
+
+	cancellation cancellation
 }
 
 type result struct {
-	Summaries   []measurement.Summary `json:"summaries"`
-	CmdExitCode int                   `json:"commandExitCode"`
+	RunID          string                `json:"runId,omitempty"`
+	SetupDurations []SetupDuration       `json:"setupDurations,omitempty"`
+	Summaries      []measurement.Summary `json:"summaries"`
+	CmdExitCode    int                   `json:"commandExitCode"`
+	Cancelled      bool                  `json:"cancelled,omitempty"`
 }
 
-func New(whileConfig []*config.Command, logger *log.Logger, workflow cfWorkflow.CfWorkflow, runner cmdRunner.CmdRunner, measurements []measurement.Measurement, ioutilShim ioutilshim.Ioutil) Orchestrator {
+// SetupDuration is how long setting up a workflow took, whether or not it
+// succeeded.
+type SetupDuration struct {
+	Foundation      string  `json:"foundation,omitempty"`
+	Workflow        string  `json:"workflow"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	Failed          bool    `json:"failed,omitempty"`
+}
+
+func New(whileConfig []*config.Command, logger *log.Logger, workflow cfWorkflow.CfWorkflow, runner cmdRunner.CmdRunner, measurements []measurement.Measurement, ioutilShim ioutilshim.Ioutil, runID string, setupDurations []SetupDuration) Orchestrator {
 	return &orchestrator{
 		logger:              logger,
 		whileConfig:         whileConfig,
@@ -52,17 +74,20 @@ func New(whileConfig []*config.Command, logger *log.Logger, workflow cfWorkflow.
 		whileCommandsRunner: runner,
 		measurements:        measurements,
 		ioutilshim:          ioutilShim,
+		runID:               runID,
+		setupDurations:      setupDurations,
 	}
 }
 
-func (o *orchestrator) Setup(runner cmdRunner.CmdRunner, ccg cfCmdGenerator.CfCmdGenerator, optionalTests config.OptionalTests) error {
+func (o *orchestrator) Setup(runner cmdRunner.CmdRunner, ccg cfCmdGenerator.CfCmdGenerator, optionalTests config.OptionalTests, syslogDrain config.SyslogDrain) error {
 	serviceName := fmt.Sprintf("uptimer-srv-%s", uuid.NewV4().String())
 
 	cmds := o.workflow.Setup(ccg)
 	cmds = append(cmds, o.workflow.Push(ccg)...)
 
 	if optionalTests.RunAppSyslogAvailability {
-		cmds = append(cmds, o.workflow.CreateAndBindSyslogDrainService(ccg, serviceName)...)
+		o.syslogDrainService = serviceName
+		cmds = append(cmds, o.workflow.CreateAndBindSyslogDrainService(ccg, serviceName, syslogDrain.Scheme)...)
 	}
 
 	return runner.RunInSequence(cmds...)
@@ -73,7 +98,9 @@ func (o *orchestrator) Run(performMeasurements bool, resultFilePath string) (int
 		o.logger.Println("*****NOT PERFORMING ANY MEASUREMENTS*****")
 	}
 
-	if performMeasurements {
+	// A run cancelled before it started still reports its measurements,
+	// without any attempts.
+	if performMeasurements && o.cancellation.cancelledBy() == nil {
 		for _, m := range o.measurements {
 			o.logger.Printf("Starting measurement: %s\n", m.Name())
 			go m.Start()
@@ -83,13 +110,24 @@ func (o *orchestrator) Run(performMeasurements bool, resultFilePath string) (int
 	o.logger.Println("Running commands...")
 	exitCode := 0
 	commandExitCode := 0
-	err := o.whileCommandsRunner.RunInSequence(o.createWhileCmds()...)
+	var whileCmds []cmdStartWaiter.CmdStartWaiter
+	for _, cmd := range o.createWhileCmds() {
+		whileCmds = append(whileCmds, &whileCmd{Cmd: cmd, cancellation: &o.cancellation})
+	}
+	err := o.whileCommandsRunner.RunInSequence(whileCmds...)
 	if err != nil {
 		exitCode = getExitCodeFromErr(err)
 		commandExitCode = exitCode
 	}
 	o.logger.Println("Finished running commands")
 
+	cancelledBy := o.cancellation.cancelledBy()
+	if cancelledBy != nil {
+		o.logger.Printf("Run cancelled by %s\n", cancelledBy)
+		exitCode = cancelledExitCode(cancelledBy)
+		err = nil
+	}
+
 	if performMeasurements {
 		for _, m := range o.measurements {
 			o.logger.Printf("Stopping measurement: %s\n", m.Name())
@@ -98,24 +136,32 @@ func (o *orchestrator) Run(performMeasurements bool, resultFilePath string) (int
 		}
 
 		o.logger.Println("Measurement summaries:")
-		for _, m := range o.measurements {
-			if m.Failed() {
-				if exitCode == 0 {
-					exitCode = 64
-				}
-				o.logger.Printf("\x1b[31m%s\x1b[0m\n", m.Summary())
+		foundations, measurementsByFoundation := groupByFoundation(o.measurements)
+		for _, foundation := range foundations {
+			if foundation != "" {
+				o.logger.Printf("Foundation %s:\n", foundation)
+			}
+			for _, m := range measurementsByFoundation[foundation] {
+				if m.Failed() {
+					if exitCode == 0 {
+						exitCode = 64
+					}
+					o.logger.Printf("\x1b[31m%s\x1b[0m\n", m.Summary())
 
-			} else {
-				o.logger.Printf("\x1b[32m%s\x1b[0m\n", m.Summary())
+				} else {
+					o.logger.Printf("\x1b[32m%s\x1b[0m\n", m.Summary())
+				}
 			}
 		}
 
 		if resultFilePath != "" {
-			r := result{}
+			r := result{RunID: o.runID, SetupDurations: o.setupDurations}
+			// Each summary names its foundation, if any.
 			for _, m := range o.measurements {
 				r.Summaries = append(r.Summaries, m.SummaryData())
 			}
 			r.CmdExitCode = commandExitCode
+			r.Cancelled = cancelledBy != nil
 			resultJSON, err := json.Marshal(r)
 			if err != nil {
 				o.logger.Printf("WARN: Failed to serilaize results to json: %s", err.Error())
@@ -128,23 +174,90 @@ func (o *orchestrator) Run(performMeasurements bool, resultFilePath string) (int
 	}
 
 	// Alert user that the While Command succeeded, but we failed in the setup of one or more measurements
-	if !performMeasurements && exitCode == 0 {
+	if !performMeasurements && exitCode == 0 && cancelledBy == nil {
 		exitCode = 70
 	}
 
 	return exitCode, err
 }
 
+// Cancel forwards sig to the running while command and keeps the remaining
+// ones from starting. Run then stops the measurements and reports their
+// partial results as usual.
+func (o *orchestrator) Cancel(sig os.Signal) {
+	if o.cancellation.cancel(sig) {
+		o.logger.Printf("Forwarded %s to the running command\n", sig)
+	}
+}
+
+// Plan passes the commands Run would run to runner instead of running them:
+// the commands of one attempt of each measurement, then the while commands.
+func (o *orchestrator) Plan(runner cmdRunner.CmdRunner) error {
+	for _, m := range o.measurements {
+		var cmds []cmdStartWaiter.CmdStartWaiter
+		if cm, ok := m.(measurement.CommandMeasurement); ok {
+			cmds = cm.Commands()
+		}
+		if len(cmds) == 0 {
+			o.logger.Printf("Measurement %s runs no commands\n", m.Name())
+			continue
+		}
+
+		o.logger.Printf("Measurement %s runs:\n", m.Name())
+		if err := runner.RunInSequence(cmds...); err != nil {
+			return err
+		}
+	}
+
+	o.logger.Println("While commands:")
+	var cmds []cmdStartWaiter.CmdStartWaiter
+	for _, cmd := range o.createWhileCmds() {
+		cmds = append(cmds, cmd)
+	}
+
+	return runner.RunInSequence(cmds...)
+}
+
+// groupByFoundation returns the foundations of the measurements in the order
+// they first appear, and the measurements of each. Measurements of a single
+// foundation run have no foundation.
+func groupByFoundation(measurements []measurement.Measurement) ([]string, map[string][]measurement.Measurement) {
+	var foundations []string
+	byFoundation := map[string][]measurement.Measurement{}
+	for _, m := range measurements {
+		foundation := m.SummaryData().Foundation
+		if _, ok := byFoundation[foundation]; !ok {
+			foundations = append(foundations, foundation)
+		}
+		byFoundation[foundation] = append(byFoundation[foundation], m)
+	}
+
+	return foundations, byFoundation
+}
+
 func (o *orchestrator) TearDown(runner cmdRunner.CmdRunner, ccg cfCmdGenerator.CfCmdGenerator) error {
-	return runner.RunInSequence(o.workflow.TearDown(ccg)...)
+	// A failure to delete the service must not keep the workflow from
+	// being torn down.
+	var serviceErr error
+	if o.syslogDrainService != "" {
+		if cmds := o.workflow.DeleteSyslogDrainService(ccg, o.syslogDrainService); len(cmds) > 0 {
+			serviceErr = runner.RunInSequence(cmds...)
+		}
+	}
+
+	if err := runner.RunInSequence(o.workflow.TearDown(ccg)...); err != nil {
+		return err
+	}
+
+	return serviceErr
 }
 
 type Syser interface {
 	Sys() interface{}
 }
 
-func (o *orchestrator) createWhileCmds() []cmdStartWaiter.CmdStartWaiter {
-	cmds := []cmdStartWaiter.CmdStartWaiter{}
+func (o *orchestrator) createWhileCmds() []*exec.Cmd {
+	cmds := []*exec.Cmd{}
 	for _, cfg := range o.whileConfig {
 		cmds = append(cmds, exec.Command(cfg.Command, cfg.CommandArgs...))
 	}
@@ -152,6 +265,16 @@ func (o *orchestrator) createWhileCmds() []cmdStartWaiter.CmdStartWaiter {
 	return cmds
 }
 
+// cancelledExitCode follows the shell convention for commands terminated by
+// a signal.
+func cancelledExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+
+	return 1
+}
+
 func getExitCodeFromErr(err error) int {
 	if _, ok := err.(Syser); ok {
 		errorCode := err.(Syser).Sys().(syscall.WaitStatus).ExitStatus()
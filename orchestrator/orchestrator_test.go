@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os/exec"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
 	"github.com/cloudfoundry/uptimer/cfWorkflow/cfWorkflowfakes"
+	"github.com/cloudfoundry/uptimer/cmdRunner"
 	"github.com/cloudfoundry/uptimer/cmdRunner/cmdRunnerfakes"
 	"github.com/cloudfoundry/uptimer/config"
 	"github.com/cloudfoundry/uptimer/measurement/measurementfakes"
@@ -18,6 +21,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 
 	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
 	"github.com/cloudfoundry/uptimer/measurement"
@@ -39,6 +43,7 @@ var _ = Describe("Orchestrator", func() {
 		fakeIoutil       *ioutil_fake.FakeIoutil
 
 		ot config.OptionalTests
+		sd config.SyslogDrain
 
 		orc Orchestrator
 
@@ -66,8 +71,9 @@ var _ = Describe("Orchestrator", func() {
 		fakeIoutil = &ioutil_fake.FakeIoutil{}
 
 		ot = config.OptionalTests{RunAppSyslogAvailability: false}
+		sd = config.SyslogDrain{Scheme: "syslog-tls"}
 
-		orc = New([]*config.Command{fakeCommand1, fakeCommand2}, logger, fakeWorkflow, fakeRunner, []measurement.Measurement{fakeMeasurement1, fakeMeasurement2}, fakeIoutil)
+		orc = New([]*config.Command{fakeCommand1, fakeCommand2}, logger, fakeWorkflow, fakeRunner, []measurement.Measurement{fakeMeasurement1, fakeMeasurement2}, fakeIoutil, "some-run-id", nil)
 
 		resultFilePath = ""
 	})
@@ -94,7 +100,7 @@ var _ = Describe("Orchestrator", func() {
 		})
 		Context("not running syslog test", func() {
 			It("calls workflow to get setup and push stuff and runs it", func() {
-				err := orc.Setup(fakeRunner, ccg, ot)
+				err := orc.Setup(fakeRunner, ccg, ot, sd)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(fakeWorkflow.SetupCallCount()).To(Equal(1))
@@ -115,7 +121,7 @@ var _ = Describe("Orchestrator", func() {
 			It("Returns an error if runner returns an error", func() {
 				fakeRunner.RunInSequenceReturns(fmt.Errorf("uh oh"))
 
-				err := orc.Setup(fakeRunner, ccg, ot)
+				err := orc.Setup(fakeRunner, ccg, ot, sd)
 
 				Expect(err).To(MatchError("uh oh"))
 			})
@@ -127,7 +133,7 @@ var _ = Describe("Orchestrator", func() {
 			})
 
 			It("calls workflow to get setup and push stuff and created and binds a service and runs it", func() {
-				err := orc.Setup(fakeRunner, ccg, ot)
+				err := orc.Setup(fakeRunner, ccg, ot, sd)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(fakeWorkflow.SetupCallCount()).To(Equal(1))
@@ -137,9 +143,10 @@ var _ = Describe("Orchestrator", func() {
 				Expect(fakeWorkflow.CreateAndBindSyslogDrainServiceCallCount()).To(Equal(1))
 
 				Expect(fakeWorkflow.CreateAndBindSyslogDrainServiceCallCount()).To(Equal(1))
-				ccgArg, serviceName := fakeWorkflow.CreateAndBindSyslogDrainServiceArgsForCall(0)
+				ccgArg, serviceName, scheme := fakeWorkflow.CreateAndBindSyslogDrainServiceArgsForCall(0)
 				Expect(ccgArg).To(Equal(ccg))
 				Expect(serviceName).To(ContainSubstring("uptimer-srv-"))
+				Expect(scheme).To(Equal("syslog-tls"))
 
 				Expect(fakeRunner.RunInSequenceCallCount()).To(Equal(1))
 				Expect(fakeRunner.RunInSequenceArgsForCall(0)).To(Equal(
@@ -156,7 +163,7 @@ var _ = Describe("Orchestrator", func() {
 			It("Returns an error if runner returns an error", func() {
 				fakeRunner.RunInSequenceReturns(fmt.Errorf("uh oh"))
 
-				err := orc.Setup(fakeRunner, ccg, ot)
+				err := orc.Setup(fakeRunner, ccg, ot, sd)
 
 				Expect(err).To(MatchError("uh oh"))
 			})
@@ -170,11 +177,11 @@ var _ = Describe("Orchestrator", func() {
 			Expect(exitCode).To(Equal(0))
 
 			Expect(fakeRunner.RunInSequenceCallCount()).To(Equal(1))
-			Expect(fakeRunner.RunInSequenceArgsForCall(0)).To(Equal(
-				[]cmdStartWaiter.CmdStartWaiter{
+			Expect(commandLines(fakeRunner.RunInSequenceArgsForCall(0))).To(Equal(
+				commandLines([]cmdStartWaiter.CmdStartWaiter{
 					exec.Command("sleep", "10"),
 					exec.Command("sleep", "15"),
-				},
+				}),
 			))
 		})
 
@@ -189,11 +196,11 @@ var _ = Describe("Orchestrator", func() {
 				Expect(err).To(HaveOccurred())
 				Expect(exitCode).To(Equal(2))
 
-				Expect(fakeRunner.RunInSequenceArgsForCall(0)).To(Equal(
-					[]cmdStartWaiter.CmdStartWaiter{
+				Expect(commandLines(fakeRunner.RunInSequenceArgsForCall(0))).To(Equal(
+					commandLines([]cmdStartWaiter.CmdStartWaiter{
 						exec.Command("sleep", "10"),
 						exec.Command("sleep", "15"),
-					},
+					}),
 				))
 				Expect(fakeRunner.RunInSequenceCallCount()).To(Equal(1))
 			})
@@ -203,11 +210,11 @@ var _ = Describe("Orchestrator", func() {
 
 				exitCode, err := orc.Run(true, resultFilePath)
 
-				Expect(fakeRunner.RunInSequenceArgsForCall(0)).To(Equal(
-					[]cmdStartWaiter.CmdStartWaiter{
+				Expect(commandLines(fakeRunner.RunInSequenceArgsForCall(0))).To(Equal(
+					commandLines([]cmdStartWaiter.CmdStartWaiter{
 						exec.Command("sleep", "10"),
 						exec.Command("sleep", "15"),
-					},
+					}),
 				))
 				Expect(fakeRunner.RunInSequenceCallCount()).To(Equal(1))
 				Expect(exitCode).To(Equal(-1))
@@ -395,6 +402,41 @@ var _ = Describe("Orchestrator", func() {
 			})
 		})
 
+		Context("when the run was cancelled before it started", func() {
+			BeforeEach(func() {
+				orc.Cancel(syscall.SIGTERM)
+			})
+
+			It("does not start the measurements", func() {
+				exitCode, err := orc.Run(true, resultFilePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(exitCode).To(Equal(143))
+
+				Expect(fakeMeasurement1.StartCallCount()).To(Equal(0))
+				Expect(fakeMeasurement2.StartCallCount()).To(Equal(0))
+			})
+
+			It("writes results flagged as cancelled", func() {
+				fakeMeasurement1.SummaryDataReturns(measurement.Summary{Name: "name1"})
+				fakeMeasurement2.SummaryDataReturns(measurement.Summary{Name: "name2"})
+
+				_, err := orc.Run(true, "/tmp/results")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeIoutil.WriteFileCallCount()).To(Equal(1))
+				_, jsonBytes, _ := fakeIoutil.WriteFileArgsForCall(0)
+				Expect(jsonBytes).To(MatchJSON(`{
+					"runId": "some-run-id",
+					"commandExitCode": 0,
+					"cancelled": true,
+					"summaries": [
+						{"name": "name1", "failed": 0, "summaryPhrase": "", "allowedFailures": 0, "total": 0},
+						{"name": "name2", "failed": 0, "summaryPhrase": "", "allowedFailures": 0, "total": 0}
+					]
+				}`))
+			})
+		})
+
 		Context("When a results file is specified", func() {
 
 			It("outputs json results", func() {
@@ -408,6 +450,7 @@ var _ = Describe("Orchestrator", func() {
 				Expect(fakeIoutil.WriteFileCallCount()).To(Equal(1))
 				_, jsonBytes, _ := fakeIoutil.WriteFileArgsForCall(0)
 				Expect(jsonBytes).To(MatchJSON(`{
+					"runId": "some-run-id",
 					"commandExitCode": 0,
 					"summaries": [
 						{
@@ -428,6 +471,41 @@ var _ = Describe("Orchestrator", func() {
 				}`))
 			})
 
+			Context("When workflow setup durations are given", func() {
+				BeforeEach(func() {
+					orc = New([]*config.Command{fakeCommand1, fakeCommand2}, logger, fakeWorkflow, fakeRunner, []measurement.Measurement{fakeMeasurement1}, fakeIoutil, "some-run-id", []SetupDuration{
+						{Workflow: "push", DurationSeconds: 12.5},
+						{Foundation: "east", Workflow: "main", DurationSeconds: 30, Failed: true},
+					})
+				})
+
+				It("outputs them in the json results", func() {
+					fakeMeasurement1.SummaryDataReturns(measurement.Summary{})
+
+					_, err := orc.Run(true, "/tmp/results")
+					Expect(err).NotTo(HaveOccurred())
+
+					_, jsonBytes, _ := fakeIoutil.WriteFileArgsForCall(0)
+					Expect(jsonBytes).To(MatchJSON(`{
+						"runId": "some-run-id",
+						"setupDurations": [
+							{"workflow": "push", "durationSeconds": 12.5},
+							{"foundation": "east", "workflow": "main", "durationSeconds": 30, "failed": true}
+						],
+						"commandExitCode": 0,
+						"summaries": [
+							{
+								"name": "",
+								"failed": 0,
+								"summaryPhrase": "",
+								"allowedFailures": 0,
+								"total": 0
+							}
+						]
+					}`))
+				})
+			})
+
 			Context("When command fails", func() {
 				It("outputs json results", func() {
 					fakeRunner.RunInSequenceReturns(fmt.Errorf("uh oh"))
@@ -440,6 +518,7 @@ var _ = Describe("Orchestrator", func() {
 					Expect(fakeIoutil.WriteFileCallCount()).To(Equal(1))
 					_, jsonBytes, _ := fakeIoutil.WriteFileArgsForCall(0)
 					Expect(jsonBytes).To(MatchJSON(`{
+					    "runId": "some-run-id",
 					    "commandExitCode": -1,
 					    "summaries": [
 						    {
@@ -462,6 +541,37 @@ var _ = Describe("Orchestrator", func() {
 			})
 		})
 
+		Context("When measuring several foundations", func() {
+			BeforeEach(func() {
+				fakeMeasurement1.SummaryReturns("summary1")
+				fakeMeasurement1.SummaryDataReturns(measurement.Summary{Name: "name1", Foundation: "east"})
+				fakeMeasurement2.SummaryReturns("summary2")
+				fakeMeasurement2.SummaryDataReturns(measurement.Summary{Name: "name2", Foundation: "west"})
+			})
+
+			It("prints the summaries under a header per foundation", func() {
+				_, err := orc.Run(true, "")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logBuf.String()).To(MatchRegexp(`(?s)Foundation east:.*summary1.*Foundation west:.*summary2`))
+			})
+
+			It("names the foundation of each summary in the json results", func() {
+				_, err := orc.Run(true, "/tmp/results")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, jsonBytes, _ := fakeIoutil.WriteFileArgsForCall(0)
+				Expect(jsonBytes).To(MatchJSON(`{
+					"runId": "some-run-id",
+					"commandExitCode": 0,
+					"summaries": [
+						{"name": "name1", "failed": 0, "summaryPhrase": "", "allowedFailures": 0, "total": 0, "foundation": "east"},
+						{"name": "name2", "failed": 0, "summaryPhrase": "", "allowedFailures": 0, "total": 0, "foundation": "west"}
+					]
+				}`))
+			})
+		})
+
 		Context("When a results file is not specified", func() {
 			It("outputs json results", func() {
 				_, err := orc.Run(true, "")
@@ -513,9 +623,188 @@ var _ = Describe("Orchestrator", func() {
 
 			Expect(err).To(MatchError("uh oh"))
 		})
+
+		Context("when setup created a syslog drain service", func() {
+			BeforeEach(func() {
+				ot = config.OptionalTests{RunAppSyslogAvailability: true}
+				fakeWorkflow.TearDownReturns([]cmdStartWaiter.CmdStartWaiter{exec.Command("delete", "org")})
+			})
+
+			JustBeforeEach(func() {
+				Expect(orc.Setup(fakeRunner, ccg, ot, sd)).To(Succeed())
+			})
+
+			It("deletes the service before tearing down", func() {
+				fakeWorkflow.DeleteSyslogDrainServiceReturns([]cmdStartWaiter.CmdStartWaiter{exec.Command("delete", "service")})
+
+				Expect(orc.TearDown(fakeRunner, ccg)).To(Succeed())
+
+				_, setupServiceName, _ := fakeWorkflow.CreateAndBindSyslogDrainServiceArgsForCall(0)
+				Expect(fakeWorkflow.DeleteSyslogDrainServiceCallCount()).To(Equal(1))
+				ccgArg, serviceName := fakeWorkflow.DeleteSyslogDrainServiceArgsForCall(0)
+				Expect(ccgArg).To(Equal(ccg))
+				Expect(serviceName).To(Equal(setupServiceName))
+				Expect(fakeRunner.RunInSequenceCallCount()).To(Equal(3))
+				Expect(fakeRunner.RunInSequenceArgsForCall(1)).To(Equal([]cmdStartWaiter.CmdStartWaiter{exec.Command("delete", "service")}))
+				Expect(fakeRunner.RunInSequenceArgsForCall(2)).To(Equal([]cmdStartWaiter.CmdStartWaiter{exec.Command("delete", "org")}))
+			})
+
+			It("does not run anything when the workflow has no service to delete", func() {
+				Expect(orc.TearDown(fakeRunner, ccg)).To(Succeed())
+
+				Expect(fakeRunner.RunInSequenceCallCount()).To(Equal(2))
+			})
+
+			It("tears down even when deleting the service fails", func() {
+				fakeWorkflow.DeleteSyslogDrainServiceReturns([]cmdStartWaiter.CmdStartWaiter{exec.Command("delete", "service")})
+				fakeRunner.RunInSequenceReturnsOnCall(1, fmt.Errorf("uh oh"))
+
+				Expect(orc.TearDown(fakeRunner, ccg)).To(MatchError("uh oh"))
+
+				Expect(fakeRunner.RunInSequenceCallCount()).To(Equal(3))
+			})
+		})
+	})
+
+	Describe("Cancel", func() {
+		var (
+			cancelLogBuf *gbytes.Buffer
+			exitCode     int
+			err          error
+			done         chan struct{}
+		)
+
+		BeforeEach(func() {
+			cancelLogBuf = gbytes.NewBuffer()
+			logger = log.New(cancelLogBuf, "", 0)
+			fakeCommand1.CommandArgs = []string{"30"}
+			fakeMeasurement1.SummaryDataReturns(measurement.Summary{})
+			fakeMeasurement2.SummaryDataReturns(measurement.Summary{})
+			orc = New([]*config.Command{fakeCommand1, fakeCommand2}, logger, fakeWorkflow, cmdRunner.New(io.Discard, io.Discard, io.Copy), []measurement.Measurement{fakeMeasurement1, fakeMeasurement2}, fakeIoutil, "some-run-id", nil)
+
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				exitCode, err = orc.Run(true, "/tmp/results")
+			}()
+			Eventually(cancelLogBuf).Should(gbytes.Say("Running commands..."))
+		})
+
+		It("stops the running while command and skips the remaining ones", func() {
+			start := time.Now()
+			orc.Cancel(syscall.SIGTERM)
+
+			Eventually(done, "5s").Should(BeClosed())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(cancelLogBuf.Contents())).To(ContainSubstring("Run cancelled by terminated"))
+		})
+
+		It("exits with the code of the signal", func() {
+			orc.Cancel(syscall.SIGINT)
+
+			Eventually(done, "5s").Should(BeClosed())
+			Expect(exitCode).To(Equal(130))
+		})
+
+		It("stops the measurements and writes their partial results", func() {
+			orc.Cancel(syscall.SIGTERM)
+
+			Eventually(done, "5s").Should(BeClosed())
+			Expect(fakeMeasurement1.StopCallCount()).To(Equal(1))
+			Expect(fakeMeasurement2.StopCallCount()).To(Equal(1))
+			Expect(fakeIoutil.WriteFileCallCount()).To(Equal(1))
+			_, jsonBytes, _ := fakeIoutil.WriteFileArgsForCall(0)
+			Expect(string(jsonBytes)).To(ContainSubstring(`"cancelled":true`))
+		})
+
+		It("ignores further cancellations", func() {
+			orc.Cancel(syscall.SIGTERM)
+			orc.Cancel(syscall.SIGINT)
+
+			Eventually(done, "5s").Should(BeClosed())
+			Expect(exitCode).To(Equal(143))
+			Expect(strings.Count(string(cancelLogBuf.Contents()), "Forwarded")).To(Equal(1))
+		})
+	})
+
+	Describe("Cancel when no while command is running", func() {
+		It("does not claim to have forwarded the signal", func() {
+			cancelLogBuf := gbytes.NewBuffer()
+			orc = New([]*config.Command{fakeCommand1, fakeCommand2}, log.New(cancelLogBuf, "", 0), fakeWorkflow, fakeRunner, []measurement.Measurement{fakeMeasurement1}, fakeIoutil, "some-run-id", nil)
+
+			orc.Cancel(syscall.SIGTERM)
+
+			Expect(string(cancelLogBuf.Contents())).NotTo(ContainSubstring("Forwarded"))
+		})
+	})
+
+	Describe("Plan", func() {
+		var commandMeasurement *fakeCommandMeasurement
+
+		BeforeEach(func() {
+			commandMeasurement = &fakeCommandMeasurement{
+				FakeMeasurement: fakeMeasurement2,
+				commands: []cmdStartWaiter.CmdStartWaiter{
+					exec.Command("cf", "logs", "--recent"),
+				},
+			}
+			orc = New([]*config.Command{fakeCommand1, fakeCommand2}, logger, fakeWorkflow, fakeRunner, []measurement.Measurement{fakeMeasurement1, commandMeasurement}, fakeIoutil, "some-run-id", nil)
+		})
+
+		It("passes the commands of each measurement and the while commands to the runner", func() {
+			Expect(orc.Plan(fakeRunner)).To(Succeed())
+
+			Expect(fakeRunner.RunInSequenceCallCount()).To(Equal(2))
+			Expect(fakeRunner.RunInSequenceArgsForCall(0)).To(Equal(
+				[]cmdStartWaiter.CmdStartWaiter{exec.Command("cf", "logs", "--recent")},
+			))
+			Expect(fakeRunner.RunInSequenceArgsForCall(1)).To(Equal(
+				[]cmdStartWaiter.CmdStartWaiter{
+					exec.Command("sleep", "10"),
+					exec.Command("sleep", "15"),
+				},
+			))
+			Expect(logBuf.String()).To(ContainSubstring("Measurement name1 runs no commands"))
+			Expect(logBuf.String()).To(ContainSubstring("Measurement name2 runs:"))
+		})
+
+		It("does not start any measurement", func() {
+			Expect(orc.Plan(fakeRunner)).To(Succeed())
+
+			Expect(fakeMeasurement1.StartCallCount()).To(Equal(0))
+			Expect(fakeMeasurement2.StartCallCount()).To(Equal(0))
+		})
+
+		It("returns an error if the runner returns an error", func() {
+			fakeRunner.RunInSequenceReturns(fmt.Errorf("uh oh"))
+
+			Expect(orc.Plan(fakeRunner)).To(MatchError("uh oh"))
+			Expect(fakeRunner.RunInSequenceCallCount()).To(Equal(1))
+		})
 	})
 })
 
+// commandLines returns the command line of each command, so that while
+// commands can be compared however Run wraps them.
+func commandLines(cmds []cmdStartWaiter.CmdStartWaiter) []string {
+	var lines []string
+	for _, cmd := range cmds {
+		lines = append(lines, fmt.Sprint(cmd))
+	}
+
+	return lines
+}
+
+type fakeCommandMeasurement struct {
+	*measurementfakes.FakeMeasurement
+	commands []cmdStartWaiter.CmdStartWaiter
+}
+
+func (f *fakeCommandMeasurement) Commands() []cmdStartWaiter.CmdStartWaiter {
+	return f.commands
+}
+
 type fakeSyser struct {
 	WS syscall.WaitStatus
 }
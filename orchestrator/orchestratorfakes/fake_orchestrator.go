@@ -2,6 +2,7 @@
 package orchestratorfakes
 
 import (
+	"os"
 	"sync"
 
 	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
@@ -11,6 +12,22 @@ import (
 )
 
 type FakeOrchestrator struct {
+	CancelStub        func(os.Signal)
+	cancelMutex       sync.RWMutex
+	cancelArgsForCall []struct {
+		arg1 os.Signal
+	}
+	PlanStub        func(cmdRunner.CmdRunner) error
+	planMutex       sync.RWMutex
+	planArgsForCall []struct {
+		arg1 cmdRunner.CmdRunner
+	}
+	planReturns struct {
+		result1 error
+	}
+	planReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RunStub        func(bool, string) (int, error)
 	runMutex       sync.RWMutex
 	runArgsForCall []struct {
@@ -25,12 +42,13 @@ type FakeOrchestrator struct {
 		result1 int
 		result2 error
 	}
-	SetupStub        func(cmdRunner.CmdRunner, cfCmdGenerator.CfCmdGenerator, config.OptionalTests) error
+	SetupStub        func(cmdRunner.CmdRunner, cfCmdGenerator.CfCmdGenerator, config.OptionalTests, config.SyslogDrain) error
 	setupMutex       sync.RWMutex
 	setupArgsForCall []struct {
 		arg1 cmdRunner.CmdRunner
 		arg2 cfCmdGenerator.CfCmdGenerator
 		arg3 config.OptionalTests
+		arg4 config.SyslogDrain
 	}
 	setupReturns struct {
 		result1 error
@@ -54,6 +72,99 @@ type FakeOrchestrator struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeOrchestrator) Cancel(arg1 os.Signal) {
+	fake.cancelMutex.Lock()
+	fake.cancelArgsForCall = append(fake.cancelArgsForCall, struct {
+		arg1 os.Signal
+	}{arg1})
+	stub := fake.CancelStub
+	fake.recordInvocation("Cancel", []interface{}{arg1})
+	fake.cancelMutex.Unlock()
+	if stub != nil {
+		fake.CancelStub(arg1)
+	}
+}
+
+func (fake *FakeOrchestrator) CancelCallCount() int {
+	fake.cancelMutex.RLock()
+	defer fake.cancelMutex.RUnlock()
+	return len(fake.cancelArgsForCall)
+}
+
+func (fake *FakeOrchestrator) CancelCalls(stub func(os.Signal)) {
+	fake.cancelMutex.Lock()
+	defer fake.cancelMutex.Unlock()
+	fake.CancelStub = stub
+}
+
+func (fake *FakeOrchestrator) CancelArgsForCall(i int) os.Signal {
+	fake.cancelMutex.RLock()
+	defer fake.cancelMutex.RUnlock()
+	argsForCall := fake.cancelArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeOrchestrator) Plan(arg1 cmdRunner.CmdRunner) error {
+	fake.planMutex.Lock()
+	ret, specificReturn := fake.planReturnsOnCall[len(fake.planArgsForCall)]
+	fake.planArgsForCall = append(fake.planArgsForCall, struct {
+		arg1 cmdRunner.CmdRunner
+	}{arg1})
+	stub := fake.PlanStub
+	fakeReturns := fake.planReturns
+	fake.recordInvocation("Plan", []interface{}{arg1})
+	fake.planMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeOrchestrator) PlanCallCount() int {
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
+	return len(fake.planArgsForCall)
+}
+
+func (fake *FakeOrchestrator) PlanCalls(stub func(cmdRunner.CmdRunner) error) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = stub
+}
+
+func (fake *FakeOrchestrator) PlanArgsForCall(i int) cmdRunner.CmdRunner {
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
+	argsForCall := fake.planArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeOrchestrator) PlanReturns(result1 error) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = nil
+	fake.planReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOrchestrator) PlanReturnsOnCall(i int, result1 error) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = nil
+	if fake.planReturnsOnCall == nil {
+		fake.planReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.planReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeOrchestrator) Run(arg1 bool, arg2 string) (int, error) {
 	fake.runMutex.Lock()
 	ret, specificReturn := fake.runReturnsOnCall[len(fake.runArgsForCall)]
@@ -119,20 +230,21 @@ func (fake *FakeOrchestrator) RunReturnsOnCall(i int, result1 int, result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeOrchestrator) Setup(arg1 cmdRunner.CmdRunner, arg2 cfCmdGenerator.CfCmdGenerator, arg3 config.OptionalTests) error {
+func (fake *FakeOrchestrator) Setup(arg1 cmdRunner.CmdRunner, arg2 cfCmdGenerator.CfCmdGenerator, arg3 config.OptionalTests, arg4 config.SyslogDrain) error {
 	fake.setupMutex.Lock()
 	ret, specificReturn := fake.setupReturnsOnCall[len(fake.setupArgsForCall)]
 	fake.setupArgsForCall = append(fake.setupArgsForCall, struct {
 		arg1 cmdRunner.CmdRunner
 		arg2 cfCmdGenerator.CfCmdGenerator
 		arg3 config.OptionalTests
-	}{arg1, arg2, arg3})
+		arg4 config.SyslogDrain
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.SetupStub
 	fakeReturns := fake.setupReturns
-	fake.recordInvocation("Setup", []interface{}{arg1, arg2, arg3})
+	fake.recordInvocation("Setup", []interface{}{arg1, arg2, arg3, arg4})
 	fake.setupMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -146,17 +258,17 @@ func (fake *FakeOrchestrator) SetupCallCount() int {
 	return len(fake.setupArgsForCall)
 }
 
-func (fake *FakeOrchestrator) SetupCalls(stub func(cmdRunner.CmdRunner, cfCmdGenerator.CfCmdGenerator, config.OptionalTests) error) {
+func (fake *FakeOrchestrator) SetupCalls(stub func(cmdRunner.CmdRunner, cfCmdGenerator.CfCmdGenerator, config.OptionalTests, config.SyslogDrain) error) {
 	fake.setupMutex.Lock()
 	defer fake.setupMutex.Unlock()
 	fake.SetupStub = stub
 }
 
-func (fake *FakeOrchestrator) SetupArgsForCall(i int) (cmdRunner.CmdRunner, cfCmdGenerator.CfCmdGenerator, config.OptionalTests) {
+func (fake *FakeOrchestrator) SetupArgsForCall(i int) (cmdRunner.CmdRunner, cfCmdGenerator.CfCmdGenerator, config.OptionalTests, config.SyslogDrain) {
 	fake.setupMutex.RLock()
 	defer fake.setupMutex.RUnlock()
 	argsForCall := fake.setupArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeOrchestrator) SetupReturns(result1 error) {
@@ -247,6 +359,10 @@ func (fake *FakeOrchestrator) TearDownReturnsOnCall(i int, result1 error) {
 func (fake *FakeOrchestrator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.cancelMutex.RLock()
+	defer fake.cancelMutex.RUnlock()
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
 	fake.runMutex.RLock()
 	defer fake.runMutex.RUnlock()
 	fake.setupMutex.RLock()
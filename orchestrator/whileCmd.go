@@ -0,0 +1,68 @@
+//go:build !windows
+// +build !windows
+
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// errCancelled is returned for while commands that are not started because
+// the run was cancelled.
+var errCancelled = errors.New("run cancelled")
+
+// cancellation tracks the while command that is running, so that the signal
+// that cancels the run can be forwarded to it.
+type cancellation struct {
+	mu      sync.Mutex
+	signal  os.Signal
+	running *exec.Cmd
+}
+
+// cancel records sig and forwards it to the running while command, if any.
+// It reports whether a command got sig, which none does once the run has
+// been cancelled.
+func (c *cancellation) cancel(sig os.Signal) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.signal != nil {
+		return false
+	}
+	c.signal = sig
+
+	// The command may have exited already; then there is nothing to stop.
+	return c.running != nil && c.running.Process.Signal(sig) == nil
+}
+
+func (c *cancellation) cancelledBy() os.Signal {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.signal
+}
+
+// whileCmd is a while command that registers with the cancellation when it
+// starts, and does not start once the run has been cancelled.
+type whileCmd struct {
+	*exec.Cmd
+	cancellation *cancellation
+}
+
+func (w *whileCmd) Start() error {
+	w.cancellation.mu.Lock()
+	defer w.cancellation.mu.Unlock()
+
+	if w.cancellation.signal != nil {
+		return errCancelled
+	}
+	if err := w.Cmd.Start(); err != nil {
+		return err
+	}
+	w.cancellation.running = w.Cmd
+
+	return nil
+}
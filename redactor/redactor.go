@@ -0,0 +1,61 @@
+package redactor
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces every secret in redacted output.
+const Redacted = "[REDACTED]"
+
+type Redactor interface {
+	Redact(s string) string
+}
+
+type redactor struct {
+	replacer *strings.Replacer
+}
+
+// New returns a Redactor for the given secrets. Empty secrets are ignored.
+func New(secrets ...string) Redactor {
+	var nonEmpty []string
+	for _, s := range secrets {
+		if s != "" {
+			nonEmpty = append(nonEmpty, s)
+		}
+	}
+	// Longer secrets go first, so that a secret containing another is
+	// replaced as a whole.
+	sort.Slice(nonEmpty, func(i, j int) bool { return len(nonEmpty[i]) > len(nonEmpty[j]) })
+
+	oldnew := make([]string, 0, 2*len(nonEmpty))
+	for _, s := range nonEmpty {
+		oldnew = append(oldnew, s, Redacted)
+	}
+
+	return &redactor{replacer: strings.NewReplacer(oldnew...)}
+}
+
+func (r *redactor) Redact(s string) string {
+	return r.replacer.Replace(s)
+}
+
+type writer struct {
+	w        io.Writer
+	redactor Redactor
+}
+
+// NewWriter returns a writer that redacts what is written to w. Secrets are
+// only found within a single write, such as one line of a log.Logger.
+func NewWriter(w io.Writer, r Redactor) io.Writer {
+	return &writer{w: w, redactor: r}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, w.redactor.Redact(string(p))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,46 @@
+package redactor_test
+
+import (
+	"bytes"
+	"log"
+
+	. "github.com/cloudfoundry/uptimer/redactor"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Redactor", func() {
+	Describe("Redact", func() {
+		It("replaces every occurrence of each secret", func() {
+			r := New("hunter2", "s3cret")
+
+			Expect(r.Redact("cf auth admin hunter2\nsecret: s3cret, again hunter2")).To(Equal(
+				"cf auth admin [REDACTED]\nsecret: [REDACTED], again [REDACTED]",
+			))
+		})
+
+		It("replaces a secret containing another as a whole", func() {
+			r := New("pass", "password123")
+
+			Expect(r.Redact("password123 and pass")).To(Equal("[REDACTED] and [REDACTED]"))
+		})
+
+		It("ignores empty secrets", func() {
+			r := New("", "hunter2")
+
+			Expect(r.Redact("nothing to hide")).To(Equal("nothing to hide"))
+		})
+	})
+
+	Describe("NewWriter", func() {
+		It("redacts what a logger writes", func() {
+			buf := &bytes.Buffer{}
+			logger := log.New(NewWriter(buf, New("hunter2")), "", 0)
+
+			logger.Printf("Failed auth: %s", "stderr:\nCredentials hunter2 were rejected")
+
+			Expect(buf.String()).To(Equal("Failed auth: stderr:\nCredentials [REDACTED] were rejected\n"))
+		})
+	})
+})
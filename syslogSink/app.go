@@ -4,18 +4,33 @@ const Source = `
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"math/big"
 	"net"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 	"unicode"
 	"unicode/utf8"
 )
 
+// retention is how long received sequence numbers are kept for counting.
+const retention = time.Hour
+
 func main() {
 	l, err := net.Listen("tcp4", fmt.Sprintf(":%s", os.Getenv("PORT")))
 	if err != nil {
@@ -23,28 +38,269 @@ func main() {
 	}
 	defer l.Close()
 
+	tlsConfig, err := loadTLSConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := newStore()
+
+	// The sink is only reachable through a single TCP route, so plain and
+	// TLS syslog streams, HTTPS drain batches and HTTP requests for counts
+	// all share one listener.
+	httpConns := newConnListener(l.Addr())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/counts", s.serveCounts)
+	mux.HandleFunc("/drain", s.serveDrain)
+	go http.Serve(httpConns, mux)
+
 	for {
 		conn, err := l.Accept()
 		if err != nil {
 			continue
 		}
 
-		go func() {
-			defer conn.Close()
-			handleConnection(conn)
-		}()
+		go handleConnection(conn, s, httpConns, tlsConfig)
+	}
+}
+
+func handleConnection(conn net.Conn, s *store, httpConns *connListener, tlsConfig *tls.Config) {
+	r := bufio.NewReader(conn)
+	first, err := r.Peek(1)
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	bc := &bufferedConn{Conn: conn, r: r}
+	switch {
+	case first[0] == 0x16 && tlsConfig != nil: // TLS handshake record
+		handleConnection(tls.Server(bc, tlsConfig), s, httpConns, nil)
+	case first[0] >= '0' && first[0] <= '9':
+		defer conn.Close()
+		handleSyslog(bc, s)
+	default:
+		httpConns.conns <- bc
 	}
 }
 
-func handleConnection(conn net.Conn) {
+func handleSyslog(r io.Reader, s *store) {
 	msg := Message{}
 	for {
-		if _, err := msg.ReadFrom(conn); err != nil {
+		if _, err := msg.ReadFrom(r); err != nil {
 			return
 		}
 
 		fmt.Println(string(msg.Message))
+		s.record(msg, time.Now())
+	}
+}
+
+// serveDrain accepts the POST requests of https drains. A body holds either
+// a single message or a batch of octet-counted messages.
+func (s *store) serveDrain(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(body) > 0 && body[0] >= '0' && body[0] <= '9' {
+		handleSyslog(bytes.NewReader(body), s)
+		return
+	}
+
+	msg := Message{}
+	if err := msg.UnmarshalBinary(bytes.TrimSpace(body)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fmt.Println(string(msg.Message))
+	s.record(msg, time.Now())
+}
+
+// loadTLSConfig uses the certificate given in SINK_TLS_CERT and SINK_TLS_KEY,
+// or generates a self-signed one.
+func loadTLSConfig() (*tls.Config, error) {
+	certPEM, keyPEM := os.Getenv("SINK_TLS_CERT"), os.Getenv("SINK_TLS_KEY")
+	if certPEM != "" && keyPEM != "" {
+		cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "uptimer-syslog-sink"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}, nil
+}
+
+// store keeps the sequence numbers logged by drained apps. The uptimer app
+// logs the current unix time once per second, so every second is expected
+// to arrive once per instance.
+type store struct {
+	mu        sync.Mutex
+	instances map[string]*instance
+	lastPrune time.Time
+}
+
+type instance struct {
+	host      string
+	processID string
+	delays    map[int64]float64
+}
+
+func newStore() *store {
+	return &store{instances: map[string]*instance{}}
+}
+
+func (s *store) record(msg Message, receivedAt time.Time) {
+	if !strings.HasPrefix(msg.ProcessID, "[APP") {
+		return
 	}
+
+	seq, err := strconv.ParseInt(strings.TrimSpace(string(msg.Message)), 10, 64)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := msg.Hostname + " " + msg.ProcessID
+	in, ok := s.instances[key]
+	if !ok {
+		in = &instance{host: msg.Hostname, processID: msg.ProcessID, delays: map[int64]float64{}}
+		s.instances[key] = in
+	}
+	if _, seen := in.delays[seq]; !seen {
+		in.delays[seq] = receivedAt.Sub(msg.Timestamp).Seconds()
+	}
+
+	if receivedAt.Sub(s.lastPrune) > time.Minute {
+		s.prune(receivedAt.Add(-retention).Unix())
+		s.lastPrune = receivedAt
+	}
+}
+
+func (s *store) prune(before int64) {
+	for _, in := range s.instances {
+		for seq := range in.delays {
+			if seq < before {
+				delete(in.delays, seq)
+			}
+		}
+	}
+}
+
+// serveCounts reports, per app instance, how many sequence numbers between
+// the "from" and "to" query parameters (inclusive) were received and how
+// long they took to arrive. An optional "host" parameter restricts the
+// counts to a single drained app.
+func (s *store) serveCounts(w http.ResponseWriter, req *http.Request) {
+	from, err := strconv.ParseInt(req.URL.Query().Get("from"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid 'from' parameter", http.StatusBadRequest)
+		return
+	}
+	to, err := strconv.ParseInt(req.URL.Query().Get("to"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid 'to' parameter", http.StatusBadRequest)
+		return
+	}
+	host := req.URL.Query().Get("host")
+
+	s.mu.Lock()
+	instances := map[string]interface{}{}
+	for key, in := range s.instances {
+		if host != "" && in.host != host {
+			continue
+		}
+
+		received := 0
+		delayTotal := 0.0
+		delayMax := 0.0
+		for seq, delay := range in.delays {
+			if seq < from || seq > to {
+				continue
+			}
+			received++
+			delayTotal += delay
+			if delay > delayMax {
+				delayMax = delay
+			}
+		}
+
+		instances[key] = map[string]interface{}{
+			"received":            received,
+			"delay_seconds_total": delayTotal,
+			"delay_seconds_max":   delayMax,
+		}
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from":      from,
+		"to":        to,
+		"instances": instances,
+	})
+}
+
+// connListener is a net.Listener that serves connections which have already
+// been accepted and identified as HTTP.
+type connListener struct {
+	addr  net.Addr
+	conns chan net.Conn
+}
+
+func newConnListener(addr net.Addr) *connListener {
+	return &connListener{addr: addr, conns: make(chan net.Conn)}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	return <-l.conns, nil
+}
+
+func (l *connListener) Close() error {
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return l.addr
+}
+
+// bufferedConn is a net.Conn whose first bytes have been peeked.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
 }
 
 // The following code is sourced from code.cloudfoundry.org/rfc5424